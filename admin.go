@@ -0,0 +1,134 @@
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// requireAdmin guards the admin endpoints. When ADMIN_TOKEN is set requests
+// must send it as a bearer token. Otherwise they are refused, unless
+// ADMIN_ALLOW_LOCAL opts in to letting loopback clients in; behind a proxy
+// on the same host every request comes from loopback, so that is not the
+// default.
+func requireAdmin(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if token := getEnv("ADMIN_TOKEN", ""); token != "" {
+			given, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="admin"`)
+				writeJSONError(w, http.StatusUnauthorized, "Admin token required")
+				return
+			}
+			next(w, r)
+			return
+		}
+
+		if !adminAllowLocal() {
+			writeJSONError(w, http.StatusForbidden, "Admin endpoints are disabled unless ADMIN_TOKEN or ADMIN_ALLOW_LOCAL is set")
+			return
+		}
+		host, _, err := net.SplitHostPort(r.RemoteAddr)
+		if ip := net.ParseIP(host); err != nil || ip == nil || !ip.IsLoopback() {
+			writeJSONError(w, http.StatusForbidden, "Admin endpoints are only available locally unless ADMIN_TOKEN is set")
+			return
+		}
+		next(w, r)
+	}
+}
+
+func adminAllowLocal() bool {
+	switch os.Getenv("ADMIN_ALLOW_LOCAL") {
+	case "1", "true", "TRUE", "yes":
+		return true
+	}
+	return false
+}
+
+// resumeBuildsHandler lists recent resume builds, newest first
+func (s *Server) resumeBuildsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": "success",
+		"builds": s.resumes.Builds(),
+	})
+}
+
+// resumeBuildLogHandler returns the captured engine output of one build
+func (s *Server) resumeBuildLogHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "Invalid build id")
+		return
+	}
+
+	_, buildLog, ok := s.resumes.Build(id)
+	if !ok {
+		writeJSONError(w, http.StatusNotFound, "Build not found")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Write(buildLog)
+}
+
+// contactRetryHandler queues a dead-lettered or rejected contact submission
+// again with a fresh set of attempts. A rejected one keeps its rejection
+// reason for the record, which also stops the outbox auto-replying to it.
+func (s *Server) contactRetryHandler(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	current, ok := s.contacts.Get(id)
+	if !ok {
+		writeJSONError(w, http.StatusNotFound, "Submission not found")
+		return
+	}
+	if current.Status != deliveryDead && current.Status != deliveryRejected {
+		writeJSONError(w, http.StatusConflict, "Only dead-lettered or rejected submissions can be retried")
+		return
+	}
+
+	sub, err := s.contacts.Update(id, func(sub *ContactSubmission) {
+		sub.Status = deliveryPending
+		sub.Attempts = 0
+		sub.NextAttemptAt = time.Now().UTC()
+	})
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, "Failed to queue submission")
+		return
+	}
+	s.outbox.Trigger()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":     "success",
+		"submission": sub,
+	})
+}
+
+func writeJSONError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{
+		"status":  "error",
+		"message": message,
+	})
+}
+
+// contactSubmissionsHandler lists stored contact submissions, newest first,
+// optionally filtered by ?status=
+func (s *Server) contactSubmissionsHandler(w http.ResponseWriter, r *http.Request) {
+	submissions := s.contacts.List(r.URL.Query().Get("status"))
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":      "success",
+		"count":       len(submissions),
+		"submissions": submissions,
+	})
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequireAdmin(t *testing.T) {
+	handler := requireAdmin(func(w http.ResponseWriter, r *http.Request) {})
+	for _, tc := range []struct {
+		token, allowLocal string
+		remote, auth      string
+		want              int
+	}{
+		// Disabled without a token, even from loopback, as behind a local proxy
+		{"", "", "127.0.0.1:4711", "", http.StatusForbidden},
+		{"", "", "[::1]:4711", "", http.StatusForbidden},
+		{"", "1", "127.0.0.1:4711", "", http.StatusOK},
+		{"", "true", "203.0.113.7:4711", "", http.StatusForbidden},
+
+		{"secret", "", "203.0.113.7:4711", "Bearer secret", http.StatusOK},
+		{"secret", "", "203.0.113.7:4711", "Bearer wrong", http.StatusUnauthorized},
+		{"secret", "", "203.0.113.7:4711", "secret", http.StatusUnauthorized},
+		{"secret", "1", "127.0.0.1:4711", "", http.StatusUnauthorized},
+	} {
+		t.Setenv("ADMIN_TOKEN", tc.token)
+		t.Setenv("ADMIN_ALLOW_LOCAL", tc.allowLocal)
+		req := httptest.NewRequest("GET", "/admin/contact/submissions", nil)
+		req.RemoteAddr = tc.remote
+		if tc.auth != "" {
+			req.Header.Set("Authorization", tc.auth)
+		}
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+		if rec.Code != tc.want {
+			t.Errorf("ADMIN_TOKEN=%q ADMIN_ALLOW_LOCAL=%q from %s with %q: got %d, want %d", tc.token, tc.allowLocal, tc.remote, tc.auth, rec.Code, tc.want)
+		}
+	}
+}
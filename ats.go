@@ -0,0 +1,395 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"mime"
+	"net/http"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/daveonthegit/Personal_Portfolio/config"
+)
+
+const (
+	// maxJobDescriptionBytes caps the request body of the analyze endpoint
+	maxJobDescriptionBytes = 64 << 10
+
+	// atsSuggestedProjects is how many projects the analyzer suggests
+	atsSuggestedProjects = 3
+
+	// Weights for a job description term found in a project's technologies
+	// or only in its description and highlights
+	atsWeightTechnology = 2.0
+	atsWeightText       = 1.0
+)
+
+// Where a candidate term came from, as reported in ATSMatch.Sources
+const (
+	atsSourceSkills     = "skills"
+	atsSourceExperience = "experience"
+	atsSourceProjects   = "projects"
+)
+
+// atsDictionary maps technology names and their aliases to canonical terms
+type atsDictionary struct {
+	aliases map[string][]atsAlias // Keyed by the lowercased first word
+}
+
+type atsAlias struct {
+	term  string
+	words []string
+	exact bool // Match the words case-sensitively
+}
+
+// atsToken is a word of a job description or resume entry
+type atsToken struct {
+	text   string
+	norm   string // Lowercased text
+	joined bool   // Joined to the next or previous word, as R and D are in R&D
+}
+
+// newATSDictionary builds a dictionary from the bundled technology terms
+func newATSDictionary() *atsDictionary {
+	d := &atsDictionary{aliases: make(map[string][]atsAlias)}
+	for _, entry := range techTerms {
+		for _, alias := range entry[1:] {
+			if exact := strings.HasPrefix(alias, "="); exact {
+				d.add(entry[0], alias[1:], true)
+			} else {
+				d.add(entry[0], alias, false)
+			}
+		}
+	}
+	return d
+}
+
+// add registers alias as a name for term
+func (d *atsDictionary) add(term, alias string, exact bool) {
+	tokens := atsTokenize(alias)
+	if len(tokens) == 0 {
+		return
+	}
+	a := atsAlias{term: term, exact: exact}
+	for _, tok := range tokens {
+		if exact {
+			a.words = append(a.words, tok.text)
+		} else {
+			a.words = append(a.words, tok.norm)
+		}
+	}
+	d.aliases[tokens[0].norm] = append(d.aliases[tokens[0].norm], a)
+}
+
+// extract returns the canonical term of every alias in text, in order of
+// appearance and including repeats. Where aliases overlap the longest wins,
+// so "React Native" is not also counted as React.
+func (d *atsDictionary) extract(text string) []string {
+	tokens := atsTokenize(text)
+	var terms []string
+	for i := 0; i < len(tokens); {
+		var best *atsAlias
+		for j, a := range d.aliases[tokens[i].norm] {
+			if a.matches(tokens[i:]) && (best == nil || len(a.words) > len(best.words)) {
+				best = &d.aliases[tokens[i].norm][j]
+			}
+		}
+		if best == nil {
+			i++
+			continue
+		}
+		terms = append(terms, best.term)
+		i += len(best.words)
+	}
+	return terms
+}
+
+func (a atsAlias) matches(tokens []atsToken) bool {
+	if len(tokens) < len(a.words) {
+		return false
+	}
+	// One-letter names like C and R must stand alone, not be part of R&D
+	if len(a.words) == 1 && utf8.RuneCountInString(a.words[0]) == 1 && tokens[0].joined {
+		return false
+	}
+	for i, word := range a.words {
+		if a.exact && tokens[i].text != word || !a.exact && tokens[i].norm != word {
+			return false
+		}
+	}
+	return true
+}
+
+// atsTokenize splits text into words. Besides letters and digits, words may
+// contain the '+', '#', '.' and '-' of names like C++, C#, Node.js and
+// scikit-learn, and start with the '.' of .NET. Slashes separate words, so
+// "HTML/CSS" is two. Words joined by '&', '@', '_' or an apostrophe are
+// split but marked as joined.
+func atsTokenize(text string) []atsToken {
+	var tokens []atsToken
+	start := -1
+
+	flush := func(end int) {
+		if start == -1 {
+			return
+		}
+		word := strings.TrimRight(text[start:end], ".-")
+		if trimmed := strings.TrimLeft(word, "."); len(word)-len(trimmed) != 1 {
+			word = trimmed
+		}
+		if strings.Trim(word, ".") != "" {
+			before, _ := utf8.DecodeLastRuneInString(text[:start])
+			after, _ := utf8.DecodeRuneInString(text[end:])
+			tokens = append(tokens, atsToken{
+				text:   word,
+				norm:   strings.ToLower(word),
+				joined: atsJoiner(before) || atsJoiner(after),
+			})
+		}
+		start = -1
+	}
+
+	for i, r := range text {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if start == -1 {
+				start = i
+			}
+		case r == '.' && start == -1:
+			start = i
+		case (r == '+' || r == '#' || r == '.' || r == '-') && start != -1:
+		default:
+			flush(i)
+		}
+	}
+	flush(len(text))
+
+	return tokens
+}
+
+// atsJoiner reports whether r joins the words either side of it into one
+// name, as in R&D, AT&T or O'Reilly
+func atsJoiner(r rune) bool {
+	return strings.ContainsRune("&@_'’", r)
+}
+
+// atsProfile is what the analyzer compares job descriptions against: the
+// candidate's skills and technologies, in canonical form, and the terms
+// each project covers. It is rebuilt whenever content is reloaded.
+type atsProfile struct {
+	dict     *atsDictionary
+	sources  map[string][]string // Candidate term to the sections it appears in
+	projects []atsProject
+}
+
+type atsProject struct {
+	project  Project
+	featured bool
+	terms    map[string]float64 // Term to weight
+}
+
+// newATSProfile collects the terms in the skills, experience technologies
+// and project technologies of info and projects. Entries the dictionary
+// does not know, such as "Discord.js", are added to it as their own terms,
+// matched with their exact capitalization.
+func newATSProfile(info config.PersonalInfo, projects []Project) *atsProfile {
+	p := &atsProfile{
+		dict:    newATSDictionary(),
+		sources: make(map[string][]string),
+	}
+
+	addSource := func(term, source string) {
+		for _, s := range p.sources[term] {
+			if s == source {
+				return
+			}
+		}
+		p.sources[term] = append(p.sources[term], source)
+	}
+
+	for _, s := range info.Skills {
+		for _, item := range s.Items {
+			for _, term := range p.canonicalize(item) {
+				addSource(term, atsSourceSkills)
+			}
+		}
+	}
+	for _, e := range info.Experience {
+		for _, tech := range e.Technologies {
+			for _, term := range p.canonicalize(tech) {
+				addSource(term, atsSourceExperience)
+			}
+		}
+	}
+
+	featured := make(map[string]bool)
+	for _, id := range info.ResumeProjects {
+		featured[id] = true
+	}
+	for _, project := range projects {
+		ap := atsProject{project: project, featured: featured[project.ID], terms: make(map[string]float64)}
+		for _, tech := range project.Technologies {
+			for _, term := range p.canonicalize(tech) {
+				addSource(term, atsSourceProjects)
+				ap.terms[term] = atsWeightTechnology
+			}
+		}
+		text := project.Title + "\n" + project.Description + "\n" + strings.Join(project.Highlights, "\n")
+		for _, term := range p.dict.extract(text) {
+			if _, ok := ap.terms[term]; !ok {
+				ap.terms[term] = atsWeightText
+			}
+		}
+		p.projects = append(p.projects, ap)
+	}
+
+	return p
+}
+
+// canonicalize returns the dictionary terms in a skill or technology entry,
+// such as C and C++ for "C/C++". An entry with none becomes its own term.
+func (p *atsProfile) canonicalize(entry string) []string {
+	entry = strings.TrimSpace(entry)
+	if entry == "" {
+		return nil
+	}
+	if terms := p.dict.extract(entry); len(terms) > 0 {
+		return terms
+	}
+	p.dict.add(entry, entry, true)
+	return []string{entry}
+}
+
+// ATSReport compares a job description with the resume
+type ATSReport struct {
+	Terms             []string        `json:"terms"`   // Every term in the job description, most mentioned first
+	Matched           []ATSMatch      `json:"matched"` // Terms the resume covers
+	Missing           []string        `json:"missing"` // Terms it does not
+	Coverage          float64         `json:"coverage"`
+	SuggestedProjects []ATSSuggestion `json:"suggested_projects"`
+}
+
+// ATSMatch is a job description term found in the resume
+type ATSMatch struct {
+	Term    string   `json:"term"`
+	Sources []string `json:"sources"` // "skills", "experience" and/or "projects"
+}
+
+// ATSSuggestion is a project worth featuring for the job
+type ATSSuggestion struct {
+	ID       string   `json:"id"`
+	Title    string   `json:"title"`
+	Score    float64  `json:"score"`
+	Featured bool     `json:"featured"` // Already on the resume
+	Matched  []string `json:"matched"`
+}
+
+// Analyze extracts the technology terms from a job description and reports
+// which the resume covers. Coverage is the fraction of distinct terms
+// matched. Projects are suggested by how many of the terms they cover,
+// counting a term in a project's technologies over one only mentioned in
+// its description.
+func (p *atsProfile) Analyze(jobDescription string) ATSReport {
+	counts := make(map[string]int)
+	var terms []string
+	for _, term := range p.dict.extract(jobDescription) {
+		if counts[term] == 0 {
+			terms = append(terms, term)
+		}
+		counts[term]++
+	}
+	// Stable, so equally mentioned terms keep their order of appearance
+	sort.SliceStable(terms, func(i, j int) bool { return counts[terms[i]] > counts[terms[j]] })
+
+	report := ATSReport{
+		Terms:             terms,
+		Matched:           []ATSMatch{},
+		Missing:           []string{},
+		SuggestedProjects: []ATSSuggestion{},
+	}
+	if report.Terms == nil {
+		report.Terms = []string{}
+	}
+	for _, term := range terms {
+		if sources, ok := p.sources[term]; ok {
+			report.Matched = append(report.Matched, ATSMatch{Term: term, Sources: sources})
+		} else {
+			report.Missing = append(report.Missing, term)
+		}
+	}
+	if len(terms) > 0 {
+		report.Coverage = math.Round(float64(len(report.Matched))/float64(len(terms))*1000) / 1000
+	}
+
+	for _, ap := range p.projects {
+		s := ATSSuggestion{ID: ap.project.ID, Title: ap.project.Title, Featured: ap.featured}
+		for _, term := range terms {
+			if weight, ok := ap.terms[term]; ok {
+				s.Score += weight
+				s.Matched = append(s.Matched, term)
+			}
+		}
+		if s.Score > 0 {
+			report.SuggestedProjects = append(report.SuggestedProjects, s)
+		}
+	}
+	sort.SliceStable(report.SuggestedProjects, func(i, j int) bool {
+		return report.SuggestedProjects[i].Score > report.SuggestedProjects[j].Score
+	})
+	if len(report.SuggestedProjects) > atsSuggestedProjects {
+		report.SuggestedProjects = report.SuggestedProjects[:atsSuggestedProjects]
+	}
+
+	return report
+}
+
+// resumeAnalyzeHandler analyzes a job description posted either as JSON,
+// {"job_description": "..."}, or as a plain text body
+func (s *Server) resumeAnalyzeHandler(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxJobDescriptionBytes)
+
+	var jobDescription string
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "text/plain" {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeAnalyzeBodyError(w, err)
+			return
+		}
+		jobDescription = string(body)
+	} else {
+		var req struct {
+			JobDescription string `json:"job_description"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeAnalyzeBodyError(w, err)
+			return
+		}
+		jobDescription = req.JobDescription
+	}
+
+	if strings.TrimSpace(jobDescription) == "" {
+		writeJSONError(w, http.StatusBadRequest, "Missing job description.")
+		return
+	}
+
+	report := s.content().ats.Analyze(jobDescription)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(struct {
+		Status string `json:"status"`
+		ATSReport
+	}{"success", report})
+}
+
+func writeAnalyzeBodyError(w http.ResponseWriter, err error) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		writeJSONError(w, http.StatusRequestEntityTooLarge, "Job description is too long.")
+		return
+	}
+	writeJSONError(w, http.StatusBadRequest, "Invalid request body.")
+}
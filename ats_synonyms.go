@@ -0,0 +1,141 @@
+package main
+
+// techTerms is the bundled technology dictionary used by the ATS analyzer.
+// Each entry is the canonical name followed by the aliases that match it.
+// Aliases are matched case-insensitively, except those prefixed with "=",
+// which are for names that are also ordinary words ("Go", "React") and only
+// match with that exact capitalization.
+var techTerms = [][]string{
+	// Languages
+	{"Go", "golang", "=Go"},
+	{"Python", "python", "python3"},
+	{"Java", "java"},
+	{"JavaScript", "javascript", "js", "ecmascript", "es6"},
+	{"TypeScript", "typescript", "ts"},
+	{"C", "=C"},
+	{"C++", "c++", "cpp", "cplusplus"},
+	{"C#", "c#", "csharp", "c sharp"},
+	{"Rust", "rust", "rustlang"},
+	{"Ruby", "ruby"},
+	{"PHP", "php"},
+	{"Kotlin", "kotlin"},
+	{"Swift", "=Swift"},
+	{"Scala", "scala"},
+	{"R", "=R"},
+	{"SQL", "sql"},
+	{"Bash", "bash", "shell scripting", "shell", "sh"},
+	{"PowerShell", "powershell"},
+	{"Assembly", "assembly", "asm", "x86 assembly", "mips", "mips assembly"},
+	{"HTML", "html", "html5"},
+	{"CSS", "css", "css3"},
+	{"Sass", "sass", "scss"},
+	{"Solidity", "solidity"},
+	{"Haskell", "haskell"},
+	{"Elixir", "elixir"},
+	{"Lua", "lua"},
+	{"MATLAB", "matlab"},
+	{"LaTeX", "latex"},
+
+	// Frameworks and libraries
+	{"React", "=React", "react.js", "reactjs"},
+	{"React Native", "react native"},
+	{"Next.js", "next.js", "nextjs"},
+	{"Vue", "vue", "vue.js", "vuejs"},
+	{"Angular", "angular", "angularjs"},
+	{"Svelte", "svelte", "sveltekit"},
+	{"Node.js", "node.js", "nodejs", "node"},
+	{"Express.js", "express.js", "expressjs", "=Express"},
+	{"Django", "django"},
+	{"Flask", "flask"},
+	{"FastAPI", "fastapi"},
+	{"Spring", "=Spring", "spring boot", "springboot"},
+	{"Ruby on Rails", "ruby on rails", "rails"},
+	{"Laravel", "laravel"},
+	{".NET", ".net", "dotnet", "asp.net"},
+	{"jQuery", "jquery"},
+	{"Tailwind CSS", "tailwind", "tailwindcss", "tailwind css"},
+	{"Bootstrap", "=Bootstrap"},
+	{"GraphQL", "graphql"},
+	{"gRPC", "grpc"},
+	{"REST", "rest", "restful", "rest api", "rest apis", "restful api", "restful apis"},
+	{"TensorFlow", "tensorflow"},
+	{"PyTorch", "pytorch", "torch"},
+	{"scikit-learn", "scikit-learn", "sklearn", "scikit"},
+	{"Pandas", "pandas"},
+	{"NumPy", "numpy"},
+	{"PERN Stack", "pern", "pern stack"},
+	{"MERN Stack", "mern", "mern stack"},
+
+	// Data stores
+	{"PostgreSQL", "postgresql", "postgres", "psql", "pgsql", "plpgsql"},
+	{"MySQL", "mysql", "mariadb"},
+	{"SQLite", "sqlite"},
+	{"MongoDB", "mongodb", "mongo"},
+	{"Redis", "redis"},
+	{"Elasticsearch", "elasticsearch", "elastic search", "opensearch"},
+	{"DynamoDB", "dynamodb"},
+	{"Cassandra", "cassandra"},
+	{"Kafka", "kafka", "apache kafka"},
+	{"RabbitMQ", "rabbitmq"},
+
+	// Cloud and infrastructure
+	{"AWS", "aws", "amazon web services", "ec2", "s3", "lambda"},
+	{"GCP", "gcp", "google cloud", "google cloud platform"},
+	{"Azure", "azure", "microsoft azure"},
+	{"Docker", "docker", "containers", "containerization"},
+	{"Kubernetes", "kubernetes", "k8s"},
+	{"Terraform", "terraform"},
+	{"Ansible", "ansible"},
+	{"Heroku", "heroku"},
+	{"Vercel", "vercel"},
+	{"Nginx", "nginx"},
+	{"Linux", "linux", "unix", "ubuntu", "debian"},
+	{"CI/CD", "ci/cd", "ci cd", "continuous integration", "continuous delivery", "continuous deployment"},
+	{"GitHub Actions", "github actions"},
+	{"Jenkins", "jenkins"},
+	{"Microservices", "microservices", "microservice"},
+	{"Serverless", "serverless"},
+
+	// Tools and practices
+	{"Git", "git", "github", "gitlab"},
+	{"Agile", "agile"},
+	{"Scrum", "scrum"},
+	{"Test Automation", "automated testing", "test automation", "unit testing", "unit tests", "integration testing"},
+	{"TDD", "tdd", "test-driven development", "test driven development"},
+	{"Jest", "jest"},
+	{"Cypress", "cypress"},
+	{"Selenium", "selenium"},
+	{"Webpack", "webpack"},
+	{"Vite", "vite"},
+	{"Figma", "figma"},
+	{"Jira", "jira"},
+	{"VS Code", "vs code", "vscode", "visual studio code"},
+	{"GCC", "gcc"},
+	{"LLVM", "llvm", "clang"},
+	{"CMake", "cmake"},
+	{"OpenAPI", "openapi", "swagger"},
+	{"WebSockets", "websockets", "websocket"},
+
+	// Security
+	{"Cryptography", "cryptography", "crypto", "encryption"},
+	{"TLS", "tls", "ssl", "https"},
+	{"RSA", "rsa"},
+	{"Penetration Testing", "penetration testing", "pentesting", "pen testing", "pentest"},
+	{"Wireshark", "wireshark"},
+	{"Burp Suite", "burp", "burp suite"},
+	{"Metasploit", "metasploit"},
+	{"OWASP", "owasp", "owasp top 10"},
+	{"Reverse Engineering", "reverse engineering", "ghidra", "ida pro"},
+	{"Threat Modeling", "threat modeling", "threat modelling", "threat models"},
+	{"OAuth", "oauth", "oauth2", "oidc", "openid connect"},
+	{"Kernel Development", "kernel", "linux kernel", "kernel development"},
+
+	// Data and machine learning
+	{"Machine Learning", "machine learning", "ml"},
+	{"Deep Learning", "deep learning"},
+	{"NLP", "nlp", "natural language processing"},
+	{"LLMs", "llm", "llms", "large language models"},
+	{"Data Analysis", "data analysis", "data analytics"},
+	{"ETL", "etl"},
+	{"Spark", "=Spark", "apache spark", "pyspark"},
+}
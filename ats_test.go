@@ -0,0 +1,78 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/daveonthegit/Personal_Portfolio/config"
+)
+
+func TestATSTokenize(t *testing.T) {
+	var got []string
+	for _, tok := range atsTokenize("C++, C#, Node.js and .NET; scikit-learn. HTML/CSS R&D") {
+		got = append(got, tok.text)
+	}
+	want := []string{"C++", "C#", "Node.js", "and", ".NET", "scikit-learn", "HTML", "CSS", "R", "D"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestATSExtract(t *testing.T) {
+	d := newATSDictionary()
+	for _, tc := range []struct {
+		text string
+		want []string
+	}{
+		{"React Native and React", []string{"React Native", "React"}},
+		{"Go, golang, and go to market", []string{"Go", "Go"}},
+		{"C/C++ and C#", []string{"C", "C++", "C#"}},
+		{"Experience with C, R and SQL.", []string{"C", "R", "SQL"}},
+		{"(R)", []string{"R"}},
+
+		// One-letter names inside other words are not matches
+		{"Our R&D team", nil},
+		{"Work with AT&T and C&C", nil},
+		{"R's ecosystem", nil},
+		{"email r@example.com", nil},
+		{"a C_API wrapper", nil},
+	} {
+		if got := d.extract(tc.text); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("extract(%q) = %q, want %q", tc.text, got, tc.want)
+		}
+	}
+}
+
+func TestATSAnalyze(t *testing.T) {
+	info := config.PersonalInfo{
+		Skills:         []config.Skill{{Category: "Languages", Items: []string{"Go", "C/C++", "Discord.js"}}},
+		Experience:     []config.Experience{{Technologies: []string{"PostgreSQL"}}},
+		ResumeProjects: []string{"bot"},
+	}
+	projects := []Project{
+		{ID: "bot", Title: "Bot", Technologies: []string{"Discord.js"}},
+		{ID: "api", Title: "API", Description: "A Go service on PostgreSQL.", Technologies: []string{"Go"}},
+	}
+	report := newATSProfile(info, projects).Analyze("Go and Rust in our R&D lab. Go, PostgreSQL, Discord.js.")
+
+	if want := []string{"Go", "Rust", "PostgreSQL", "Discord.js"}; !reflect.DeepEqual(report.Terms, want) {
+		t.Errorf("terms = %q, want %q", report.Terms, want)
+	}
+	if want := []string{"Rust"}; !reflect.DeepEqual(report.Missing, want) {
+		t.Errorf("missing = %q, want %q", report.Missing, want)
+	}
+	if report.Coverage != 0.75 {
+		t.Errorf("coverage = %v, want 0.75", report.Coverage)
+	}
+	for _, m := range report.Matched {
+		if m.Term == "Go" && strings.Join(m.Sources, ",") != "skills,projects" {
+			t.Errorf("Go found in %q", m.Sources)
+		}
+	}
+
+	s := report.SuggestedProjects
+	if len(s) != 2 || s[0].ID != "api" || s[0].Score != 3 || s[1].ID != "bot" || !s[1].Featured {
+		t.Errorf("suggested %+v", s)
+	}
+}
@@ -0,0 +1,416 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Default limits for a LaTeX compilation
+const (
+	defaultCompileTimeout = 60 * time.Second
+	defaultMaxCompileLog  = 1 << 20  // Engine output kept, in bytes
+	defaultMaxOutput      = 20 << 20 // Total size of files the engine may write
+)
+
+// ErrNoLaTeXEngine is returned when none of the supported engines is installed
+var ErrNoLaTeXEngine = errors.New("no LaTeX engine found; install latexmk, lualatex, xelatex or pdflatex")
+
+// CompileTimeoutError means the engine ran past its deadline and was killed
+type CompileTimeoutError struct {
+	Engine  string
+	Timeout time.Duration
+}
+
+func (e *CompileTimeoutError) Error() string {
+	return fmt.Sprintf("%s timed out after %v", e.Engine, e.Timeout)
+}
+
+// LogLimitError means the engine printed more than the log limit and was killed
+type LogLimitError struct {
+	Engine string
+	Limit  int64
+}
+
+func (e *LogLimitError) Error() string {
+	return fmt.Sprintf("%s output exceeded %d bytes", e.Engine, e.Limit)
+}
+
+// OutputLimitError means the engine wrote more than the output limit to its
+// scratch directory and was killed
+type OutputLimitError struct {
+	Engine string
+	Limit  int64
+}
+
+func (e *OutputLimitError) Error() string {
+	return fmt.Sprintf("%s wrote more than %d bytes of output", e.Engine, e.Limit)
+}
+
+// EngineError means the engine exited unsuccessfully or produced no PDF
+type EngineError struct {
+	Engine   string
+	ExitCode int
+	Msg      string
+}
+
+func (e *EngineError) Error() string {
+	return fmt.Sprintf("%s failed with exit status %d: %s", e.Engine, e.ExitCode, e.Msg)
+}
+
+// DependencyError means a file the source includes could not be copied into
+// the scratch directory
+type DependencyError struct {
+	Path string
+	Msg  string
+}
+
+func (e *DependencyError) Error() string {
+	return fmt.Sprintf("dependency %s: %s", e.Path, e.Msg)
+}
+
+// latexEngines are the engines CompileLaTeX tries, in order of preference,
+// with the arguments that precede the source file name
+var latexEngines = []struct {
+	name string
+	args []string
+}{
+	{"latexmk", []string{"-pdf", "-interaction=nonstopmode", "-latexoption=-no-shell-escape"}},
+	{"lualatex", []string{"-interaction=nonstopmode", "-no-shell-escape"}},
+	{"xelatex", []string{"-interaction=nonstopmode", "-no-shell-escape"}},
+	{"pdflatex", []string{"-interaction=nonstopmode", "-no-shell-escape"}},
+}
+
+// preferredLaTeXEngine returns the engine CompileLaTeX will try first, or an
+// empty string if none is installed
+func preferredLaTeXEngine() string {
+	for _, engine := range latexEngines {
+		if _, err := exec.LookPath(engine.name); err == nil {
+			return engine.name
+		}
+	}
+	return ""
+}
+
+// CompileOptions bounds a LaTeX compilation. Zero values use the defaults.
+type CompileOptions struct {
+	Timeout   time.Duration // Deadline for the whole compilation, across engines
+	MaxLog    int64         // Bytes of engine output before it is killed
+	MaxOutput int64         // Bytes the engine may write before it is killed
+}
+
+// compileOptionsFromEnv reads LATEX_TIMEOUT_SECONDS, leaving the size limits
+// at their defaults
+func compileOptionsFromEnv() CompileOptions {
+	return CompileOptions{
+		Timeout: time.Duration(getEnvInt("LATEX_TIMEOUT_SECONDS", int(defaultCompileTimeout/time.Second))) * time.Second,
+	}
+}
+
+func (o CompileOptions) withDefaults() CompileOptions {
+	if o.Timeout <= 0 {
+		o.Timeout = defaultCompileTimeout
+	}
+	if o.MaxLog <= 0 {
+		o.MaxLog = defaultMaxCompileLog
+	}
+	if o.MaxOutput <= 0 {
+		o.MaxOutput = defaultMaxOutput
+	}
+	return o
+}
+
+// compileResult describes a LaTeX compilation attempt
+type compileResult struct {
+	Engine   string // Engine that produced the PDF, or the last one tried
+	ExitCode int    // Exit status of that engine, -1 if it did not finish
+	Log      []byte // Combined output of every engine tried, capped at MaxLog
+}
+
+// CompileLaTeX compiles texPath to pdfPath with the first engine that
+// succeeds. The source and the local files it includes are copied into a
+// private scratch directory where the engine runs with shell escape
+// disabled and TeX's file access restricted to that directory. The engine's
+// process group is killed when it passes the deadline or the log or output
+// limits. The PDF is written to pdfPath atomically.
+func CompileLaTeX(ctx context.Context, texPath, pdfPath string, opts CompileOptions) (result compileResult, err error) {
+	opts = opts.withDefaults()
+	result.ExitCode = -1
+
+	scratch, err := os.MkdirTemp("", "resume-compile-")
+	if err != nil {
+		return result, fmt.Errorf("failed to create scratch directory: %v", err)
+	}
+	defer os.RemoveAll(scratch)
+
+	if err := copySourceTree(texPath, scratch); err != nil {
+		return result, err
+	}
+
+	texName := filepath.Base(texPath)
+	builtPDF := filepath.Join(scratch, strings.TrimSuffix(texName, filepath.Ext(texName))+".pdf")
+
+	ctx, cancel := context.WithTimeout(ctx, opts.Timeout)
+	defer cancel()
+
+	logBuf := &cappedBuffer{limit: opts.MaxLog}
+	defer func() { result.Log = logBuf.Bytes() }()
+
+	found := false
+	var lastErr error
+	for _, engine := range latexEngines {
+		if _, err := exec.LookPath(engine.name); err != nil {
+			continue // Skip if engine not found
+		}
+		found = true
+
+		args := append(append([]string(nil), engine.args...), texName)
+		err := runEngine(ctx, engine.name, args, scratch, builtPDF, logBuf, opts, &result)
+		// For engines that need multiple passes, try again
+		if isEngineError(err) && engine.name != "latexmk" {
+			err = runEngine(ctx, engine.name, args, scratch, builtPDF, logBuf, opts, &result)
+		}
+		if err == nil {
+			pdf, err := os.ReadFile(builtPDF)
+			if err != nil {
+				return result, err
+			}
+			return result, writeFileAtomic(pdfPath, pdf)
+		}
+		if !isEngineError(err) {
+			// Timeouts and limits come from the source, so another engine
+			// would fare no better
+			return result, err
+		}
+		lastErr = err
+	}
+
+	if !found {
+		return result, ErrNoLaTeXEngine
+	}
+	return result, lastErr
+}
+
+// runEngine runs one engine pass in dir and classifies how it ended
+func runEngine(ctx context.Context, engine string, args []string, dir, builtPDF string, logBuf *cappedBuffer, opts CompileOptions, result *compileResult) error {
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	logBuf.setOnLimit(cancel)
+	fmt.Fprintf(logBuf, "==> %s %s\n", engine, strings.Join(args, " "))
+
+	cmd := exec.CommandContext(runCtx, engine, args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		// Paranoid mode: TeX may only open files below the working directory
+		"openout_any=p",
+		"openin_any=p",
+		"shell_escape=f",
+	)
+	cmd.Stdout = logBuf
+	cmd.Stderr = logBuf
+	setProcessGroup(cmd)
+	cmd.Cancel = func() error { return killProcessGroup(cmd) }
+	cmd.WaitDelay = 2 * time.Second
+
+	// Watch the scratch directory so runaway output is stopped early
+	var outputExceeded bool
+	var mu sync.Mutex
+	stopWatch := make(chan struct{})
+	defer close(stopWatch)
+	go func() {
+		ticker := time.NewTicker(200 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stopWatch:
+				return
+			case <-ticker.C:
+				if dirSize(dir) > opts.MaxOutput {
+					mu.Lock()
+					outputExceeded = true
+					mu.Unlock()
+					cancel()
+					return
+				}
+			}
+		}
+	}()
+
+	err := cmd.Run()
+
+	result.Engine = engine
+	result.ExitCode = -1
+	if cmd.ProcessState != nil {
+		result.ExitCode = cmd.ProcessState.ExitCode()
+	}
+
+	mu.Lock()
+	exceeded := outputExceeded || dirSize(dir) > opts.MaxOutput
+	mu.Unlock()
+
+	switch {
+	case ctx.Err() == context.DeadlineExceeded:
+		return &CompileTimeoutError{Engine: engine, Timeout: opts.Timeout}
+	case logBuf.Exceeded():
+		return &LogLimitError{Engine: engine, Limit: opts.MaxLog}
+	case exceeded:
+		return &OutputLimitError{Engine: engine, Limit: opts.MaxOutput}
+	case err != nil:
+		return &EngineError{Engine: engine, ExitCode: result.ExitCode, Msg: err.Error()}
+	case !fileExists(builtPDF):
+		return &EngineError{Engine: engine, ExitCode: result.ExitCode, Msg: "no PDF produced"}
+	}
+	return nil
+}
+
+func isEngineError(err error) bool {
+	var engineErr *EngineError
+	return errors.As(err, &engineErr)
+}
+
+var includePattern = regexp.MustCompile(`\\(?:input|include|includegraphics(?:\[[^\]]*\])?)\{([^}]+)\}`)
+
+// latexDependencies returns the local files texPath includes with \input,
+// \include or \includegraphics, relative to its directory. Names that do not
+// exist locally, such as glyphtounicode from the TeX distribution, are
+// skipped.
+func latexDependencies(texPath string) ([]string, error) {
+	source, err := os.ReadFile(texPath)
+	if err != nil {
+		return nil, err
+	}
+	dir := filepath.Dir(texPath)
+
+	var deps []string
+	seen := make(map[string]bool)
+	for _, m := range includePattern.FindAllSubmatch(stripLaTeXComments(source), -1) {
+		name := strings.TrimSpace(string(m[1]))
+		for _, candidate := range []string{name, name + ".tex"} {
+			if seen[candidate] {
+				break
+			}
+			if info, err := os.Stat(filepath.Join(dir, candidate)); err == nil && info.Mode().IsRegular() {
+				seen[candidate] = true
+				deps = append(deps, candidate)
+				break
+			}
+		}
+	}
+	return deps, nil
+}
+
+// stripLaTeXComments removes % comments so commented-out includes are ignored
+func stripLaTeXComments(source []byte) []byte {
+	lines := strings.Split(string(source), "\n")
+	for i, line := range lines {
+		for j := 0; j < len(line); j++ {
+			if line[j] == '%' && (j == 0 || line[j-1] != '\\') {
+				lines[i] = line[:j]
+				break
+			}
+		}
+	}
+	return []byte(strings.Join(lines, "\n"))
+}
+
+// copySourceTree copies texPath and its local dependencies into dir.
+// Dependencies must stay inside the source directory.
+func copySourceTree(texPath, dir string) error {
+	deps, err := latexDependencies(texPath)
+	if err != nil {
+		return fmt.Errorf("failed to read LaTeX source: %v", err)
+	}
+
+	srcDir := filepath.Dir(texPath)
+	for _, name := range append([]string{filepath.Base(texPath)}, deps...) {
+		clean := filepath.Clean(name)
+		if filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+			return &DependencyError{Path: name, Msg: "outside the source directory"}
+		}
+
+		data, err := os.ReadFile(filepath.Join(srcDir, clean))
+		if err != nil {
+			return &DependencyError{Path: name, Msg: err.Error()}
+		}
+		dest := filepath.Join(dir, clean)
+		if err := os.MkdirAll(filepath.Dir(dest), 0700); err != nil {
+			return &DependencyError{Path: name, Msg: err.Error()}
+		}
+		if err := os.WriteFile(dest, data, 0600); err != nil {
+			return &DependencyError{Path: name, Msg: err.Error()}
+		}
+	}
+	return nil
+}
+
+// dirSize returns the total size of the regular files below dir
+func dirSize(dir string) int64 {
+	var total int64
+	filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.Type().IsRegular() {
+			if info, err := d.Info(); err == nil {
+				total += info.Size()
+			}
+		}
+		return nil
+	})
+	return total
+}
+
+// cappedBuffer collects engine output up to limit bytes. Writing past the
+// limit calls onLimit once, which stops the engine.
+type cappedBuffer struct {
+	mu       sync.Mutex
+	buf      []byte
+	limit    int64
+	exceeded bool
+	onLimit  func()
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if room := b.limit - int64(len(b.buf)); int64(len(p)) > room {
+		b.buf = append(b.buf, p[:max(room, 0)]...)
+		if !b.exceeded {
+			b.exceeded = true
+			if b.onLimit != nil {
+				b.onLimit()
+			}
+		}
+		return len(p), nil
+	}
+	b.buf = append(b.buf, p...)
+	return len(p), nil
+}
+
+func (b *cappedBuffer) setOnLimit(fn func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.onLimit = fn
+}
+
+func (b *cappedBuffer) Exceeded() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.exceeded
+}
+
+func (b *cappedBuffer) Bytes() []byte {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]byte(nil), b.buf...)
+}
@@ -0,0 +1,146 @@
+//go:build unix
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// compileFake compiles a one-line source with the fake engine behaving as
+// mode says
+func compileFake(t *testing.T, mode string, opts CompileOptions) (compileResult, string, error) {
+	t.Helper()
+	fakeLaTeX(t)
+	t.Setenv("FAKE_LATEX", mode)
+	dir := t.TempDir()
+	tex := filepath.Join(dir, "resume.tex")
+	os.WriteFile(tex, []byte(`\documentclass{article}`), 0644)
+	pdf := filepath.Join(dir, "out", "resume.pdf")
+	os.MkdirAll(filepath.Dir(pdf), 0755)
+	result, err := CompileLaTeX(context.Background(), tex, pdf, opts)
+	return result, pdf, err
+}
+
+func TestCompileLaTeX(t *testing.T) {
+	result, pdf, err := compileFake(t, "", CompileOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Engine != "latexmk" || result.ExitCode != 0 || !fileExists(pdf) {
+		t.Errorf("got %+v, want a PDF from latexmk", result)
+	}
+	if !strings.Contains(string(result.Log), "fake latexmk resume.tex") {
+		t.Errorf("log = %q", result.Log)
+	}
+}
+
+func TestCompileLaTeXEngineFailure(t *testing.T) {
+	result, pdf, err := compileFake(t, "fail", CompileOptions{})
+	var engineErr *EngineError
+	if !errors.As(err, &engineErr) || engineErr.ExitCode != 1 {
+		t.Fatalf("got %v, want an EngineError with exit status 1", err)
+	}
+	if fileExists(pdf) || !strings.Contains(string(result.Log), "Undefined control sequence") {
+		t.Errorf("got a PDF %v and log %q", fileExists(pdf), result.Log)
+	}
+}
+
+func TestCompileLaTeXTimeout(t *testing.T) {
+	pidFile := filepath.Join(t.TempDir(), "pid")
+	t.Setenv("FAKE_LATEX_PID", pidFile)
+
+	start := time.Now()
+	_, _, err := compileFake(t, "hang", CompileOptions{Timeout: 500 * time.Millisecond})
+	var timeout *CompileTimeoutError
+	if !errors.As(err, &timeout) || timeout.Timeout != 500*time.Millisecond {
+		t.Fatalf("got %v, want a CompileTimeoutError", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("took %v to stop the engine", elapsed)
+	}
+
+	// The engine's own children are killed with it
+	data, err := os.ReadFile(pidFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pid, _ := strconv.Atoi(strings.TrimSpace(string(data)))
+	deadline := time.Now().Add(2 * time.Second)
+	for running(pid) {
+		if time.Now().After(deadline) {
+			t.Fatalf("child process %d still running", pid)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+// running reports whether process pid is alive. One killed but not yet
+// reaped, which in a container can take a while, counts as dead.
+func running(pid int) bool {
+	if syscall.Kill(pid, 0) != nil {
+		return false
+	}
+	stat, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return true // No /proc to tell a zombie by
+	}
+	state := stat[bytes.LastIndexByte(stat, ')')+2:]
+	return len(state) == 0 || state[0] != 'Z'
+}
+
+func TestCompileLaTeXLogLimit(t *testing.T) {
+	result, _, err := compileFake(t, "chatty", CompileOptions{MaxLog: 64 << 10})
+	var limit *LogLimitError
+	if !errors.As(err, &limit) || limit.Limit != 64<<10 {
+		t.Fatalf("got %v, want a LogLimitError", err)
+	}
+	if len(result.Log) != 64<<10 {
+		t.Errorf("kept %d bytes of log, want %d", len(result.Log), 64<<10)
+	}
+}
+
+func TestCompileLaTeXOutputLimit(t *testing.T) {
+	start := time.Now()
+	_, pdf, err := compileFake(t, "bloat", CompileOptions{MaxOutput: 1 << 20})
+	var limit *OutputLimitError
+	if !errors.As(err, &limit) || limit.Limit != 1<<20 {
+		t.Fatalf("got %v, want an OutputLimitError", err)
+	}
+	if fileExists(pdf) || time.Since(start) > 5*time.Second {
+		t.Errorf("engine not stopped promptly")
+	}
+}
+
+func TestCompileLaTeXWithoutEngine(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+	tex := filepath.Join(t.TempDir(), "resume.tex")
+	os.WriteFile(tex, nil, 0644)
+	if _, err := CompileLaTeX(context.Background(), tex, tex+".pdf", CompileOptions{}); err != ErrNoLaTeXEngine {
+		t.Errorf("got %v, want ErrNoLaTeXEngine", err)
+	}
+}
+
+func TestCompileLaTeXRejectsOutsideDependencies(t *testing.T) {
+	fakeLaTeX(t)
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "secret.tex"), []byte("secret"), 0644)
+	os.MkdirAll(filepath.Join(dir, "src"), 0755)
+	tex := filepath.Join(dir, "src", "resume.tex")
+	os.WriteFile(tex, []byte(`\input{../secret}`), 0644)
+
+	_, err := CompileLaTeX(context.Background(), tex, tex+".pdf", CompileOptions{})
+	var depErr *DependencyError
+	if !errors.As(err, &depErr) {
+		t.Errorf("got %v, want a DependencyError", err)
+	}
+}
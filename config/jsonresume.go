@@ -0,0 +1,317 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// JSONResumeSchema is the schema exported resumes declare
+const JSONResumeSchema = "https://raw.githubusercontent.com/jsonresume/resume-schema/v1.0.0/schema.json"
+
+// JSONResume is a resume in the jsonresume.org schema. Only the sections
+// PersonalInfo can represent are modelled; others are ignored on import.
+type JSONResume struct {
+	Schema    string               `json:"$schema,omitempty"`
+	Basics    JSONResumeBasics     `json:"basics"`
+	Work      []JSONResumeWork     `json:"work,omitempty"`
+	Education []JSONResumeSchool   `json:"education,omitempty"`
+	Skills    []JSONResumeSkill    `json:"skills,omitempty"`
+	Interests []JSONResumeInterest `json:"interests,omitempty"`
+	Projects  []JSONResumeProject  `json:"projects,omitempty"`
+}
+
+type JSONResumeBasics struct {
+	Name     string              `json:"name"`
+	Label    string              `json:"label,omitempty"`
+	Email    string              `json:"email,omitempty"`
+	Phone    string              `json:"phone,omitempty"`
+	URL      string              `json:"url,omitempty"`
+	Summary  string              `json:"summary,omitempty"`
+	Location *JSONResumeLocation `json:"location,omitempty"`
+	Profiles []JSONResumeProfile `json:"profiles,omitempty"`
+}
+
+type JSONResumeLocation struct {
+	Address     string `json:"address,omitempty"`
+	PostalCode  string `json:"postalCode,omitempty"`
+	City        string `json:"city,omitempty"`
+	CountryCode string `json:"countryCode,omitempty"`
+	Region      string `json:"region,omitempty"`
+}
+
+type JSONResumeProfile struct {
+	Network  string `json:"network"`
+	Username string `json:"username,omitempty"`
+	URL      string `json:"url,omitempty"`
+}
+
+type JSONResumeWork struct {
+	Name       string   `json:"name"`
+	Position   string   `json:"position"`
+	Location   string   `json:"location,omitempty"`
+	URL        string   `json:"url,omitempty"`
+	StartDate  string   `json:"startDate,omitempty"`
+	EndDate    string   `json:"endDate,omitempty"` // Omitted for the current position
+	Summary    string   `json:"summary,omitempty"`
+	Highlights []string `json:"highlights,omitempty"`
+}
+
+type JSONResumeSchool struct {
+	Institution string   `json:"institution"`
+	URL         string   `json:"url,omitempty"`
+	Area        string   `json:"area,omitempty"`
+	StudyType   string   `json:"studyType,omitempty"`
+	StartDate   string   `json:"startDate,omitempty"`
+	EndDate     string   `json:"endDate,omitempty"`
+	Score       string   `json:"score,omitempty"`
+	Courses     []string `json:"courses,omitempty"`
+}
+
+type JSONResumeSkill struct {
+	Name     string   `json:"name"`
+	Level    string   `json:"level,omitempty"`
+	Keywords []string `json:"keywords,omitempty"`
+}
+
+type JSONResumeInterest struct {
+	Name     string   `json:"name"`
+	Keywords []string `json:"keywords,omitempty"`
+}
+
+type JSONResumeProject struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Highlights  []string `json:"highlights,omitempty"`
+	Keywords    []string `json:"keywords,omitempty"`
+	StartDate   string   `json:"startDate,omitempty"`
+	URL         string   `json:"url,omitempty"`
+	Type        string   `json:"type,omitempty"`
+}
+
+// jsonResumeDate is the date layout used in exported resumes
+const jsonResumeDate = "2006-01-02"
+
+// ToJSONResume converts personal info to the JSON Resume schema. Projects
+// are left for the caller to fill in.
+func ToJSONResume(info PersonalInfo) JSONResume {
+	summary := info.Summary
+	if summary == "" {
+		summary = info.Bio
+	}
+
+	r := JSONResume{
+		Schema: JSONResumeSchema,
+		Basics: JSONResumeBasics{
+			Name:    info.Name,
+			Label:   info.Title,
+			Email:   info.Email,
+			Phone:   info.Phone,
+			URL:     info.Website,
+			Summary: summary,
+		},
+	}
+
+	if info.Location != "" {
+		loc := &JSONResumeLocation{City: info.Location}
+		if i := strings.LastIndex(info.Location, ","); i != -1 {
+			loc.City = strings.TrimSpace(info.Location[:i])
+			loc.Region = strings.TrimSpace(info.Location[i+1:])
+		}
+		r.Basics.Location = loc
+	}
+	for _, p := range []struct{ network, url string }{{"LinkedIn", info.LinkedIn}, {"GitHub", info.GitHub}} {
+		if p.url != "" {
+			r.Basics.Profiles = append(r.Basics.Profiles, JSONResumeProfile{
+				Network:  p.network,
+				Username: profileUsername(p.url),
+				URL:      p.url,
+			})
+		}
+	}
+
+	for _, e := range info.Experience {
+		work := JSONResumeWork{
+			Name:       e.Company,
+			Position:   e.Position,
+			Location:   e.Location,
+			StartDate:  formatJSONResumeDate(e.StartDate),
+			Highlights: e.Description,
+		}
+		if e.EndDate != nil {
+			work.EndDate = e.EndDate.Format(jsonResumeDate)
+		}
+		r.Work = append(r.Work, work)
+	}
+
+	for _, e := range info.Education {
+		r.Education = append(r.Education, JSONResumeSchool{
+			Institution: e.Institution,
+			Area:        e.Field,
+			StudyType:   e.Degree,
+			StartDate:   formatJSONResumeDate(e.StartDate),
+			EndDate:     formatJSONResumeDate(e.EndDate),
+			Score:       e.GPA,
+		})
+	}
+
+	for _, s := range info.Skills {
+		r.Skills = append(r.Skills, JSONResumeSkill{Name: s.Category, Keywords: s.Items})
+	}
+	for _, interest := range info.Interests {
+		r.Interests = append(r.Interests, JSONResumeInterest{Name: interest})
+	}
+
+	return r
+}
+
+// profileUsername returns the last path segment of a profile URL
+func profileUsername(profileURL string) string {
+	u, err := url.Parse(profileURL)
+	if err != nil {
+		return ""
+	}
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	return segments[len(segments)-1]
+}
+
+// decodeJSONResume reads a JSON Resume document into the on-disk personal
+// format, so it is validated like a YAML or TOML file
+func decodeJSONResume(data []byte) (personalFile, error) {
+	var r JSONResume
+	if err := json.Unmarshal(data, &r); err != nil {
+		return personalFile{}, err
+	}
+
+	b := r.Basics
+	f := personalFile{
+		Name:    b.Name,
+		Title:   b.Label,
+		Email:   b.Email,
+		Phone:   b.Phone,
+		Website: b.URL,
+		Bio:     b.Summary,
+		Summary: b.Summary,
+
+		// Every date in a JSON Resume is optional
+		optionalDates: true,
+	}
+
+	if loc := b.Location; loc != nil {
+		var parts []string
+		for _, part := range []string{loc.City, loc.Region} {
+			if part = strings.TrimSpace(part); part != "" {
+				parts = append(parts, part)
+			}
+		}
+		f.Location = strings.Join(parts, ", ")
+	}
+	for _, p := range b.Profiles {
+		switch strings.ToLower(p.Network) {
+		case "linkedin":
+			f.LinkedIn = p.URL
+		case "github":
+			f.GitHub = p.URL
+		}
+	}
+
+	for _, w := range r.Work {
+		exp := experienceFile{
+			Company:     w.Name,
+			Position:    w.Position,
+			StartDate:   jsonResumeDateToISO(w.StartDate),
+			Location:    w.Location,
+			Description: w.Highlights,
+		}
+		if w.EndDate != "" {
+			end := jsonResumeDateToISO(w.EndDate)
+			exp.EndDate = &end
+		}
+		if len(exp.Description) == 0 && w.Summary != "" {
+			exp.Description = []string{w.Summary}
+		}
+		f.Experience = append(f.Experience, exp)
+	}
+
+	for _, e := range r.Education {
+		f.Education = append(f.Education, educationFile{
+			Institution: e.Institution,
+			Degree:      e.StudyType,
+			Field:       e.Area,
+			StartDate:   jsonResumeDateToISO(e.StartDate),
+			EndDate:     jsonResumeDateToISO(e.EndDate),
+			GPA:         e.Score,
+		})
+	}
+
+	for _, s := range r.Skills {
+		f.Skills = append(f.Skills, skillFile{Category: s.Name, Items: s.Keywords})
+	}
+	for _, interest := range r.Interests {
+		f.Interests = append(f.Interests, interest.Name)
+	}
+
+	return f, nil
+}
+
+// formatJSONResumeDate formats t for JSON Resume, leaving unknown (zero)
+// dates out
+func formatJSONResumeDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(jsonResumeDate)
+}
+
+// jsonResumeDateToISO widens a year-only JSON Resume date to a month, the
+// coarsest precision ParseDate accepts
+func jsonResumeDateToISO(date string) string {
+	date = strings.TrimSpace(date)
+	if len(date) == 4 {
+		return date + "-01"
+	}
+	return date
+}
+
+// jsonResumeErrors renames the fields in validation errors to their JSON
+// Resume paths, such as work[0].name for experience[0].company
+func jsonResumeErrors(errs []error) []error {
+	sections := map[string]string{"experience": "work", "name": "basics.name", "email": "basics.email"}
+	leaves := map[string]string{
+		"work.company":         "name",
+		"education.degree":     "studyType",
+		"skills.category":      "name",
+		"skills.items":         "keywords",
+		"work.start_date":      "startDate",
+		"work.end_date":        "endDate",
+		"education.start_date": "startDate",
+		"education.end_date":   "endDate",
+	}
+
+	for _, err := range errs {
+		var v *ValidationError
+		if !errors.As(err, &v) {
+			continue
+		}
+		section, leaf, hasLeaf := strings.Cut(v.Field, ".")
+		index := ""
+		if i := strings.IndexByte(section, '['); i != -1 {
+			section, index = section[:i], section[i:]
+		}
+		if renamed, ok := sections[section]; ok {
+			section = renamed
+		}
+		if !hasLeaf {
+			v.Field = section + index
+			continue
+		}
+		if renamed, ok := leaves[section+"."+leaf]; ok {
+			leaf = renamed
+		}
+		v.Field = fmt.Sprintf("%s%s.%s", section, index, leaf)
+	}
+	return errs
+}
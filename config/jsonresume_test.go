@@ -0,0 +1,161 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// loadJSONResume writes r to a resume.json and loads it back
+func loadJSONResume(t *testing.T, r interface{}) (PersonalInfo, error) {
+	t.Helper()
+	data, err := json.Marshal(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "resume.json")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return LoadPersonalInfo(path)
+}
+
+func TestJSONResumeRoundTrip(t *testing.T) {
+	end := date(2024, time.August, 15)
+	info := PersonalInfo{
+		Name:     "Ada Lovelace",
+		Title:    "Analyst",
+		Email:    "ada@example.com",
+		Phone:    "+44 20 7946 0000",
+		Location: "London, UK",
+		LinkedIn: "https://www.linkedin.com/in/ada/",
+		GitHub:   "https://github.com/ada",
+		Website:  "https://ada.example.com",
+		Bio:      "Mathematician and writer.",
+		Summary:  "Mathematician and writer.",
+		Skills:   []Skill{{Category: "Mathematics", Items: []string{"Analysis", "Notes"}}},
+		Experience: []Experience{
+			{Company: "Engine Society", Position: "Analyst", StartDate: date(2024, time.January, 1), EndDate: &end, Location: "London", Description: []string{"Wrote notes"}},
+			{Company: "Babbage & Co", Position: "Translator", StartDate: date(2025, time.March, 1)},
+		},
+		Education: []Education{{Institution: "Home", Degree: "Private tutoring", Field: "Mathematics", StartDate: date(2020, time.September, 1), EndDate: date(2024, time.June, 1), GPA: "4.0"}},
+		Interests: []string{"Poetical science"},
+	}
+
+	r := ToJSONResume(info)
+	if r.Schema != JSONResumeSchema {
+		t.Errorf("schema = %q", r.Schema)
+	}
+	if loc := r.Basics.Location; loc == nil || loc.City != "London" || loc.Region != "UK" {
+		t.Errorf("location = %+v", loc)
+	}
+	var usernames []string
+	for _, p := range r.Basics.Profiles {
+		usernames = append(usernames, p.Network+":"+p.Username)
+	}
+	if got := strings.Join(usernames, " "); got != "LinkedIn:ada GitHub:ada" {
+		t.Errorf("profiles = %s", got)
+	}
+	if r.Work[1].EndDate != "" {
+		t.Errorf("current position exported with end date %q", r.Work[1].EndDate)
+	}
+
+	got, err := loadJSONResume(t, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, info) {
+		t.Errorf("round trip changed the resume\ngot  %+v\nwant %+v", got, info)
+	}
+}
+
+func TestJSONResumeImport(t *testing.T) {
+	info, err := loadJSONResume(t, map[string]interface{}{
+		"basics": map[string]interface{}{
+			"name":     "Ada",
+			"summary":  "Summary",
+			"profiles": []map[string]string{{"network": "github", "url": "https://github.com/ada"}},
+		},
+		"work": []map[string]interface{}{
+			{"name": "Engine Society", "position": "Analyst", "startDate": "2024", "summary": "Wrote notes"},
+		},
+		"education": []map[string]interface{}{
+			{"institution": "Home", "studyType": "Tutoring", "startDate": "2020-09", "endDate": "2024"},
+		},
+		"volunteer": []map[string]interface{}{{"organization": "Ignored"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Bio != "Summary" || info.GitHub != "https://github.com/ada" {
+		t.Errorf("basics imported as %+v", info)
+	}
+	// Year-only dates widen to January, and a summary stands in for highlights
+	work := info.Experience[0]
+	if !work.StartDate.Equal(date(2024, time.January, 1)) || work.EndDate != nil || !reflect.DeepEqual(work.Description, []string{"Wrote notes"}) {
+		t.Errorf("work imported as %+v", work)
+	}
+	if school := info.Education[0]; !school.StartDate.Equal(date(2020, time.September, 1)) || !school.EndDate.Equal(date(2024, time.January, 1)) {
+		t.Errorf("education imported as %+v", school)
+	}
+}
+
+func TestJSONResumeImportWithoutDates(t *testing.T) {
+	info, err := loadJSONResume(t, map[string]interface{}{
+		"basics":    map[string]interface{}{"name": "Ada"},
+		"work":      []map[string]interface{}{{"name": "Engine Society", "position": "Analyst", "endDate": "2024-08"}},
+		"education": []map[string]interface{}{{"institution": "Home", "studyType": "Tutoring"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	work, school := info.Experience[0], info.Education[0]
+	if !work.StartDate.IsZero() || work.EndDate == nil || !work.EndDate.Equal(date(2024, time.August, 1)) {
+		t.Errorf("work imported as %+v", work)
+	}
+	if !school.StartDate.IsZero() || !school.EndDate.IsZero() {
+		t.Errorf("education imported as %+v", school)
+	}
+
+	// and unknown dates stay out of the export
+	r := ToJSONResume(info)
+	if r.Work[0].StartDate != "" || r.Work[0].EndDate != "2024-08-01" || r.Education[0].StartDate != "" || r.Education[0].EndDate != "" {
+		t.Errorf("exported as %+v and %+v", r.Work[0], r.Education[0])
+	}
+}
+
+func TestJSONResumeErrors(t *testing.T) {
+	_, err := loadJSONResume(t, map[string]interface{}{
+		"basics": map[string]interface{}{"email": "nowhere"},
+		"work": []map[string]interface{}{
+			{"position": "Analyst", "startDate": "soon"},
+		},
+		"skills": []map[string]interface{}{{"name": "Empty"}},
+	})
+	if err == nil {
+		t.Fatal("invalid resume loaded")
+	}
+	for _, want := range []string{
+		"resume.json: basics.name: is required",
+		"resume.json: basics.email: ",
+		"resume.json: work[0].name: is required",
+		"resume.json: work[0].startDate: invalid date",
+		"resume.json: skills[0].keywords: must list",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %q", err, want)
+		}
+	}
+
+	if _, err := loadJSONResume(t, []string{"not", "a", "resume"}); err == nil || !strings.Contains(err.Error(), "resume.json: ") {
+		t.Errorf("got %v, want a decoding error naming the file", err)
+	}
+}
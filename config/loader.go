@@ -0,0 +1,270 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// ValidationError describes an invalid value in a personal data file
+type ValidationError struct {
+	Field string
+	Msg   string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Field + ": " + e.Msg
+}
+
+// personalFile mirrors PersonalInfo on disk. Dates are kept as strings so
+// they can be validated with field-level error messages.
+type personalFile struct {
+	Name       string           `yaml:"name" toml:"name"`
+	Title      string           `yaml:"title" toml:"title"`
+	Email      string           `yaml:"email" toml:"email"`
+	Phone      string           `yaml:"phone" toml:"phone"`
+	Location   string           `yaml:"location" toml:"location"`
+	LinkedIn   string           `yaml:"linkedin" toml:"linkedin"`
+	GitHub     string           `yaml:"github" toml:"github"`
+	Website    string           `yaml:"website" toml:"website"`
+	Bio        string           `yaml:"bio" toml:"bio"`
+	Summary    string           `yaml:"summary" toml:"summary"`
+	Skills     []skillFile      `yaml:"skills" toml:"skills"`
+	Experience []experienceFile `yaml:"experience" toml:"experience"`
+	Education  []educationFile  `yaml:"education" toml:"education"`
+	Interests  []string         `yaml:"interests" toml:"interests"`
+
+	ResumeProjects []string `yaml:"resume_projects" toml:"resume_projects"`
+
+	// optionalDates lets dates be empty, as JSON Resume allows; they are
+	// left zero
+	optionalDates bool
+}
+
+type skillFile struct {
+	Category string   `yaml:"category" toml:"category"`
+	Items    []string `yaml:"items" toml:"items"`
+}
+
+type experienceFile struct {
+	Company      string   `yaml:"company" toml:"company"`
+	Position     string   `yaml:"position" toml:"position"`
+	StartDate    string   `yaml:"start_date" toml:"start_date"`
+	EndDate      *string  `yaml:"end_date" toml:"end_date"` // null or omitted for current position
+	Location     string   `yaml:"location" toml:"location"`
+	Description  []string `yaml:"description" toml:"description"`
+	Technologies []string `yaml:"technologies" toml:"technologies"`
+}
+
+type educationFile struct {
+	Institution string `yaml:"institution" toml:"institution"`
+	Degree      string `yaml:"degree" toml:"degree"`
+	Field       string `yaml:"field" toml:"field"`
+	StartDate   string `yaml:"start_date" toml:"start_date"`
+	EndDate     string `yaml:"end_date" toml:"end_date"`
+	GPA         string `yaml:"gpa" toml:"gpa"`
+	Location    string `yaml:"location" toml:"location"`
+}
+
+// FindPersonalFile returns the first personal.yaml, personal.yml,
+// personal.toml or JSON Resume resume.json found in dir
+func FindPersonalFile(dir string) (string, error) {
+	for _, name := range []string{"personal.yaml", "personal.yml", "personal.toml", "resume.json"} {
+		path := filepath.Join(dir, name)
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("no personal.yaml, personal.toml or resume.json found in %s: %w", dir, os.ErrNotExist)
+}
+
+// LoadPersonalInfo reads and validates a personal data file. The format is
+// chosen from the file extension: .yaml, .yml, .toml, or .json for a
+// jsonresume.org resume. All validation problems are returned together,
+// prefixed with the file name.
+func LoadPersonalInfo(path string) (PersonalInfo, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return PersonalInfo{}, err
+	}
+	return ParsePersonalInfo(path, data)
+}
+
+// ParsePersonalInfo decodes and validates personal data that was read from
+// path, as LoadPersonalInfo does
+func ParsePersonalInfo(path string, data []byte) (PersonalInfo, error) {
+	var err error
+	var file personalFile
+	ext := strings.ToLower(filepath.Ext(path))
+	switch ext {
+	case ".yaml", ".yml":
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err := dec.Decode(&file); err != nil {
+			return PersonalInfo{}, fmt.Errorf("%s: %v", path, err)
+		}
+	case ".toml":
+		md, err := toml.Decode(string(data), &file)
+		if err != nil {
+			return PersonalInfo{}, fmt.Errorf("%s: %v", path, err)
+		}
+		if undecoded := md.Undecoded(); len(undecoded) > 0 {
+			return PersonalInfo{}, fmt.Errorf("%s: unknown field %q", path, undecoded[0].String())
+		}
+	case ".json":
+		file, err = decodeJSONResume(data)
+		if err != nil {
+			return PersonalInfo{}, fmt.Errorf("%s: %v", path, err)
+		}
+	default:
+		return PersonalInfo{}, fmt.Errorf("%s: unsupported format, expected .yaml, .toml or .json", path)
+	}
+
+	info, errs := file.toPersonalInfo()
+	if ext == ".json" {
+		errs = jsonResumeErrors(errs)
+	}
+	if len(errs) > 0 {
+		for i, e := range errs {
+			errs[i] = fmt.Errorf("%s: %w", path, e)
+		}
+		return PersonalInfo{}, errors.Join(errs...)
+	}
+	return info, nil
+}
+
+// toPersonalInfo converts and validates the decoded file
+func (f personalFile) toPersonalInfo() (PersonalInfo, []error) {
+	var errs []error
+	invalid := func(field, format string, args ...interface{}) {
+		errs = append(errs, &ValidationError{Field: field, Msg: fmt.Sprintf(format, args...)})
+	}
+	parseDate := func(field, value string) time.Time {
+		if f.optionalDates && strings.TrimSpace(value) == "" {
+			return time.Time{}
+		}
+		t, err := ParseDate(value)
+		if err != nil {
+			invalid(field, "%v", err)
+		}
+		return t
+	}
+
+	info := PersonalInfo{
+		Name:      strings.TrimSpace(f.Name),
+		Title:     strings.TrimSpace(f.Title),
+		Email:     strings.TrimSpace(f.Email),
+		Phone:     strings.TrimSpace(f.Phone),
+		Location:  strings.TrimSpace(f.Location),
+		LinkedIn:  strings.TrimSpace(f.LinkedIn),
+		GitHub:    strings.TrimSpace(f.GitHub),
+		Website:   strings.TrimSpace(f.Website),
+		Bio:       strings.TrimSpace(f.Bio),
+		Summary:   strings.TrimSpace(f.Summary),
+		Interests: f.Interests,
+
+		ResumeProjects: f.ResumeProjects,
+	}
+
+	if info.Name == "" {
+		invalid("name", "is required")
+	}
+	if info.Email != "" && !strings.Contains(info.Email, "@") {
+		invalid("email", "%q is not an email address", info.Email)
+	}
+
+	for i, s := range f.Skills {
+		field := fmt.Sprintf("skills[%d]", i)
+		if strings.TrimSpace(s.Category) == "" {
+			invalid(field+".category", "is required")
+		}
+		if len(s.Items) == 0 {
+			invalid(field+".items", "must list at least one skill")
+		}
+		info.Skills = append(info.Skills, Skill{Category: s.Category, Items: s.Items})
+	}
+
+	for i, e := range f.Experience {
+		field := fmt.Sprintf("experience[%d]", i)
+		if e.Company == "" {
+			invalid(field+".company", "is required")
+		}
+		if e.Position == "" {
+			invalid(field+".position", "is required")
+		}
+
+		exp := Experience{
+			Company:      e.Company,
+			Position:     e.Position,
+			Location:     e.Location,
+			Description:  e.Description,
+			Technologies: e.Technologies,
+		}
+
+		start := parseDate(field+".start_date", e.StartDate)
+		exp.StartDate = start
+
+		if e.EndDate != nil && strings.TrimSpace(*e.EndDate) != "" {
+			end, err := ParseDate(*e.EndDate)
+			if err != nil {
+				invalid(field+".end_date", "%v", err)
+			} else {
+				if !start.IsZero() && end.Before(start) {
+					invalid(field+".end_date", "%s is before start_date %s", *e.EndDate, e.StartDate)
+				}
+				exp.EndDate = &end
+			}
+		}
+
+		info.Experience = append(info.Experience, exp)
+	}
+
+	for i, e := range f.Education {
+		field := fmt.Sprintf("education[%d]", i)
+		if e.Institution == "" {
+			invalid(field+".institution", "is required")
+		}
+		if e.Degree == "" {
+			invalid(field+".degree", "is required")
+		}
+
+		start := parseDate(field+".start_date", e.StartDate)
+		end := parseDate(field+".end_date", e.EndDate)
+		if !start.IsZero() && !end.IsZero() && end.Before(start) {
+			invalid(field+".end_date", "%s is before start_date %s", e.EndDate, e.StartDate)
+		}
+
+		info.Education = append(info.Education, Education{
+			Institution: e.Institution,
+			Degree:      e.Degree,
+			Field:       e.Field,
+			StartDate:   start,
+			EndDate:     end,
+			GPA:         e.GPA,
+			Location:    e.Location,
+		})
+	}
+
+	return info, errs
+}
+
+// ParseDate parses an ISO 8601 date ("2025-06-01") or month ("2025-06")
+func ParseDate(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, errors.New("date is required")
+	}
+	for _, layout := range []string{"2006-01-02", "2006-01"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q, expected YYYY-MM-DD or YYYY-MM", value)
+}
@@ -0,0 +1,190 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// loadPersonal writes data to a file with the given name and loads it back
+func loadPersonal(t *testing.T, name, data string) (PersonalInfo, error) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return LoadPersonalInfo(path)
+}
+
+func wantErrors(t *testing.T, err error, msgs ...string) {
+	t.Helper()
+	if err == nil {
+		t.Fatal("invalid personal info loaded")
+	}
+	for _, msg := range msgs {
+		if !strings.Contains(err.Error(), msg) {
+			t.Errorf("error does not mention %q:\n%v", msg, err)
+		}
+	}
+}
+
+func TestLoadPersonalInfoFormats(t *testing.T) {
+	end := date(2024, time.August, 31)
+	want := PersonalInfo{
+		Name:  "Ada Lovelace",
+		Email: "ada@example.com",
+		Bio:   "Mathematician.",
+		Skills: []Skill{
+			{Category: "Mathematics", Items: []string{"Analysis"}},
+		},
+		Experience: []Experience{
+			{Company: "Engine Society", Position: "Analyst", StartDate: date(2024, time.January, 1), EndDate: &end},
+			{Company: "Babbage & Co", Position: "Translator", StartDate: date(2024, time.September, 1)},
+		},
+		Education:      []Education{{Institution: "Home", Degree: "Tutoring", StartDate: date(2020, time.September, 1), EndDate: date(2024, time.June, 1)}},
+		ResumeProjects: []string{"engine"},
+	}
+
+	for name, data := range map[string]string{
+		"personal.yaml": `
+name: Ada Lovelace
+email: ada@example.com
+bio: Mathematician.
+skills:
+  - category: Mathematics
+    items: [Analysis]
+experience:
+  - company: Engine Society
+    position: Analyst
+    start_date: 2024-01
+    end_date: 2024-08-31
+  - company: Babbage & Co
+    position: Translator
+    start_date: 2024-09-01
+    end_date: null
+education:
+  - institution: Home
+    degree: Tutoring
+    start_date: 2020-09-01
+    end_date: 2024-06
+resume_projects: [engine]
+`,
+		"personal.toml": `
+name = "Ada Lovelace"
+email = "ada@example.com"
+bio = "Mathematician."
+resume_projects = ["engine"]
+
+[[skills]]
+category = "Mathematics"
+items = ["Analysis"]
+
+[[experience]]
+company = "Engine Society"
+position = "Analyst"
+start_date = "2024-01"
+end_date = "2024-08-31"
+
+[[experience]]
+company = "Babbage & Co"
+position = "Translator"
+start_date = "2024-09-01"
+
+[[education]]
+institution = "Home"
+degree = "Tutoring"
+start_date = "2020-09-01"
+end_date = "2024-06"
+`,
+	} {
+		got, err := loadPersonal(t, name, data)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s loaded as\n%+v\nwant %+v", name, got, want)
+		}
+	}
+
+	// resume.json goes through the JSON Resume importer
+	info, err := loadPersonal(t, "resume.json", `{"basics": {"name": "Ada Lovelace", "email": "ada@example.com"}, "work": [{"name": "Engine Society", "position": "Analyst", "startDate": "2024-01"}]}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Name != "Ada Lovelace" || len(info.Experience) != 1 || info.Experience[0].Company != "Engine Society" {
+		t.Errorf("resume.json loaded as %+v", info)
+	}
+
+	if _, err := loadPersonal(t, "personal.ini", "name = Ada"); err == nil || !strings.Contains(err.Error(), "unsupported format") {
+		t.Errorf("unknown extension got %v", err)
+	}
+}
+
+func TestLoadPersonalInfoUnknownFields(t *testing.T) {
+	// A misspelt key is an error rather than silently dropped data
+	_, err := loadPersonal(t, "personal.yaml", "name: Ada\nexperience:\n  - company: Acme\n    postion: Analyst\n")
+	wantErrors(t, err, "personal.yaml: yaml: unmarshal errors:", "line 4: field postion not found")
+
+	_, err = loadPersonal(t, "personal.toml", "name = \"Ada\"\n\n[[experience]]\ncompany = \"Acme\"\npostion = \"Analyst\"\n")
+	wantErrors(t, err, `personal.toml: unknown field "experience.postion"`)
+}
+
+func TestLoadPersonalInfoErrors(t *testing.T) {
+	for _, name := range []string{"personal.yaml", "personal.toml"} {
+		data := `
+email: nowhere
+skills:
+  - category: ""
+experience:
+  - company: Acme
+    position: Analyst
+    start_date: 2024-06-01
+    end_date: 2024-01-01
+  - start_date: June 2024
+education:
+  - institution: Home
+    degree: Tutoring
+    start_date: 2024-09
+    end_date: 2020-06
+`
+		if name == "personal.toml" {
+			data = `
+email = "nowhere"
+
+[[skills]]
+category = ""
+
+[[experience]]
+company = "Acme"
+position = "Analyst"
+start_date = "2024-06-01"
+end_date = "2024-01-01"
+
+[[experience]]
+start_date = "June 2024"
+
+[[education]]
+institution = "Home"
+degree = "Tutoring"
+start_date = "2024-09"
+end_date = "2020-06"
+`
+		}
+		_, err := loadPersonal(t, name, data)
+		wantErrors(t, err,
+			name+": name: is required",
+			name+`: email: "nowhere" is not an email address`,
+			name+": skills[0].category: is required",
+			name+": skills[0].items: must list at least one skill",
+			name+": experience[0].end_date: 2024-01-01 is before start_date 2024-06-01",
+			name+": experience[1].company: is required",
+			name+": experience[1].position: is required",
+			name+`: experience[1].start_date: invalid date "June 2024", expected YYYY-MM-DD or YYYY-MM`,
+			name+": education[0].end_date: 2020-06 is before start_date 2024-09",
+		)
+	}
+}
@@ -0,0 +1,215 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ResumeVariant tailors the resume for a kind of role by choosing which
+// skills, experience bullets and projects to include, and in what order
+type ResumeVariant struct {
+	Name    string
+	Title   string // Replaces PersonalInfo.Title when set
+	Summary string // Replaces the resume summary when set
+
+	// Skills lists the skill categories to include, in order; all when empty
+	Skills []string
+	// Experience lists the positions to include, in order; all when empty
+	Experience []VariantExperience
+	// ProjectTypes lists the project types to include, in order; the
+	// featured resume projects when empty
+	ProjectTypes []string
+	MaxProjects  int // Caps the number of projects; 0 means no limit
+}
+
+// VariantExperience selects one position and some of its bullets
+type VariantExperience struct {
+	Company string
+	Bullets []int // Indexes into Experience.Description, in order; all when empty
+}
+
+type variantsFile struct {
+	Variants []variantFile `yaml:"variants"`
+}
+
+type variantFile struct {
+	Name         string                  `yaml:"name"`
+	Title        string                  `yaml:"title"`
+	Summary      string                  `yaml:"summary"`
+	Skills       []string                `yaml:"skills"`
+	Experience   []variantExperienceFile `yaml:"experience"`
+	ProjectTypes []string                `yaml:"project_types"`
+	MaxProjects  int                     `yaml:"max_projects"`
+}
+
+type variantExperienceFile struct {
+	Company string `yaml:"company"`
+	Bullets []int  `yaml:"bullets"`
+}
+
+// variantNamePattern keeps variant names usable as URL path segments
+var variantNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+// LoadResumeVariants reads resume variants from a YAML file and checks that
+// the skill categories, companies and bullets they name exist in info, and
+// that their project types are among projectTypes. All validation problems
+// are returned together, prefixed with the file name.
+func LoadResumeVariants(path string, info PersonalInfo, projectTypes []string) ([]ResumeVariant, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file variantsFile
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&file); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+
+	variants, errs := file.toVariants(info, projectTypes)
+	if len(errs) > 0 {
+		for i, e := range errs {
+			errs[i] = fmt.Errorf("%s: %w", path, e)
+		}
+		return nil, errors.Join(errs...)
+	}
+	return variants, nil
+}
+
+// toVariants converts and validates the decoded file
+func (f variantsFile) toVariants(info PersonalInfo, projectTypes []string) ([]ResumeVariant, []error) {
+	var errs []error
+	invalid := func(field, format string, args ...interface{}) {
+		errs = append(errs, &ValidationError{Field: field, Msg: fmt.Sprintf(format, args...)})
+	}
+
+	categories := make(map[string]bool)
+	for _, s := range info.Skills {
+		categories[s.Category] = true
+	}
+	positions := make(map[string]Experience)
+	for _, e := range info.Experience {
+		positions[e.Company] = e
+	}
+	types := make(map[string]bool)
+	for _, t := range projectTypes {
+		types[t] = true
+	}
+
+	var variants []ResumeVariant
+	seen := make(map[string]bool)
+	for i, v := range f.Variants {
+		field := fmt.Sprintf("variants[%d]", i)
+
+		name := strings.TrimSpace(v.Name)
+		switch {
+		case name == "":
+			invalid(field+".name", "is required")
+		case !variantNamePattern.MatchString(name):
+			invalid(field+".name", "%q must be lowercase letters, digits and dashes", name)
+		case name == "default":
+			invalid(field+".name", "%q is reserved", name)
+		case seen[name]:
+			invalid(field+".name", "duplicate variant %q", name)
+		}
+		seen[name] = true
+
+		for j, category := range v.Skills {
+			if !categories[category] {
+				invalid(fmt.Sprintf("%s.skills[%d]", field, j), "unknown skill category %q", category)
+			}
+		}
+
+		for j, projectType := range v.ProjectTypes {
+			if !types[projectType] {
+				invalid(fmt.Sprintf("%s.project_types[%d]", field, j), "unknown project type %q, must be one of %s", projectType, strings.Join(projectTypes, ", "))
+			}
+		}
+
+		variant := ResumeVariant{
+			Name:         name,
+			Title:        strings.TrimSpace(v.Title),
+			Summary:      strings.TrimSpace(v.Summary),
+			Skills:       v.Skills,
+			ProjectTypes: v.ProjectTypes,
+			MaxProjects:  v.MaxProjects,
+		}
+
+		for j, e := range v.Experience {
+			expField := fmt.Sprintf("%s.experience[%d]", field, j)
+			position, ok := positions[e.Company]
+			if !ok {
+				invalid(expField+".company", "unknown company %q", e.Company)
+			}
+			for k, bullet := range e.Bullets {
+				if ok && (bullet < 0 || bullet >= len(position.Description)) {
+					invalid(fmt.Sprintf("%s.bullets[%d]", expField, k), "%d is out of range, %s has %d bullets", bullet, e.Company, len(position.Description))
+				}
+			}
+			variant.Experience = append(variant.Experience, VariantExperience{Company: e.Company, Bullets: e.Bullets})
+		}
+
+		if v.MaxProjects < 0 {
+			invalid(field+".max_projects", "must not be negative")
+		}
+
+		variants = append(variants, variant)
+	}
+
+	return variants, errs
+}
+
+// Apply returns a copy of info with the variant's title, skills and
+// experience selections applied. Projects and the summary are left to the
+// caller, which owns them.
+func (v ResumeVariant) Apply(info PersonalInfo) PersonalInfo {
+	if v.Title != "" {
+		info.Title = v.Title
+	}
+
+	if len(v.Skills) > 0 {
+		byCategory := make(map[string]Skill)
+		for _, s := range info.Skills {
+			byCategory[s.Category] = s
+		}
+		info.Skills = nil
+		for _, category := range v.Skills {
+			if s, ok := byCategory[category]; ok {
+				info.Skills = append(info.Skills, s)
+			}
+		}
+	}
+
+	if len(v.Experience) > 0 {
+		byCompany := make(map[string]Experience)
+		for _, e := range info.Experience {
+			byCompany[e.Company] = e
+		}
+		info.Experience = nil
+		for _, selected := range v.Experience {
+			e, ok := byCompany[selected.Company]
+			if !ok {
+				continue
+			}
+			if len(selected.Bullets) > 0 {
+				var bullets []string
+				for _, i := range selected.Bullets {
+					if i >= 0 && i < len(e.Description) {
+						bullets = append(bullets, e.Description[i])
+					}
+				}
+				e.Description = bullets
+			}
+			info.Experience = append(info.Experience, e)
+		}
+	}
+
+	return info
+}
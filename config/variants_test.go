@@ -0,0 +1,76 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var testProjectTypes = []string{"web", "security", "research"}
+
+func loadVariants(t *testing.T, yaml string) ([]ResumeVariant, error) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "resume-variants.yaml")
+	if err := os.WriteFile(path, []byte(yaml), 0644); err != nil {
+		t.Fatal(err)
+	}
+	info := PersonalInfo{
+		Skills:     []Skill{{Category: "Languages", Items: []string{"Go"}}},
+		Experience: []Experience{{Company: "Acme", Description: []string{"one", "two"}}},
+	}
+	return LoadResumeVariants(path, info, testProjectTypes)
+}
+
+func TestLoadResumeVariants(t *testing.T) {
+	variants, err := loadVariants(t, `
+variants:
+  - name: security
+    skills: [Languages]
+    experience:
+      - company: Acme
+        bullets: [1]
+    project_types: [security, research]
+    max_projects: 2
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(variants) != 1 || strings.Join(variants[0].ProjectTypes, ",") != "security,research" || variants[0].MaxProjects != 2 {
+		t.Errorf("got %+v", variants)
+	}
+}
+
+func TestLoadResumeVariantsErrors(t *testing.T) {
+	_, err := loadVariants(t, `
+variants:
+  - name: Security
+    skills: [Cooking]
+    experience:
+      - company: Initech
+      - company: Acme
+        bullets: [2]
+    project_types: [security, secruity]
+    max_projects: -1
+  - name: default
+`)
+	if err == nil {
+		t.Fatal("invalid variants loaded")
+	}
+	for _, want := range []string{
+		`resume-variants.yaml: variants[0].name: "Security" must be lowercase`,
+		`resume-variants.yaml: variants[0].skills[0]: unknown skill category "Cooking"`,
+		`resume-variants.yaml: variants[0].experience[0].company: unknown company "Initech"`,
+		`resume-variants.yaml: variants[0].experience[1].bullets[0]: 2 is out of range`,
+		`resume-variants.yaml: variants[0].project_types[1]: unknown project type "secruity", must be one of web, security, research`,
+		`resume-variants.yaml: variants[0].max_projects: must not be negative`,
+		`resume-variants.yaml: variants[1].name: "default" is reserved`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error does not mention %q:\n%v", want, err)
+		}
+	}
+	if strings.Contains(err.Error(), "project_types[0]") {
+		t.Errorf("known project type reported:\n%v", err)
+	}
+}
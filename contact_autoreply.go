@@ -0,0 +1,212 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"gopkg.in/mail.v2"
+)
+
+// Auto-reply states of a delivered submission
+const (
+	autoReplySent      = "sent"
+	autoReplyThrottled = "throttled" // The address, or everyone together, had enough within autoReplyInterval
+	autoReplySkipped   = "skipped"   // Released after a spam filter rejected it, so its address is not trusted
+	autoReplyFailed    = "failed"
+)
+
+// autoReplyInterval is the least time between two auto-replies to the same
+// mailbox, and the window defaultAutoReplyLimit counts over, so the form
+// cannot be used to mail someone, or many people, repeatedly
+const (
+	autoReplyInterval     = 24 * time.Hour
+	defaultAutoReplyLimit = 20
+)
+
+const defaultAutoReplySubject = `Thanks for your message`
+
+// The copy of the message can only reach a mailbox the throttle allows, at
+// most once a day, and only from a submission no spam filter rejected
+const defaultAutoReplyBody = `Hi {{.Name}},
+
+Thanks for getting in touch. This is an automatic reply to let you know your
+message arrived; I'll get back to you soon.
+
+For your records, this is what you sent:
+
+Subject: {{.Subject}}
+
+{{quote .Message}}
+`
+
+// autoReplyData is what the auto-reply templates are executed with
+type autoReplyData struct {
+	Name       string
+	Email      string
+	Subject    string
+	Message    string
+	ReceivedAt time.Time
+}
+
+// autoReplier acknowledges contact submissions to their senders
+type autoReplier struct {
+	subject *texttemplate.Template
+	body    *texttemplate.Template
+	replyTo string // Where replies to the acknowledgement go
+	limit   int    // Auto-replies to anyone per autoReplyInterval
+}
+
+// autoReplierFromEnv returns the auto-replier configured by
+// CONTACT_AUTOREPLY, or nil if it is off. The body template is read from
+// CONTACT_AUTOREPLY_TEMPLATE and the subject from CONTACT_AUTOREPLY_SUBJECT,
+// both Go text/templates; replies go to CONTACT_AUTOREPLY_REPLY_TO, or to
+// TO_EMAIL. At most CONTACT_AUTOREPLY_DAILY_LIMIT are sent a day in all.
+func autoReplierFromEnv(email EmailConfig) (*autoReplier, error) {
+	switch os.Getenv("CONTACT_AUTOREPLY") {
+	case "1", "true", "TRUE", "yes":
+	default:
+		return nil, nil
+	}
+
+	body := defaultAutoReplyBody
+	if path := getEnv("CONTACT_AUTOREPLY_TEMPLATE", ""); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read auto-reply template: %v", err)
+		}
+		body = string(data)
+	}
+	reply, err := newAutoReplier(getEnv("CONTACT_AUTOREPLY_SUBJECT", defaultAutoReplySubject), body,
+		getEnv("CONTACT_AUTOREPLY_REPLY_TO", email.ToEmail))
+	if err != nil {
+		return nil, err
+	}
+	reply.limit = getEnvInt("CONTACT_AUTOREPLY_DAILY_LIMIT", defaultAutoReplyLimit)
+	return reply, nil
+}
+
+func newAutoReplier(subject, body, replyTo string) (*autoReplier, error) {
+	funcs := texttemplate.FuncMap{"quote": quoteText}
+	subjectTmpl, err := texttemplate.New("subject").Funcs(funcs).Parse(subject)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse auto-reply subject: %v", err)
+	}
+	bodyTmpl, err := texttemplate.New("body").Funcs(funcs).Parse(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse auto-reply template: %v", err)
+	}
+	return &autoReplier{subject: subjectTmpl, body: bodyTmpl, replyTo: replyTo, limit: defaultAutoReplyLimit}, nil
+}
+
+// message builds the acknowledgement of sub, marked as an automatic reply
+// (RFC 3834) so that the sender's own auto-responders stay quiet
+func (a *autoReplier) message(c EmailConfig, sub ContactSubmission) (*mail.Message, error) {
+	data := autoReplyData{
+		Name:       sub.Form.Name,
+		Email:      sub.Form.Email,
+		Subject:    sub.Form.Subject,
+		Message:    sub.Form.Message,
+		ReceivedAt: sub.ReceivedAt,
+	}
+	var subject, body bytes.Buffer
+	if err := a.subject.Execute(&subject, data); err != nil {
+		return nil, fmt.Errorf("failed to render auto-reply subject: %v", err)
+	}
+	if err := a.body.Execute(&body, data); err != nil {
+		return nil, fmt.Errorf("failed to render auto-reply: %v", err)
+	}
+
+	m := mail.NewMessage()
+	m.SetHeader("Message-ID", fmt.Sprintf("<autoreply-%s@%s>", sub.ID, messageDomain(c)))
+	m.SetHeader("From", c.FromEmail)
+	m.SetAddressHeader("To", sub.Form.Email, sub.Form.Name)
+	if a.replyTo != "" {
+		m.SetHeader("Reply-To", a.replyTo)
+	}
+	// One line, whatever the template produced
+	m.SetHeader("Subject", strings.Join(strings.Fields(subject.String()), " "))
+	m.SetHeader("Auto-Submitted", "auto-replied")
+	m.SetHeader("X-Auto-Response-Suppress", "All")
+	m.SetBody("text/plain", body.String())
+	return m, nil
+}
+
+// autoReply acknowledges a delivered submission unless it was released
+// after being rejected as spam or the throttle says otherwise, and records
+// the outcome. It is only called from the outbox worker, so two
+// submissions cannot both pass the throttle.
+func (o *Outbox) autoReply(sub ContactSubmission) {
+	now := time.Now().UTC()
+	state := autoReplySent
+	switch {
+	case sub.Rejection != "":
+		state = autoReplySkipped
+	case o.autoReplyThrottled(sub.Form.Email, now):
+		state = autoReplyThrottled
+	default:
+		m, err := o.autoReplier.message(o.email, sub)
+		if err == nil {
+			err = o.email.send(m)
+		}
+		if err != nil {
+			state = autoReplyFailed
+			log.Printf("⚠️  Auto-reply for contact submission %s failed: %v", sub.ID, err)
+		}
+	}
+
+	_, err := o.store.Update(sub.ID, func(s *ContactSubmission) {
+		s.AutoReply = state
+		if state == autoReplySent {
+			s.AutoReplyAt = &now
+		}
+	})
+	if err != nil {
+		log.Printf("Failed to record auto-reply for contact submission %s: %v", sub.ID, err)
+	}
+}
+
+// autoReplyThrottled reports whether the mailbox email delivers to, or
+// everyone together, has had as many auto-replies in the last
+// autoReplyInterval as allowed
+func (o *Outbox) autoReplyThrottled(email string, now time.Time) bool {
+	recent := o.store.AutoRepliedSince(now.Add(-autoReplyInterval))
+	if len(recent) >= o.autoReplier.limit {
+		return true
+	}
+	key := mailboxKey(email)
+	for _, sub := range recent {
+		if mailboxKey(sub.Form.Email) == key {
+			return true
+		}
+	}
+	return false
+}
+
+// mailboxKey reduces an address to the mailbox it most likely delivers to,
+// ignoring case, a "+tag" and dots in the local part, as the big providers
+// do. Distinct mailboxes may share a key, which only makes the throttle
+// stricter.
+func mailboxKey(email string) string {
+	email = strings.ToLower(strings.TrimSpace(email))
+	at := strings.LastIndex(email, "@")
+	if at == -1 {
+		return email
+	}
+	local, domain := email[:at], email[at+1:]
+	local, _, _ = strings.Cut(local, "+")
+	local = strings.ReplaceAll(local, ".", "")
+	if domain == "googlemail.com" {
+		domain = "gmail.com"
+	}
+	return local + "@" + domain
+}
+
+// quoteText prefixes every line of s with "> "
+func quoteText(s string) string {
+	return "> " + strings.ReplaceAll(s, "\n", "\n> ")
+}
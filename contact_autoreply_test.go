@@ -0,0 +1,127 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOutboxAutoReplies(t *testing.T) {
+	smtp := newFakeSMTP(t)
+	store, _ := openTestStore(t)
+	outbox := NewOutbox(store, smtp.config(), fastRetries)
+	reply, err := newAutoReplier(defaultAutoReplySubject, defaultAutoReplyBody, "owner@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	outbox.autoReplier = reply
+
+	first := addTestSubmission(t, store)
+	got := drain(t, outbox, first.ID)
+	if got, _ := store.Get(first.ID); got.AutoReply != autoReplySent || got.AutoReplyAt == nil {
+		t.Errorf("first submission has auto-reply %q at %v, want sent", got.AutoReply, got.AutoReplyAt)
+	}
+	messages := smtp.received()
+	if got.Status != deliverySent || len(messages) != 2 {
+		t.Fatalf("got status %q and %d messages, want the notification and the auto-reply", got.Status, len(messages))
+	}
+
+	ack := strings.ReplaceAll(messages[1], "=\r\n", "") // Undo quoted-printable line wrapping
+	for _, header := range []string{
+		`To: "Ada" <ada@example.com>`,
+		"Reply-To: owner@example.com",
+		"Subject: Thanks for your message",
+		"Auto-Submitted: auto-replied",
+	} {
+		if !regexp.MustCompile(`(?mi)^` + regexp.QuoteMeta(header) + `\r$`).MatchString(ack) {
+			t.Errorf("auto-reply missing header %q:\n%s", header, ack)
+		}
+	}
+	if !strings.Contains(ack, "Hi Ada,") {
+		t.Errorf("auto-reply does not greet the sender:\n%s", ack)
+	}
+	if !strings.Contains(ack, "> Are you available?") {
+		t.Errorf("auto-reply does not quote the message:\n%s", ack)
+	}
+
+	// Variants of the same mailbox are not acknowledged again the same day
+	for _, email := range []string{"ADA@example.com", "a.d.a+again@example.com"} {
+		sub, _, _ := store.Add(ContactSubmission{Form: ContactForm{
+			Name: "Ada", Email: email, Subject: "Again", Message: "Hello again",
+		}})
+		drain(t, outbox, sub.ID)
+		if got, _ := store.Get(sub.ID); got.AutoReply != autoReplyThrottled || got.AutoReplyAt != nil {
+			t.Errorf("submission from %s has auto-reply %q, want throttled", email, got.AutoReply)
+		}
+	}
+
+	// A submission released after the spam filters rejected it is not
+	// acknowledged
+	released, _, _ := store.Add(ContactSubmission{
+		Form:      ContactForm{Name: "Bob", Email: "bob@example.com", Subject: "Hi", Message: "Hello"},
+		Rejection: "honeypot: hidden field filled in",
+	})
+	drain(t, outbox, released.ID)
+	if got, _ := store.Get(released.ID); got.AutoReply != autoReplySkipped {
+		t.Errorf("released submission has auto-reply %q, want skipped", got.AutoReply)
+	}
+
+	// Nor is anyone once the daily limit is reached
+	reply.limit = 1
+	other, _, _ := store.Add(ContactSubmission{Form: ContactForm{
+		Name: "Carol", Email: "carol@example.com", Subject: "Hi", Message: "Hello",
+	}})
+	drain(t, outbox, other.ID)
+	if got, _ := store.Get(other.ID); got.AutoReply != autoReplyThrottled {
+		t.Errorf("submission past the daily limit has auto-reply %q, want throttled", got.AutoReply)
+	}
+
+	if n := len(smtp.received()); n != 6 {
+		t.Errorf("server received %d messages, want 6", n)
+	}
+}
+
+func TestMailboxKey(t *testing.T) {
+	for _, email := range []string{"Ada.Lovelace+forms@Example.com", "adalovelace@example.com", " ADA.LOVE.LACE@EXAMPLE.COM "} {
+		if got := mailboxKey(email); got != "adalovelace@example.com" {
+			t.Errorf("mailboxKey(%q) = %q", email, got)
+		}
+	}
+	if got := mailboxKey("ada@googlemail.com"); got != "ada@gmail.com" {
+		t.Errorf("googlemail.com address became %q", got)
+	}
+	if mailboxKey("ada@example.com") == mailboxKey("ada@example.org") {
+		t.Error("addresses at different domains share a key")
+	}
+}
+
+func TestAutoReplyTemplate(t *testing.T) {
+	reply, err := newAutoReplier("Thanks, {{.Name}}\r\nBcc: x@example.com", "{{.Name}} wrote on {{.ReceivedAt.Year}}:\n{{quote .Message}}", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := reply.message(EmailConfig{FromEmail: "me@example.com"}, ContactSubmission{
+		ID:         "abc",
+		ReceivedAt: time.Now(),
+		Form:       ContactForm{Name: "Ada", Email: "ada@example.com", Message: "one\ntwo"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := m.GetHeader("Subject"); len(got) != 1 || got[0] != "Thanks, Ada Bcc: x@example.com" {
+		t.Errorf("subject = %q, want one line", got)
+	}
+	if got := m.GetHeader("Reply-To"); len(got) != 0 {
+		t.Errorf("Reply-To = %q, want none", got)
+	}
+	var raw strings.Builder
+	m.WriteTo(&raw)
+	if body := raw.String(); !strings.Contains(body, "> one\r\n> two") {
+		t.Errorf("message not quoted:\n%s", body)
+	}
+
+	if _, err := newAutoReplier("{{.Name", "", ""); err == nil {
+		t.Error("broken subject template accepted")
+	}
+}
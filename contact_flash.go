@@ -0,0 +1,134 @@
+package main
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Flash messages outlive the redirect that shows them by this much, and no
+// more than maxFlashes are kept at once
+const (
+	flashTTL        = 10 * time.Minute
+	maxFlashes      = 1000
+	flashCookieName = "contact_flash"
+)
+
+// contactFlash is the outcome of a contact form post made without
+// JavaScript, shown once on the contact page it redirects to
+type contactFlash struct {
+	Success bool
+	Message string
+	Errors  FieldErrors
+	Form    ContactForm // What was posted, to fill the form in again after an error
+}
+
+// flashStore keeps flash messages in memory, keyed by the random ID in the
+// visitor's cookie, so a message and its form can be carried across the
+// redirect without fitting in a cookie
+type flashStore struct {
+	mu      sync.Mutex
+	entries map[string]flashEntry
+}
+
+type flashEntry struct {
+	flash   contactFlash
+	expires time.Time
+}
+
+func newFlashStore() *flashStore {
+	return &flashStore{entries: make(map[string]flashEntry)}
+}
+
+// Put stores f and returns its ID
+func (s *flashStore) Put(f contactFlash, now time.Time) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, e := range s.entries {
+		if now.After(e.expires) {
+			delete(s.entries, id)
+		}
+	}
+	// Under a flood, forget arbitrary flashes rather than grow
+	for id := range s.entries {
+		if len(s.entries) < maxFlashes {
+			break
+		}
+		delete(s.entries, id)
+	}
+
+	id := newSubmissionID()
+	s.entries[id] = flashEntry{flash: f, expires: now.Add(flashTTL)}
+	return id
+}
+
+// Take removes and returns the flash with the given ID
+func (s *flashStore) Take(id string, now time.Time) (contactFlash, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[id]
+	if !ok {
+		return contactFlash{}, false
+	}
+	delete(s.entries, id)
+	if now.After(e.expires) {
+		return contactFlash{}, false
+	}
+	return e.flash, true
+}
+
+// redirectWithFlash ends a contact form post made without JavaScript: the
+// result is kept as a flash and the browser is sent back to the contact
+// page, where a reload will not post the form again
+func (s *Server) redirectWithFlash(w http.ResponseWriter, r *http.Request, result contactResult, form ContactForm) {
+	flash := contactFlash{
+		Success: result.Status == http.StatusOK,
+		Message: result.Message,
+		Errors:  result.Errors,
+	}
+	if !flash.Success {
+		form.FormToken, form.Challenge, form.Solution = "", "", ""
+		flash.Form = form
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     flashCookieName,
+		Value:    s.flashes.Put(flash, time.Now()),
+		Path:     "/contact",
+		MaxAge:   int(flashTTL / time.Second),
+		HttpOnly: true,
+		Secure:   isHTTPS(r),
+		SameSite: http.SameSiteLaxMode,
+	})
+	http.Redirect(w, r, "/contact#contact-form", http.StatusSeeOther)
+}
+
+// takeFlash returns the flash message the visitor was redirected with, if
+// any, and clears its cookie
+func (s *Server) takeFlash(w http.ResponseWriter, r *http.Request) *contactFlash {
+	cookie, err := r.Cookie(flashCookieName)
+	if err != nil {
+		return nil
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     flashCookieName,
+		Path:     "/contact",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   isHTTPS(r),
+		SameSite: http.SameSiteLaxMode,
+	})
+	flash, ok := s.flashes.Take(cookie.Value, time.Now())
+	if !ok {
+		return nil
+	}
+	return &flash
+}
+
+// isHTTPS reports whether r reached the site over HTTPS, directly or, with
+// TRUST_PROXY set, through a proxy that terminated TLS
+func isHTTPS(r *http.Request) bool {
+	return r.TLS != nil || (trustProxy() && r.Header.Get("X-Forwarded-Proto") == "https")
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestContactFormWithoutJavaScript(t *testing.T) {
+	store, _ := openTestStore(t)
+	signer := &formTokenSigner{key: []byte("secret")}
+	pow := newPowIssuer(signer, defaultPowDifficulty)
+	s := &Server{
+		contacts:   store,
+		outbox:     NewOutbox(store, EmailConfig{}, OutboxOptions{}),
+		formTokens: signer,
+		pow:        pow,
+		spam:       spamChainFromEnv(signer, pow),
+		rejections: newRateLimiter(maxStoredRejections, rateWindow),
+		flashes:    newFlashStore(),
+	}
+	// What the contact page renders: a form token and a question, which
+	// the visitor answers
+	token := signer.Issue(time.Now().Add(-time.Minute))
+	challenge, question := pow.IssueQuestion("192.0.2.1", time.Now())
+
+	post := func(req *http.Request) contactFlash {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		s.handleContactForm(rec, req)
+		if rec.Code != http.StatusSeeOther || !strings.HasPrefix(rec.Header().Get("Location"), "/contact") {
+			t.Fatalf("got %d to %q, want a redirect to the contact page", rec.Code, rec.Header().Get("Location"))
+		}
+		cookies := rec.Result().Cookies()
+		if len(cookies) != 1 || cookies[0].Name != flashCookieName || !cookies[0].HttpOnly {
+			t.Fatalf("cookies = %v", cookies)
+		}
+		flash, ok := s.flashes.Take(cookies[0].Value, time.Now())
+		if !ok {
+			t.Fatal("no flash stored for the cookie")
+		}
+		if _, ok := s.flashes.Take(cookies[0].Value, time.Now()); ok {
+			t.Error("flash shown twice")
+		}
+		return flash
+	}
+
+	// A bad address comes back with the form filled in
+	values := url.Values{"name": {"Ada"}, "email": {"ada"}, "message": {"Hello"}, "idempotency_key": {"k1"}}
+	req := httptest.NewRequest("POST", "/contact", strings.NewReader(values.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	flash := post(req)
+	if flash.Success || flash.Errors["email"] == "" || flash.Form.Name != "Ada" || flash.Form.Message != "Hello" {
+		t.Errorf("got flash %+v, want an email error and the form", flash)
+	}
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for _, field := range [][2]string{
+		{"name", "Ada"}, {"email", "ada@example.com"}, {"message", "Hello"}, {"idempotency_key", "k2"},
+		{"form_token", token}, {"challenge", challenge}, {"solution", answerQuestion(question)},
+	} {
+		mw.WriteField(field[0], field[1])
+	}
+	mw.Close()
+	req = httptest.NewRequest("POST", "/contact", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	flash = post(req)
+	if !flash.Success || flash.Form != (ContactForm{}) {
+		t.Errorf("got flash %+v, want a success without the form", flash)
+	}
+
+	// Without the answer, the visitor is told it was not sent
+	values = url.Values{"name": {"Ada"}, "email": {"ada@example.com"}, "message": {"Hello again"}, "form_token": {token}}
+	req = httptest.NewRequest("POST", "/contact", strings.NewReader(values.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	flash = post(req)
+	if flash.Success || flash.Form.Message != "Hello again" {
+		t.Errorf("got flash %+v, want a failure with the form", flash)
+	}
+
+	subs := store.List(deliveryPending)
+	if len(subs) != 1 || subs[0].IdempotencyKey != "k2" || subs[0].Form.Email != "ada@example.com" {
+		t.Errorf("queued %+v, want the multipart submission", subs)
+	}
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/textproto"
+	"strings"
+	"time"
+
+	"gopkg.in/mail.v2"
+)
+
+// Outbox limits, overridable with OUTBOX_MAX_ATTEMPTS and
+// OUTBOX_BACKOFF_SECONDS
+const (
+	defaultOutboxMaxAttempts = 8
+	defaultOutboxBackoff     = 30 * time.Second
+	defaultOutboxMaxBackoff  = time.Hour
+	outboxPollInterval       = 15 * time.Second
+)
+
+// OutboxOptions controls how often a contact email is retried
+type OutboxOptions struct {
+	MaxAttempts int           // Attempts before a message is dead-lettered
+	Backoff     time.Duration // Delay after the first failure, doubled after each one after it
+	MaxBackoff  time.Duration // Longest delay between two attempts
+}
+
+// outboxOptionsFromEnv reads OUTBOX_MAX_ATTEMPTS and OUTBOX_BACKOFF_SECONDS
+func outboxOptionsFromEnv() OutboxOptions {
+	return OutboxOptions{
+		MaxAttempts: getEnvInt("OUTBOX_MAX_ATTEMPTS", defaultOutboxMaxAttempts),
+		Backoff:     time.Duration(getEnvInt("OUTBOX_BACKOFF_SECONDS", int(defaultOutboxBackoff/time.Second))) * time.Second,
+	}
+}
+
+func (o OutboxOptions) withDefaults() OutboxOptions {
+	if o.MaxAttempts <= 0 {
+		o.MaxAttempts = defaultOutboxMaxAttempts
+	}
+	if o.Backoff <= 0 {
+		o.Backoff = defaultOutboxBackoff
+	}
+	if o.MaxBackoff <= 0 {
+		o.MaxBackoff = defaultOutboxMaxBackoff
+	}
+	if o.MaxBackoff < o.Backoff {
+		o.MaxBackoff = o.Backoff
+	}
+	return o
+}
+
+// backoff returns the delay before the attempt after the given number of
+// failed ones
+func (o OutboxOptions) backoff(attempts int) time.Duration {
+	delay := o.Backoff
+	for i := 1; i < attempts && delay < o.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > o.MaxBackoff {
+		delay = o.MaxBackoff
+	}
+	return delay
+}
+
+// Outbox emails the pending submissions in a ContactStore from a single
+// background worker. A failed send is retried with exponential backoff
+// until it succeeds, the SMTP server rejects it permanently or MaxAttempts
+// is reached; the last two leave the submission dead-lettered in the
+// store. Delivery is at least once: every attempt carries the same
+// Message-ID, so a repeat after a lost reply can be recognised.
+type Outbox struct {
+	store       *ContactStore
+	email       EmailConfig
+	opts        OutboxOptions
+	trigger     chan struct{}
+	autoReplier *autoReplier // Acknowledges delivered submissions, if set
+}
+
+// NewOutbox returns an outbox that sends store's submissions with email
+func NewOutbox(store *ContactStore, email EmailConfig, opts OutboxOptions) *Outbox {
+	return &Outbox{
+		store:   store,
+		email:   email,
+		opts:    opts.withDefaults(),
+		trigger: make(chan struct{}, 1),
+	}
+}
+
+// Run delivers due submissions until the process exits. Between rounds it
+// sleeps until the next retry is due, but no less than a second and no
+// more than outboxPollInterval.
+func (o *Outbox) Run() {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		o.deliverDue()
+
+		wait := outboxPollInterval
+		if next, ok := o.store.NextAttempt(); ok && time.Until(next) < wait {
+			wait = max(time.Until(next), time.Second)
+		}
+		timer.Reset(wait)
+		select {
+		case <-o.trigger:
+		case <-timer.C:
+		}
+	}
+}
+
+// Trigger asks the worker to look for due submissions now rather than at
+// the next interval. It never blocks.
+func (o *Outbox) Trigger() {
+	select {
+	case o.trigger <- struct{}{}:
+	default:
+	}
+}
+
+// deliverDue makes one attempt at every submission that is due
+func (o *Outbox) deliverDue() {
+	for _, sub := range o.store.Due(time.Now()) {
+		o.deliver(sub)
+	}
+}
+
+// deliver makes one attempt at sending sub and records the outcome
+func (o *Outbox) deliver(sub ContactSubmission) {
+	sendErr := o.email.send(contactMessage(o.email, sub))
+	attempts := sub.Attempts + 1
+
+	updated, err := o.store.Update(sub.ID, func(s *ContactSubmission) {
+		s.Attempts = attempts
+		s.NextAttemptAt = time.Time{}
+		s.Error = ""
+		switch {
+		case sendErr == nil:
+			s.Status = deliverySent
+		case permanentSMTPError(sendErr) || attempts >= o.opts.MaxAttempts:
+			s.Status = deliveryDead
+			s.Error = sendErr.Error()
+		default:
+			s.NextAttemptAt = time.Now().UTC().Add(o.opts.backoff(attempts))
+			s.Error = sendErr.Error()
+		}
+	})
+	if err != nil {
+		// The next attempt will send it again; the Message-ID stays the same
+		log.Printf("Failed to record delivery of contact submission %s: %v", sub.ID, err)
+		return
+	}
+
+	switch updated.Status {
+	case deliverySent:
+		log.Printf("📨 Contact submission %s emailed (attempt %d)", sub.ID, attempts)
+		if o.autoReplier != nil {
+			o.autoReply(updated)
+		}
+	case deliveryDead:
+		log.Printf("❌ Contact submission %s dead-lettered after %d attempt(s): %v", sub.ID, attempts, sendErr)
+	default:
+		log.Printf("⚠️  Contact submission %s attempt %d failed, retrying at %s: %v",
+			sub.ID, attempts, updated.NextAttemptAt.Format(time.RFC3339), sendErr)
+	}
+}
+
+// configured reports whether enough of the SMTP settings are present to
+// send mail
+func (c EmailConfig) configured() bool {
+	return c.Username != "" && c.Password != "" && c.ToEmail != ""
+}
+
+// send delivers m over one SMTP connection
+func (c EmailConfig) send(m *mail.Message) error {
+	if !c.configured() {
+		return fmt.Errorf("email configuration incomplete")
+	}
+	return mail.NewDialer(c.SMTPHost, c.SMTPPort, c.Username, c.Password).DialAndSend(m)
+}
+
+// contactMessage builds the notification email for a submission. Its
+// Message-ID is derived from the submission ID so that retries of the same
+// submission are the same message.
+func contactMessage(c EmailConfig, sub ContactSubmission) *mail.Message {
+	form := sub.Form
+	m := mail.NewMessage()
+	m.SetHeader("Message-ID", fmt.Sprintf("<contact-%s@%s>", sub.ID, messageDomain(c)))
+	m.SetHeader("From", c.FromEmail)
+	m.SetHeader("To", c.ToEmail)
+	m.SetHeader("Subject", fmt.Sprintf("Portfolio Contact: %s", form.Subject))
+	m.SetDateHeader("Date", sub.ReceivedAt)
+
+	// Create email body
+	body := fmt.Sprintf(`
+New contact form submission from your portfolio:
+
+Name: %s
+Email: %s
+Subject: %s
+
+Message:
+%s
+
+---
+This message was sent from your portfolio contact form.
+`, form.Name, form.Email, form.Subject, form.Message)
+
+	m.SetBody("text/plain", body)
+	return m
+}
+
+// messageDomain returns the domain of the From address, for Message-IDs
+func messageDomain(c EmailConfig) string {
+	if at := strings.LastIndex(c.FromEmail, "@"); at != -1 && at < len(c.FromEmail)-1 {
+		return c.FromEmail[at+1:]
+	}
+	return "localhost"
+}
+
+// permanentSMTPError reports whether err is a 5xx reply, which resending
+// the same message will not fix. Connection failures and 4xx replies are
+// temporary.
+func permanentSMTPError(err error) bool {
+	var sendErr *mail.SendError
+	if errors.As(err, &sendErr) {
+		err = sendErr.Cause
+	}
+	var reply *textproto.Error
+	return errors.As(err, &reply) && reply.Code >= 500
+}
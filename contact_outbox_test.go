@@ -0,0 +1,292 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeSMTP is an SMTP server that answers each message's DATA with the next
+// of its scripted replies, then with 250 once they run out
+type fakeSMTP struct {
+	ln       net.Listener
+	mu       sync.Mutex
+	replies  []string
+	messages []string // Every message received, accepted or not
+}
+
+func newFakeSMTP(t *testing.T, replies ...string) *fakeSMTP {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &fakeSMTP{ln: ln, replies: replies}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *fakeSMTP) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+
+	reply("220 fake ESMTP")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		switch cmd := strings.ToUpper(strings.Fields(line + " ")[0]); cmd {
+		case "EHLO", "HELO":
+			reply("250 fake") // No STARTTLS or AUTH, so the client sends in the clear
+		case "DATA":
+			reply("354 go ahead")
+			var msg strings.Builder
+			for {
+				line, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if line == ".\r\n" {
+					break
+				}
+				msg.WriteString(line)
+			}
+			s.mu.Lock()
+			s.messages = append(s.messages, msg.String())
+			answer := "250 OK"
+			if len(s.replies) > 0 {
+				answer, s.replies = s.replies[0], s.replies[1:]
+			}
+			s.mu.Unlock()
+			reply(answer)
+		case "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+func (s *fakeSMTP) received() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.messages...)
+}
+
+func (s *fakeSMTP) config() EmailConfig {
+	addr := s.ln.Addr().(*net.TCPAddr)
+	return EmailConfig{
+		SMTPHost:  addr.IP.String(),
+		SMTPPort:  addr.Port,
+		Username:  "user",
+		Password:  "secret",
+		FromEmail: "portfolio@example.com",
+		ToEmail:   "owner@example.com",
+	}
+}
+
+func openTestStore(t *testing.T) (*ContactStore, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "contact.jsonl")
+	store, err := OpenContactStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return store, path
+}
+
+func addTestSubmission(t *testing.T, store *ContactStore) ContactSubmission {
+	t.Helper()
+	sub, _, err := store.Add(ContactSubmission{Form: ContactForm{
+		Name:    "Ada",
+		Email:   "ada@example.com",
+		Subject: "Hello",
+		Message: "Are you available?",
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sub
+}
+
+// drain runs the outbox until sub leaves the pending state
+func drain(t *testing.T, o *Outbox, id string) ContactSubmission {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		o.deliverDue()
+		if sub, _ := o.store.Get(id); sub.Status != deliveryPending {
+			return sub
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("submission %s still pending", id)
+	return ContactSubmission{}
+}
+
+var fastRetries = OutboxOptions{MaxAttempts: 3, Backoff: time.Millisecond, MaxBackoff: time.Millisecond}
+
+func TestOutboxRetriesTemporaryFailures(t *testing.T) {
+	smtp := newFakeSMTP(t, "451 4.3.0 try again later", "421 4.4.2 busy")
+	store, _ := openTestStore(t)
+	outbox := NewOutbox(store, smtp.config(), fastRetries)
+	sub := addTestSubmission(t, store)
+
+	got := drain(t, outbox, sub.ID)
+	if got.Status != deliverySent || got.Attempts != 3 || got.Error != "" {
+		t.Errorf("got status %q after %d attempts (error %q), want sent after 3", got.Status, got.Attempts, got.Error)
+	}
+
+	messages := smtp.received()
+	if len(messages) != 3 {
+		t.Fatalf("server received %d messages, want 3", len(messages))
+	}
+	// Every attempt is the same message
+	messageID := regexp.MustCompile(`(?mi)^Message-Id: (.*)\r$`)
+	for i, msg := range messages {
+		m := messageID.FindStringSubmatch(msg)
+		if m == nil || m[1] != "<contact-"+sub.ID+"@example.com>" {
+			t.Errorf("attempt %d has Message-ID %q", i+1, m)
+		}
+	}
+	if !strings.Contains(messages[2], "Are you available?") {
+		t.Error("message body missing from the email")
+	}
+
+	// Nothing is due once sent
+	outbox.deliverDue()
+	if n := len(smtp.received()); n != 3 {
+		t.Errorf("server received %d messages after delivery, want 3", n)
+	}
+}
+
+func TestOutboxDeadLettersAfterMaxAttempts(t *testing.T) {
+	smtp := newFakeSMTP(t, "451 one", "451 two", "451 three", "451 four")
+	store, path := openTestStore(t)
+	outbox := NewOutbox(store, smtp.config(), fastRetries)
+	sub := addTestSubmission(t, store)
+
+	got := drain(t, outbox, sub.ID)
+	if got.Status != deliveryDead || got.Attempts != 3 || !strings.Contains(got.Error, "three") {
+		t.Errorf("got status %q after %d attempts (error %q), want dead after 3", got.Status, got.Attempts, got.Error)
+	}
+	if n := len(smtp.received()); n != 3 {
+		t.Errorf("server received %d messages, want 3", n)
+	}
+
+	// The dead letter survives a restart and is not retried
+	reopened, err := OpenContactStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sub, _ := reopened.Get(sub.ID); sub.Status != deliveryDead || sub.Attempts != 3 {
+		t.Errorf("reopened store has status %q after %d attempts", sub.Status, sub.Attempts)
+	}
+	NewOutbox(reopened, smtp.config(), fastRetries).deliverDue()
+	if n := len(smtp.received()); n != 3 {
+		t.Errorf("server received %d messages after restart, want 3", n)
+	}
+}
+
+func TestOutboxDeadLettersPermanentFailures(t *testing.T) {
+	smtp := newFakeSMTP(t, "554 5.7.1 rejected")
+	store, _ := openTestStore(t)
+	outbox := NewOutbox(store, smtp.config(), fastRetries)
+	sub := addTestSubmission(t, store)
+
+	got := drain(t, outbox, sub.ID)
+	if got.Status != deliveryDead || got.Attempts != 1 {
+		t.Errorf("got status %q after %d attempts, want dead after 1", got.Status, got.Attempts)
+	}
+}
+
+func TestOutboxBacksOffExponentially(t *testing.T) {
+	opts := OutboxOptions{Backoff: time.Second, MaxBackoff: 5 * time.Second}.withDefaults()
+	for attempts, want := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 3: 4 * time.Second, 4: 5 * time.Second, 50: 5 * time.Second} {
+		if got := opts.backoff(attempts); got != want {
+			t.Errorf("backoff(%d) = %v, want %v", attempts, got, want)
+		}
+	}
+
+	// A failed attempt is not due again until its backoff has passed
+	smtp := newFakeSMTP(t, "451 later")
+	store, _ := openTestStore(t)
+	outbox := NewOutbox(store, smtp.config(), OutboxOptions{Backoff: time.Hour})
+	sub := addTestSubmission(t, store)
+	outbox.deliverDue()
+
+	got, _ := store.Get(sub.ID)
+	if got.Status != deliveryPending || got.Attempts != 1 {
+		t.Fatalf("got status %q after %d attempts, want pending after 1", got.Status, got.Attempts)
+	}
+	if wait := time.Until(got.NextAttemptAt); wait < 59*time.Minute || wait > time.Hour {
+		t.Errorf("next attempt in %v, want an hour", wait)
+	}
+	if due := store.Due(time.Now()); len(due) != 0 {
+		t.Errorf("%d submissions due during backoff", len(due))
+	}
+	if due := store.Due(got.NextAttemptAt); len(due) != 1 {
+		t.Errorf("%d submissions due after backoff, want 1", len(due))
+	}
+}
+
+func TestContactFormEnqueues(t *testing.T) {
+	// The SMTP server is down; the visitor still gets a success
+	store, _ := openTestStore(t)
+	s := &Server{contacts: store, outbox: NewOutbox(store, EmailConfig{SMTPHost: "127.0.0.1", SMTPPort: 1}, fastRetries)}
+
+	post := func(key, body string) (int, map[string]string) {
+		req := httptest.NewRequest("POST", "/contact", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if key != "" {
+			req.Header.Set("Idempotency-Key", key)
+		}
+		rec := httptest.NewRecorder()
+		s.handleContactForm(rec, req)
+		var resp map[string]string
+		json.NewDecoder(rec.Body).Decode(&resp)
+		return rec.Code, resp
+	}
+
+	body := `{"name":"Ada","email":"ada@example.com","subject":"Hi","message":"Hello"}`
+	code, first := post("key-1", body)
+	if code != http.StatusOK || first["status"] != "success" || first["id"] == "" {
+		t.Fatalf("got %d %v, want success", code, first)
+	}
+
+	// A retried request with the same key is not queued twice
+	code, again := post("key-1", body)
+	if code != http.StatusOK || again["id"] != first["id"] {
+		t.Errorf("retry got %d %v, want the first submission %s", code, again, first["id"])
+	}
+	if code, _ := post("key-1", strings.Replace(body, "Hello", "Changed", 1)); code != http.StatusUnprocessableEntity {
+		t.Errorf("reused key with a different message got %d, want 422", code)
+	}
+	if code, other := post("", body); code != http.StatusOK || other["id"] == first["id"] {
+		t.Errorf("request without a key got %d %v, want a new submission", code, other)
+	}
+
+	if subs := store.List(deliveryPending); len(subs) != 2 {
+		t.Errorf("%d pending submissions, want 2", len(subs))
+	}
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"math/bits"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Proof-of-work settings; the base difficulty is overridable with
+// CONTACT_POW_DIFFICULTY
+const (
+	defaultPowDifficulty = 16 // Leading zero bits, about 65,000 hashes on average
+	maxPowDifficulty     = 24
+	powChallengeTTL      = 30 * time.Minute
+	powHeavyThreshold    = 3 // Submissions per IP per rateWindow before the difficulty rises
+	maxPowSolution       = 32
+)
+
+// powIssuer hands out hashcash-style challenges for the contact form and
+// checks their solutions. A challenge is a random nonce, a difficulty and
+// an expiry, signed together with the client's IP so it cannot be altered
+// or solved on another's behalf. It is solved by finding a string s such
+// that SHA-256(challenge + ":" + s) starts with difficulty zero bits. Each
+// challenge is redeemed at most once.
+//
+// Browsers without JavaScript cannot do the work, so the contact page also
+// renders a question challenge, such as "What is three plus four?". In place
+// of a difficulty it carries "q" and a signature of the answer, never the
+// question itself. Question challenges are only accepted from the page's own
+// form post, are used up by a wrong answer, and are not issued to or
+// accepted from an IP whose difficulty has risen.
+//
+// Every redeemed challenge counts against the client's IP, and the
+// challenges it is issued get one bit harder, doubling the work, each time
+// its submissions in the last rateWindow double past powHeavyThreshold.
+type powIssuer struct {
+	signer *formTokenSigner
+	base   int
+
+	mu    sync.Mutex
+	spent map[string]time.Time // Redeemed nonces, until their challenge expires
+
+	submissions *rateLimiter
+}
+
+func newPowIssuer(signer *formTokenSigner, base int) *powIssuer {
+	return &powIssuer{
+		signer:      signer,
+		base:        max(0, min(base, maxPowDifficulty)),
+		spent:       make(map[string]time.Time),
+		submissions: newRateLimiter(0, rateWindow),
+	}
+}
+
+// Difficulty returns the number of leading zero bits asked of ip now
+func (p *powIssuer) Difficulty(ip string, now time.Time) int {
+	n := p.submissions.Count(ip, now)
+	if n < powHeavyThreshold {
+		return p.base
+	}
+	return min(p.base+bits.Len(uint(n/powHeavyThreshold)), maxPowDifficulty)
+}
+
+// Issue returns a challenge for ip and when it expires
+func (p *powIssuer) Issue(ip string, now time.Time) (challenge string, difficulty int, expires time.Time) {
+	difficulty = p.Difficulty(ip, now)
+	expires = now.Add(powChallengeTTL)
+	payload := fmt.Sprintf("%s.%d.%d", newSubmissionID(), difficulty, expires.Unix())
+	return payload + "." + p.signer.sign("pow:"+ip+":"+payload), difficulty, expires
+}
+
+var numberWords = []string{"zero", "one", "two", "three", "four", "five", "six", "seven", "eight", "nine"}
+
+// IssueQuestion returns a question challenge for ip and the question to
+// show with it, or empty strings if ip must do the work instead
+func (p *powIssuer) IssueQuestion(ip string, now time.Time) (challenge, question string) {
+	if p.Difficulty(ip, now) > p.base {
+		return "", ""
+	}
+	a, b := 1+rand.Intn(9), 1+rand.Intn(9)
+	nonce := newSubmissionID()
+	payload := fmt.Sprintf("%s.q%s.%d", nonce, p.answerMAC(nonce, strconv.Itoa(a+b)), now.Add(powChallengeTTL).Unix())
+	question = fmt.Sprintf("What is %s plus %s?", numberWords[a], numberWords[b])
+	return payload + "." + p.signer.sign("pow:"+ip+":"+payload), question
+}
+
+// answerMAC signs the answer to the question challenge with nonce
+func (p *powIssuer) answerMAC(nonce, answer string) string {
+	return p.signer.sign("pow-answer:" + nonce + ":" + answer)
+}
+
+// Redeem checks that solution solves challenge, which must have been issued
+// to ip and not redeemed before, and records it. It returns why not
+// otherwise. A question challenge is only accepted if questions is set.
+func (p *powIssuer) Redeem(ip, challenge, solution string, questions bool, now time.Time) error {
+	parts := strings.Split(challenge, ".")
+	if len(parts) != 4 {
+		return fmt.Errorf("missing or malformed challenge")
+	}
+	nonce, payload := parts[0], strings.Join(parts[:3], ".")
+	if !hmac.Equal([]byte(parts[3]), []byte(p.signer.sign("pow:"+ip+":"+payload))) {
+		return fmt.Errorf("challenge not issued to this client")
+	}
+	expiresUnix, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return fmt.Errorf("missing or malformed challenge")
+	}
+	expires := time.Unix(expiresUnix, 0)
+	if now.After(expires) {
+		return fmt.Errorf("challenge expired")
+	}
+	if solution == "" || len(solution) > maxPowSolution {
+		return fmt.Errorf("challenge not solved")
+	}
+	mac, question := strings.CutPrefix(parts[1], "q")
+	if question {
+		if !questions {
+			return fmt.Errorf("question challenge not posted by the form")
+		}
+		if p.Difficulty(ip, now) > p.base {
+			return fmt.Errorf("question challenge from a client that must do the work")
+		}
+	} else if difficulty, err := strconv.Atoi(parts[1]); err != nil {
+		return fmt.Errorf("missing or malformed challenge")
+	} else if leadingZeroBits(challenge+":"+solution) < difficulty {
+		return fmt.Errorf("challenge not solved")
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for n, exp := range p.spent {
+		if now.After(exp) {
+			delete(p.spent, n)
+		}
+	}
+	if _, ok := p.spent[nonce]; ok {
+		return fmt.Errorf("challenge already used")
+	}
+	p.spent[nonce] = expires
+	// Spent even when answered wrongly, so the answers cannot be tried in turn
+	if question && !hmac.Equal([]byte(mac), []byte(p.answerMAC(nonce, strings.TrimSpace(solution)))) {
+		return fmt.Errorf("question answered wrongly")
+	}
+	p.submissions.Add(ip, now)
+	return nil
+}
+
+// leadingZeroBits returns the number of zero bits the SHA-256 hash of s
+// starts with
+func leadingZeroBits(s string) int {
+	sum := sha256.Sum256([]byte(s))
+	n := 0
+	for _, b := range sum {
+		if b != 0 {
+			return n + bits.LeadingZeros8(b)
+		}
+		n += 8
+	}
+	return n
+}
+
+// powFilter rejects submissions without a solved, unused challenge
+type powFilter struct {
+	pow *powIssuer
+}
+
+func (powFilter) Name() string { return "proof_of_work" }
+
+func (f powFilter) Check(sub ContactSubmission, now time.Time) *SpamRejection {
+	if err := f.pow.Redeem(sub.IP, sub.Form.Challenge, sub.Form.Solution, sub.formPost, now); err != nil {
+		return &SpamRejection{
+			Reason:  err.Error(),
+			Status:  http.StatusBadRequest,
+			Message: "The anti-spam check failed. Please reload the page and try again.",
+		}
+	}
+	return nil
+}
+
+// contactChallengeHandler issues a proof-of-work challenge for the contact
+// form
+func (s *Server) contactChallengeHandler(w http.ResponseWriter, r *http.Request) {
+	challenge, difficulty, expires := s.pow.Issue(clientIP(r), time.Now())
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":     "success",
+		"challenge":  challenge,
+		"difficulty": difficulty,
+		"expires_at": expires.UTC(),
+	})
+}
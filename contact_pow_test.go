@@ -0,0 +1,172 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// solvePow finds a solution to challenge by brute force
+func solvePow(challenge string, difficulty int) string {
+	for i := 0; ; i++ {
+		solution := strconv.FormatInt(int64(i), 16)
+		if leadingZeroBits(challenge+":"+solution) >= difficulty {
+			return solution
+		}
+	}
+}
+
+// answerQuestion returns the answer to a question such as "What is seven
+// plus eight?"
+func answerQuestion(question string) string {
+	sum := 0
+	for _, word := range strings.Fields(strings.TrimSuffix(question, "?")) {
+		for n, w := range numberWords {
+			if word == w {
+				sum += n
+			}
+		}
+	}
+	return strconv.Itoa(sum)
+}
+
+func TestPowChallenges(t *testing.T) {
+	pow := newPowIssuer(&formTokenSigner{key: []byte("secret")}, 8)
+	now := time.Now()
+	challenge, difficulty, expires := pow.Issue("192.0.2.1", now)
+	if difficulty != 8 || !expires.Equal(now.Add(powChallengeTTL)) {
+		t.Fatalf("got difficulty %d expiring %v", difficulty, expires)
+	}
+	solution := solvePow(challenge, difficulty)
+
+	// Wrong answers and other clients are turned away without using it up
+	unsolved := "x"
+	for leadingZeroBits(challenge+":"+unsolved) >= difficulty {
+		unsolved += "x"
+	}
+	if err := pow.Redeem("192.0.2.1", challenge, unsolved, false, now); err == nil {
+		t.Error("wrong solution accepted")
+	}
+	if err := pow.Redeem("192.0.2.2", challenge, solution, false, now); err == nil {
+		t.Error("challenge accepted from another IP")
+	}
+	if err := pow.Redeem("192.0.2.1", challenge, solution, false, expires.Add(time.Second)); err == nil {
+		t.Error("expired challenge accepted")
+	}
+
+	if err := pow.Redeem("192.0.2.1", challenge, solution, false, now); err != nil {
+		t.Fatalf("solved challenge refused: %v", err)
+	}
+	if err := pow.Redeem("192.0.2.1", challenge, solution, false, now); err == nil {
+		t.Error("challenge accepted twice")
+	}
+
+	// The difficulty is signed
+	easier := strconv.Itoa(difficulty - 4)
+	forged := challenge[:17] + easier + challenge[17+len(strconv.Itoa(difficulty)):]
+	if err := pow.Redeem("192.0.2.1", forged, solvePow(forged, difficulty-4), false, now); err == nil {
+		t.Error("challenge with a lowered difficulty accepted")
+	}
+}
+
+func TestPowDifficultyRisesForHeavyIPs(t *testing.T) {
+	pow := newPowIssuer(&formTokenSigner{key: []byte("secret")}, 4)
+	now := time.Now()
+
+	var got []int
+	for i := 0; i < 12; i++ {
+		challenge, difficulty, _ := pow.Issue("192.0.2.1", now)
+		got = append(got, difficulty)
+		if err := pow.Redeem("192.0.2.1", challenge, solvePow(challenge, difficulty), false, now); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := []int{4, 4, 4, 5, 5, 5, 6, 6, 6, 6, 6, 6}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("difficulties = %v, want %v", got, want)
+		}
+	}
+
+	if d := pow.Difficulty("192.0.2.2", now); d != 4 {
+		t.Errorf("another IP got difficulty %d, want 4", d)
+	}
+	if d := pow.Difficulty("192.0.2.1", now.Add(rateWindow+time.Minute)); d != 4 {
+		t.Errorf("difficulty %d after a quiet hour, want 4", d)
+	}
+}
+
+func TestPowQuestions(t *testing.T) {
+	pow := newPowIssuer(&formTokenSigner{key: []byte("secret")}, 24)
+	now := time.Now()
+	challenge, question := pow.IssueQuestion("192.0.2.1", now)
+	if !strings.HasPrefix(question, "What is ") {
+		t.Errorf("question = %q", question)
+	}
+	answer := answerQuestion(question)
+	for _, part := range strings.Split(challenge, ".") {
+		if part == "q"+answer || strings.Contains(part, "+") {
+			t.Errorf("challenge %q gives the answer away", challenge)
+		}
+	}
+
+	if err := pow.Redeem("192.0.2.1", challenge, answer, false, now); err == nil {
+		t.Error("question accepted from the script")
+	}
+	if err := pow.Redeem("192.0.2.2", challenge, answer, true, now); err == nil {
+		t.Error("question accepted from another IP")
+	}
+	if err := pow.Redeem("192.0.2.1", challenge, " "+answer+" ", true, now); err != nil {
+		t.Fatalf("right answer refused: %v", err)
+	}
+	if err := pow.Redeem("192.0.2.1", challenge, answer, true, now); err == nil {
+		t.Error("question accepted twice")
+	}
+
+	// A wrong answer uses the question up
+	challenge, question = pow.IssueQuestion("192.0.2.1", now)
+	if err := pow.Redeem("192.0.2.1", challenge, "100", true, now); err == nil {
+		t.Error("wrong answer accepted")
+	}
+	if err := pow.Redeem("192.0.2.1", challenge, answerQuestion(question), true, now); err == nil {
+		t.Error("question answered again after a wrong answer")
+	}
+
+	// The answer is signed
+	challenge, question = pow.IssueQuestion("192.0.2.1", now)
+	parts := strings.Split(challenge, ".")
+	parts[1] = "q" + pow.answerMAC(parts[0], "100")
+	if err := pow.Redeem("192.0.2.1", strings.Join(parts, "."), "100", true, now); err == nil {
+		t.Error("question with an altered answer accepted")
+	}
+}
+
+func TestPowQuestionsStopForHeavyIPs(t *testing.T) {
+	pow := newPowIssuer(&formTokenSigner{key: []byte("secret")}, 4)
+	now := time.Now()
+	held, question := pow.IssueQuestion("192.0.2.1", now)
+
+	for i := 0; i < powHeavyThreshold; i++ {
+		challenge, q := pow.IssueQuestion("192.0.2.1", now)
+		if challenge == "" {
+			t.Fatalf("no question after %d submissions", i)
+		}
+		if err := pow.Redeem("192.0.2.1", challenge, answerQuestion(q), true, now); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if d := pow.Difficulty("192.0.2.1", now); d <= 4 {
+		t.Fatalf("difficulty %d, want it raised", d)
+	}
+
+	if challenge, q := pow.IssueQuestion("192.0.2.1", now); challenge != "" || q != "" {
+		t.Errorf("question %q issued to a heavy IP", q)
+	}
+	if err := pow.Redeem("192.0.2.1", held, answerQuestion(question), true, now); err == nil {
+		t.Error("question issued earlier accepted from a heavy IP")
+	}
+	if challenge, _ := pow.IssueQuestion("192.0.2.2", now); challenge == "" {
+		t.Error("no question for another IP")
+	}
+}
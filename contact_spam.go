@@ -0,0 +1,383 @@
+package main
+
+import (
+	"bufio"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Spam filter defaults, overridable with the CONTACT_* environment variables
+const (
+	defaultMinSubmitSeconds = 3
+	defaultFormTokenTTL     = 24 * time.Hour
+	defaultIPLimit          = 5 // Submissions per IP per rateWindow
+	defaultEmailLimit       = 3 // Submissions per sender address per rateWindow
+	defaultMaxLinks         = 3
+	rateWindow              = time.Hour
+	maxStoredRejections     = 100 // Rejected submissions kept for review per rateWindow
+)
+
+// SpamRejection explains why a filter turned a submission away
+type SpamRejection struct {
+	Filter string // Name of the filter
+	Reason string // Stored with the submission for review
+
+	// Status and Message are what the visitor is told. Filters aimed at
+	// bots leave Status zero, and the visitor gets the usual success
+	// response so there is nothing to learn from.
+	Status  int
+	Message string
+
+	// Discard drops the submission instead of storing it for review, as
+	// for a client already over its rate limit
+	Discard bool
+}
+
+// SpamFilter inspects a submission before it is queued for email
+type SpamFilter interface {
+	Name() string
+	// Check returns nil to let sub through
+	Check(sub ContactSubmission, now time.Time) *SpamRejection
+}
+
+// SpamChain runs filters in order; the first rejection wins
+type SpamChain []SpamFilter
+
+// Check returns the first filter's rejection of sub, or nil if every
+// filter lets it through
+func (c SpamChain) Check(sub ContactSubmission, now time.Time) *SpamRejection {
+	for _, f := range c {
+		if rej := f.Check(sub, now); rej != nil {
+			rej.Filter = f.Name()
+			return rej
+		}
+	}
+	return nil
+}
+
+// spamChainFromEnv builds the contact form's filters: the per-IP rate
+// limit first, so every post counts against it whatever happens to it,
+// then the checks that catch bots, the blocklist, and last the per-address
+// limit, which only submissions that would otherwise be sent count against
+func spamChainFromEnv(tokens *formTokenSigner, pow *powIssuer) SpamChain {
+	chain := SpamChain{
+		newRateLimitFilter("ip_rate", getEnvInt("CONTACT_IP_LIMIT", defaultIPLimit), func(sub ContactSubmission) string {
+			return sub.IP
+		}),
+		honeypotFilter{},
+		formTokenFilter{
+			tokens: tokens,
+			minAge: time.Duration(getEnvInt("CONTACT_MIN_SUBMIT_SECONDS", defaultMinSubmitSeconds)) * time.Second,
+			maxAge: defaultFormTokenTTL,
+		},
+		powFilter{pow: pow},
+		linkFilter{max: getEnvInt("CONTACT_MAX_LINKS", defaultMaxLinks)},
+	}
+
+	if path := getEnv("CONTACT_BLOCKLIST_FILE", ""); path != "" {
+		blocklist, err := loadBlocklist(path)
+		if err != nil {
+			log.Printf("⚠️  Contact blocklist not loaded: %v", err)
+		} else {
+			chain = append(chain, blocklist)
+		}
+	}
+
+	return append(chain,
+		newRateLimitFilter("email_rate", getEnvInt("CONTACT_EMAIL_LIMIT", defaultEmailLimit), func(sub ContactSubmission) string {
+			return strings.ToLower(strings.TrimSpace(sub.Form.Email))
+		}),
+	)
+}
+
+// honeypotFilter rejects submissions that fill in the form's hidden
+// "website" field, which people never see
+type honeypotFilter struct{}
+
+func (honeypotFilter) Name() string { return "honeypot" }
+
+func (honeypotFilter) Check(sub ContactSubmission, now time.Time) *SpamRejection {
+	if sub.Form.Website != "" {
+		return &SpamRejection{Reason: "hidden field filled in"}
+	}
+	return nil
+}
+
+// formTokenFilter requires the token the contact page was rendered with,
+// and rejects forms sent back sooner than a person could fill them in
+type formTokenFilter struct {
+	tokens *formTokenSigner
+	minAge time.Duration
+	maxAge time.Duration
+}
+
+func (formTokenFilter) Name() string { return "form_token" }
+
+func (f formTokenFilter) Check(sub ContactSubmission, now time.Time) *SpamRejection {
+	expired := &SpamRejection{
+		Status:  http.StatusBadRequest,
+		Message: "This form has expired. Please reload the page and try again.",
+	}
+	issued, ok := f.tokens.Verify(sub.Form.FormToken)
+	switch {
+	case !ok:
+		expired.Reason = "missing or invalid form token"
+		return expired
+	case now.Sub(issued) > f.maxAge:
+		expired.Reason = "form token expired"
+		return expired
+	case now.Sub(issued) < f.minAge:
+		return &SpamRejection{Reason: fmt.Sprintf("submitted %v after the form was served", now.Sub(issued).Round(time.Millisecond))}
+	}
+	return nil
+}
+
+var linkPattern = regexp.MustCompile(`(?i)\b(?:https?://|www\.)|\[url`)
+
+// linkFilter rejects messages with more links than anyone writing by hand
+// tends to include
+type linkFilter struct {
+	max int
+}
+
+func (linkFilter) Name() string { return "links" }
+
+func (f linkFilter) Check(sub ContactSubmission, now time.Time) *SpamRejection {
+	form := sub.Form
+	n := len(linkPattern.FindAllStringIndex(form.Name+"\n"+form.Subject+"\n"+form.Message, -1))
+	if n > f.max {
+		return &SpamRejection{
+			Reason:  fmt.Sprintf("%d links", n),
+			Status:  http.StatusBadRequest,
+			Message: fmt.Sprintf("Please include no more than %d links in your message.", f.max),
+		}
+	}
+	return nil
+}
+
+// blocklistFilter rejects senders and content listed in a file
+type blocklistFilter struct {
+	networks []*net.IPNet
+	emails   map[string]bool // Whole addresses, and domains as "@example.com"
+	phrases  []string        // Lowercase, matched anywhere in the form
+}
+
+// loadBlocklist reads a blocklist with one entry per line: an IP address
+// or CIDR range, an email address, an "@domain", or otherwise a phrase.
+// Blank lines and lines starting with # are ignored.
+func loadBlocklist(path string) (*blocklistFilter, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open blocklist: %v", err)
+	}
+	defer file.Close()
+
+	b := &blocklistFilter{emails: make(map[string]bool)}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		entry := strings.ToLower(strings.TrimSpace(scanner.Text()))
+		if entry == "" || strings.HasPrefix(entry, "#") {
+			continue
+		}
+		if ip := net.ParseIP(entry); ip != nil {
+			bits := 8 * len(ip.To16())
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 32
+			}
+			b.networks = append(b.networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+		} else if _, network, err := net.ParseCIDR(entry); err == nil {
+			b.networks = append(b.networks, network)
+		} else if strings.Contains(entry, "@") && !strings.ContainsAny(entry, " \t") {
+			b.emails[entry] = true
+		} else {
+			b.phrases = append(b.phrases, entry)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read blocklist: %v", err)
+	}
+	return b, nil
+}
+
+func (*blocklistFilter) Name() string { return "blocklist" }
+
+func (b *blocklistFilter) Check(sub ContactSubmission, now time.Time) *SpamRejection {
+	if ip := net.ParseIP(sub.IP); ip != nil {
+		for _, network := range b.networks {
+			if network.Contains(ip) {
+				return &SpamRejection{Reason: "blocked IP " + network.String()}
+			}
+		}
+	}
+
+	email := strings.ToLower(strings.TrimSpace(sub.Form.Email))
+	if b.emails[email] {
+		return &SpamRejection{Reason: "blocked address " + email}
+	}
+	if at := strings.LastIndex(email, "@"); at != -1 && b.emails[email[at:]] {
+		return &SpamRejection{Reason: "blocked domain " + email[at:]}
+	}
+
+	form := sub.Form
+	text := strings.ToLower(form.Name + "\n" + form.Subject + "\n" + form.Message)
+	for _, phrase := range b.phrases {
+		if strings.Contains(text, phrase) {
+			return &SpamRejection{Reason: fmt.Sprintf("blocked phrase %q", phrase)}
+		}
+	}
+	return nil
+}
+
+// rateLimitFilter allows limit submissions per key in any rateWindow; a
+// limit of zero or less turns it off
+type rateLimitFilter struct {
+	name    string
+	key     func(ContactSubmission) string
+	limiter *rateLimiter
+}
+
+func newRateLimitFilter(name string, limit int, key func(ContactSubmission) string) *rateLimitFilter {
+	return &rateLimitFilter{name: name, key: key, limiter: newRateLimiter(limit, rateWindow)}
+}
+
+func (f *rateLimitFilter) Name() string { return f.name }
+
+func (f *rateLimitFilter) Check(sub ContactSubmission, now time.Time) *SpamRejection {
+	key := f.key(sub)
+	if key == "" || f.limiter.limit <= 0 || f.limiter.Allow(key, now) {
+		return nil
+	}
+	return &SpamRejection{
+		Reason:  "rate limit exceeded for " + key,
+		Status:  http.StatusTooManyRequests,
+		Message: "Too many messages. Please try again later.",
+		Discard: true,
+	}
+}
+
+// rateLimiter counts events per key over a sliding window
+type rateLimiter struct {
+	mu        sync.Mutex
+	limit     int
+	window    time.Duration
+	hits      map[string][]time.Time
+	lastSweep time.Time
+}
+
+func newRateLimiter(limit int, window time.Duration) *rateLimiter {
+	return &rateLimiter{limit: limit, window: window, hits: make(map[string][]time.Time)}
+}
+
+// Allow records an event for key at now and reports whether it is within
+// the limit. Events over the limit are not recorded.
+func (l *rateLimiter) Allow(key string, now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	hits := l.recent(key, now)
+	if len(hits) >= l.limit {
+		return false
+	}
+	l.hits[key] = append(hits, now)
+	return true
+}
+
+// Add records an event for key at now, whatever the limit, and returns the
+// number of events for key in the window
+func (l *rateLimiter) Add(key string, now time.Time) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.hits[key] = append(l.recent(key, now), now)
+	return len(l.hits[key])
+}
+
+// Count returns the number of events for key in the window
+func (l *rateLimiter) Count(key string, now time.Time) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.recent(key, now))
+}
+
+// recent drops the events for key that have left the window and returns
+// the rest. l.mu must be held.
+func (l *rateLimiter) recent(key string, now time.Time) []time.Time {
+	// Forget keys that have been quiet for a whole window
+	if now.Sub(l.lastSweep) > l.window {
+		for k, hits := range l.hits {
+			if now.Sub(hits[len(hits)-1]) > l.window {
+				delete(l.hits, k)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	hits := l.hits[key]
+	for len(hits) > 0 && now.Sub(hits[0]) > l.window {
+		hits = hits[1:]
+	}
+	if len(hits) == 0 {
+		delete(l.hits, key)
+		return nil
+	}
+	l.hits[key] = hits
+	return hits
+}
+
+// formTokenSigner issues and checks the tokens embedded in the contact
+// form: the time the page was served, signed so it cannot be forged
+type formTokenSigner struct {
+	key []byte
+}
+
+// newFormTokenSigner signs with CONTACT_FORM_SECRET, or with a random key
+// if it is unset, in which case forms served before a restart stop working
+func newFormTokenSigner() *formTokenSigner {
+	if secret := getEnv("CONTACT_FORM_SECRET", ""); secret != "" {
+		return &formTokenSigner{key: []byte(secret)}
+	}
+	log.Printf("⚠️  CONTACT_FORM_SECRET not set; contact forms will expire when the server restarts")
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		// crypto/rand does not fail on supported platforms
+		panic(err)
+	}
+	return &formTokenSigner{key: key}
+}
+
+func (s *formTokenSigner) sign(payload string) string {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// Issue returns a token recording now
+func (s *formTokenSigner) Issue(now time.Time) string {
+	issued := strconv.FormatInt(now.UnixMilli(), 10)
+	return issued + "." + s.sign(issued)
+}
+
+// Verify returns the time a token was issued, if it was signed by s
+func (s *formTokenSigner) Verify(token string) (time.Time, bool) {
+	issued, sig, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(sig), []byte(s.sign(issued))) {
+		return time.Time{}, false
+	}
+	ms, err := strconv.ParseInt(issued, 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.UnixMilli(ms), true
+}
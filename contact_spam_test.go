@@ -0,0 +1,155 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormTokens(t *testing.T) {
+	signer := &formTokenSigner{key: []byte("secret")}
+	filter := formTokenFilter{tokens: signer, minAge: 3 * time.Second, maxAge: time.Hour}
+	served := time.Now()
+	token := signer.Issue(served)
+
+	check := func(token string, at time.Time) *SpamRejection {
+		return filter.Check(ContactSubmission{Form: ContactForm{FormToken: token}}, at)
+	}
+	if rej := check(token, served.Add(10*time.Second)); rej != nil {
+		t.Errorf("valid token rejected: %+v", rej)
+	}
+	if rej := check(token, served.Add(time.Second)); rej == nil || rej.Status != 0 {
+		t.Errorf("fast submission got %+v, want a silent rejection", rej)
+	}
+	if rej := check(token, served.Add(2*time.Hour)); rej == nil || rej.Status != http.StatusBadRequest {
+		t.Errorf("expired token got %+v, want 400", rej)
+	}
+
+	// Moving the time back breaks the signature
+	issued, sig, _ := strings.Cut(token, ".")
+	forged := strings.TrimSuffix(issued, "0") + "1." + sig
+	for _, bad := range []string{"", "garbage", forged, (&formTokenSigner{key: []byte("other")}).Issue(served)} {
+		if rej := check(bad, served.Add(10*time.Second)); rej == nil || rej.Status != http.StatusBadRequest {
+			t.Errorf("token %q got %+v, want 400", bad, rej)
+		}
+	}
+}
+
+func TestBlocklist(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "blocklist.txt")
+	os.WriteFile(path, []byte("# comment\n203.0.113.7\n198.51.100.0/24\nspammer@example.com\n@spam.test\nCheap Pills\n"), 0644)
+	b, err := loadBlocklist(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		sub     ContactSubmission
+		blocked bool
+	}{
+		{ContactSubmission{IP: "203.0.113.7"}, true},
+		{ContactSubmission{IP: "203.0.113.8"}, false},
+		{ContactSubmission{IP: "198.51.100.42"}, true},
+		{ContactSubmission{Form: ContactForm{Email: "Spammer@Example.com"}}, true},
+		{ContactSubmission{Form: ContactForm{Email: "friend@example.com"}}, false},
+		{ContactSubmission{Form: ContactForm{Email: "anyone@spam.test"}}, true},
+		{ContactSubmission{Form: ContactForm{Message: "Buy CHEAP PILLS now"}}, true},
+		{ContactSubmission{Form: ContactForm{Message: "Lunch next week?"}}, false},
+	} {
+		if rej := b.Check(tc.sub, time.Now()); (rej != nil) != tc.blocked {
+			t.Errorf("%+v: got %+v, want blocked %v", tc.sub, rej, tc.blocked)
+		}
+	}
+}
+
+func TestLinkFilter(t *testing.T) {
+	f := linkFilter{max: 2}
+	ok := "See https://example.com and www.example.org"
+	if rej := f.Check(ContactSubmission{Form: ContactForm{Message: ok}}, time.Now()); rej != nil {
+		t.Errorf("two links rejected: %+v", rej)
+	}
+	spam := ok + " and [url=http://spam.test]this[/url]"
+	if rej := f.Check(ContactSubmission{Form: ContactForm{Message: spam}}, time.Now()); rej == nil {
+		t.Error("four links accepted")
+	}
+}
+
+func TestRateLimiter(t *testing.T) {
+	l := newRateLimiter(2, time.Hour)
+	start := time.Now()
+	if !l.Allow("a", start) || !l.Allow("a", start.Add(time.Minute)) {
+		t.Fatal("first two events refused")
+	}
+	if l.Allow("a", start.Add(2*time.Minute)) {
+		t.Error("third event within the window allowed")
+	}
+	if !l.Allow("b", start.Add(2*time.Minute)) {
+		t.Error("other key refused")
+	}
+	if !l.Allow("a", start.Add(61*time.Minute)) {
+		t.Error("event refused after the first one left the window")
+	}
+}
+
+func TestContactFormRejectsSpam(t *testing.T) {
+	store, _ := openTestStore(t)
+	signer := &formTokenSigner{key: []byte("secret")}
+	s := &Server{
+		contacts:   store,
+		outbox:     NewOutbox(store, EmailConfig{}, OutboxOptions{}),
+		formTokens: signer,
+		spam: SpamChain{
+			newRateLimitFilter("ip_rate", 3, func(sub ContactSubmission) string { return sub.IP }),
+			honeypotFilter{},
+			formTokenFilter{tokens: signer, minAge: time.Second, maxAge: time.Hour},
+		},
+		rejections: newRateLimiter(1, time.Hour),
+	}
+	token := signer.Issue(time.Now().Add(-time.Minute))
+
+	post := func(body string) (int, map[string]string) {
+		req := httptest.NewRequest("POST", "/contact", strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		s.handleContactForm(rec, req)
+		var resp map[string]string
+		json.NewDecoder(rec.Body).Decode(&resp)
+		return rec.Code, resp
+	}
+	form := `{"name":"Ada","email":"ada@example.com","message":"Hello","form_token":"` + token + `"`
+
+	// The bot is told it succeeded, whether or not its post is kept
+	if code, _ := post(form + `,"website":"http://spam.test"}`); code != http.StatusOK {
+		t.Errorf("honeypot got %d, want 200", code)
+	}
+	if code, resp := post(form + `,"website":"http://spam.test"}`); code != http.StatusOK || resp["status"] != "success" {
+		t.Errorf("honeypot past the storage limit got %d %v, want success", code, resp)
+	}
+	// Rejected posts counted against the IP's limit too
+	if code, _ := post(form + `}`); code != http.StatusOK {
+		t.Errorf("first message got %d, want 200", code)
+	}
+	if code, _ := post(form + `}`); code != http.StatusTooManyRequests {
+		t.Errorf("message over the limit got %d, want 429", code)
+	}
+
+	rejected := store.List(deliveryRejected)
+	if len(rejected) != 1 || !strings.HasPrefix(rejected[0].Rejection, "honeypot: ") {
+		t.Errorf("stored rejections %+v, want only the first honeypot", rejected)
+	}
+	if pending := store.List(deliveryPending); len(pending) != 1 {
+		t.Errorf("%d submissions queued, want 1", len(pending))
+	}
+
+	// A rejection that fails to store is still answered as a success
+	s.rejections = newRateLimiter(1, time.Hour)
+	s.spam = SpamChain{honeypotFilter{}}
+	store.file.Close()
+	if code, resp := post(form + `,"website":"http://spam.test"}`); code != http.StatusOK || resp["status"] != "success" {
+		t.Errorf("unstored honeypot got %d %v, want success", code, resp)
+	}
+}
@@ -0,0 +1,392 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Delivery states of a contact submission
+const (
+	deliveryPending  = "pending" // Queued in the outbox, not sent yet
+	deliverySent     = "sent"
+	deliveryDead     = "dead"     // Gave up after a permanent error or too many attempts
+	deliveryRejected = "rejected" // Turned away by a spam filter, never emailed
+)
+
+// Limits on what a contact form post may send
+const (
+	maxContactBody    = 64 << 10
+	maxIdempotencyKey = 255
+)
+
+// ContactSubmission is a contact form message as stored, with where it came
+// from and how far its email delivery has got
+type ContactSubmission struct {
+	ID             string      `json:"id"`
+	IdempotencyKey string      `json:"idempotency_key,omitempty"`
+	ReceivedAt     time.Time   `json:"received_at"`
+	IP             string      `json:"ip"`
+	UserAgent      string      `json:"user_agent"`
+	Form           ContactForm `json:"form"`
+	Status         string      `json:"status"`
+	Attempts       int         `json:"attempts"`
+	NextAttemptAt  time.Time   `json:"next_attempt_at"`     // When a pending email is next tried
+	Error          string      `json:"error,omitempty"`     // Last delivery error
+	Rejection      string      `json:"rejection,omitempty"` // Spam filter and reason, if rejected
+	AutoReply      string      `json:"auto_reply,omitempty"`
+	AutoReplyAt    *time.Time  `json:"auto_reply_at,omitempty"`
+	UpdatedAt      time.Time   `json:"updated_at"`
+
+	formPost bool // Posted by the page's own form, not the script; not stored
+}
+
+// compactMinLines is the file length below which the contact store is never
+// compacted while running
+const compactMinLines = 1000
+
+// ContactStore keeps contact submissions in an append-only JSON Lines file.
+// Every change appends the whole record and is synced to disk before it
+// returns; when the file is read back, the last line for an ID wins. The
+// file is compacted to one line per submission when it is opened, and again
+// once superseded lines make up most of it.
+type ContactStore struct {
+	mu      sync.Mutex
+	path    string
+	file    *os.File
+	lines   int // Records in the file, including superseded ones
+	records map[string]*ContactSubmission
+	order   []string          // IDs in the order they were received
+	keys    map[string]string // Idempotency key to ID
+}
+
+// OpenContactStore loads the submissions in path, creating the file if it
+// does not exist. A truncated last line, left by a crash mid-write, is
+// skipped.
+func OpenContactStore(path string) (*ContactStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create contact store directory: %v", err)
+	}
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open contact store: %v", err)
+	}
+
+	s := &ContactStore{
+		path:    path,
+		file:    file,
+		records: make(map[string]*ContactSubmission),
+		keys:    make(map[string]string),
+	}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, 1<<20)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		s.lines++
+		var sub ContactSubmission
+		if err := json.Unmarshal(scanner.Bytes(), &sub); err != nil || sub.ID == "" {
+			log.Printf("⚠️  %s:%d: skipping unreadable contact submission", path, line)
+			continue
+		}
+		if sub.Status == "failed" {
+			// Sent synchronously before the outbox existed; queue it again
+			sub.Status = deliveryPending
+		}
+		s.put(sub)
+	}
+	if err := scanner.Err(); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to read contact store: %v", err)
+	}
+
+	if s.lines > len(s.records) {
+		if err := s.compact(); err != nil {
+			file.Close()
+			return nil, err
+		}
+		log.Printf("Compacted %s to %d submissions", path, len(s.records))
+		return s, nil
+	}
+
+	// End a truncated last line so the next record starts on its own
+	if info, err := file.Stat(); err == nil && info.Size() > 0 {
+		last := make([]byte, 1)
+		if _, err := file.ReadAt(last, info.Size()-1); err == nil && last[0] != '\n' {
+			if _, err := file.Write([]byte("\n")); err != nil {
+				file.Close()
+				return nil, fmt.Errorf("failed to repair contact store: %v", err)
+			}
+		}
+	}
+	return s, nil
+}
+
+// put records sub in memory. s.mu must be held or s not yet shared.
+func (s *ContactStore) put(sub ContactSubmission) {
+	if _, ok := s.records[sub.ID]; !ok {
+		s.order = append(s.order, sub.ID)
+	}
+	if sub.IdempotencyKey != "" {
+		s.keys[sub.IdempotencyKey] = sub.ID
+	}
+	s.records[sub.ID] = &sub
+}
+
+// write appends sub to the file and syncs it. s.mu must be held.
+func (s *ContactStore) write(sub ContactSubmission) error {
+	line, err := json.Marshal(sub)
+	if err != nil {
+		return err
+	}
+	if _, err := s.file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write contact submission: %v", err)
+	}
+	if err := s.file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync contact store: %v", err)
+	}
+	s.lines++
+	s.put(sub)
+
+	// The record is safely stored either way, so a failed compaction only
+	// leaves the file longer than it needs to be
+	if s.lines >= compactMinLines && s.lines > 2*len(s.records) {
+		if err := s.compact(); err != nil {
+			log.Printf("⚠️  Failed to compact contact store: %v", err)
+		}
+	}
+	return nil
+}
+
+// compact rewrites the file with only the latest record of each submission,
+// in the order they were received. The new file is written and synced next
+// to the old one and renamed over it, so a crash leaves one or the other.
+// s.mu must be held or s not yet shared.
+func (s *ContactStore) compact() error {
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), "."+filepath.Base(s.path)+".tmp-")
+	if err != nil {
+		return fmt.Errorf("failed to compact contact store: %v", err)
+	}
+	fail := func(err error) error {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to compact contact store: %v", err)
+	}
+
+	w := bufio.NewWriter(tmp)
+	for _, id := range s.order {
+		line, err := json.Marshal(s.records[id])
+		if err != nil {
+			return fail(err)
+		}
+		w.Write(append(line, '\n'))
+	}
+	if err := w.Flush(); err != nil {
+		return fail(err)
+	}
+	if err := tmp.Sync(); err != nil {
+		return fail(err)
+	}
+	// Open the new file for appending before it replaces the old one, so
+	// there is nothing left to fail once it has
+	file, err := os.OpenFile(tmp.Name(), os.O_RDWR|os.O_APPEND, 0600)
+	if err != nil {
+		return fail(err)
+	}
+	tmp.Close()
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		file.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to compact contact store: %v", err)
+	}
+	if dir, err := os.Open(filepath.Dir(s.path)); err == nil {
+		dir.Sync()
+		dir.Close()
+	}
+
+	s.file.Close()
+	s.file = file
+	s.lines = len(s.order)
+	return nil
+}
+
+// Add stores a new submission as pending and due now, assigning its ID and
+// timestamps. If a submission with the same idempotency key is already
+// stored, that one is returned instead and added is false.
+func (s *ContactStore) Add(sub ContactSubmission) (stored ContactSubmission, added bool, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if id, ok := s.keys[sub.IdempotencyKey]; ok && sub.IdempotencyKey != "" {
+		return *s.records[id], false, nil
+	}
+
+	sub.ID = newSubmissionID()
+	sub.ReceivedAt = time.Now().UTC()
+	sub.UpdatedAt = sub.ReceivedAt
+	if sub.Status == "" {
+		sub.Status = deliveryPending
+		sub.NextAttemptAt = sub.ReceivedAt
+	}
+	if err := s.write(sub); err != nil {
+		return ContactSubmission{}, false, err
+	}
+	return sub, true, nil
+}
+
+// Update applies change to the stored submission id and persists the
+// result
+func (s *ContactStore) Update(id string, change func(*ContactSubmission)) (ContactSubmission, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, ok := s.records[id]
+	if !ok {
+		return ContactSubmission{}, fmt.Errorf("unknown contact submission %q", id)
+	}
+	sub := *current
+	change(&sub)
+	sub.ID = id
+	sub.UpdatedAt = time.Now().UTC()
+	if err := s.write(sub); err != nil {
+		return ContactSubmission{}, err
+	}
+	return sub, nil
+}
+
+// Get returns the submission with the given ID
+func (s *ContactStore) Get(id string) (ContactSubmission, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sub, ok := s.records[id]
+	if !ok {
+		return ContactSubmission{}, false
+	}
+	return *sub, true
+}
+
+// ByKey returns the submission stored with an idempotency key
+func (s *ContactStore) ByKey(key string) (ContactSubmission, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id, ok := s.keys[key]
+	if !ok || key == "" {
+		return ContactSubmission{}, false
+	}
+	return *s.records[id], true
+}
+
+// Due returns the pending submissions whose next attempt is at or before
+// now, oldest first
+func (s *ContactStore) Due(now time.Time) []ContactSubmission {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var subs []ContactSubmission
+	for _, id := range s.order {
+		sub := s.records[id]
+		if sub.Status == deliveryPending && !sub.NextAttemptAt.After(now) {
+			subs = append(subs, *sub)
+		}
+	}
+	return subs
+}
+
+// NextAttempt returns the earliest time a pending submission is due, if
+// there are any
+func (s *ContactStore) NextAttempt() (time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var next time.Time
+	found := false
+	for _, sub := range s.records {
+		if sub.Status == deliveryPending && (!found || sub.NextAttemptAt.Before(next)) {
+			next, found = sub.NextAttemptAt, true
+		}
+	}
+	return next, found
+}
+
+// AutoRepliedSince returns the submissions whose auto-reply was sent after
+// since
+func (s *ContactStore) AutoRepliedSince(since time.Time) []ContactSubmission {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var subs []ContactSubmission
+	for _, sub := range s.records {
+		if sub.AutoReplyAt != nil && sub.AutoReplyAt.After(since) {
+			subs = append(subs, *sub)
+		}
+	}
+	return subs
+}
+
+// List returns the submissions, newest first, optionally only those in the
+// given status
+func (s *ContactStore) List(status string) []ContactSubmission {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	subs := make([]ContactSubmission, 0, len(s.order))
+	for i := len(s.order) - 1; i >= 0; i-- {
+		sub := s.records[s.order[i]]
+		if status == "" || sub.Status == status {
+			subs = append(subs, *sub)
+		}
+	}
+	return subs
+}
+
+// newSubmissionID returns a random 16 character hex ID
+func newSubmissionID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		// crypto/rand does not fail on supported platforms
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+// clientIP returns the address of the client that made r. With TRUST_PROXY
+// set, as behind Heroku's router, it is the last address the proxy appended
+// to X-Forwarded-For; otherwise the header is ignored, since any client can
+// set it.
+func clientIP(r *http.Request) string {
+	if trustProxy() {
+		if forwarded := r.Header.Values("X-Forwarded-For"); len(forwarded) > 0 {
+			hops := strings.Split(forwarded[len(forwarded)-1], ",")
+			if ip := strings.TrimSpace(hops[len(hops)-1]); net.ParseIP(ip) != nil {
+				return ip
+			}
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func trustProxy() bool {
+	switch os.Getenv("TRUST_PROXY") {
+	case "1", "true", "TRUE", "yes":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestClientIP(t *testing.T) {
+	for _, tc := range []struct {
+		trust     string
+		remote    string
+		forwarded []string
+		want      string
+	}{
+		{"", "203.0.113.7:4711", nil, "203.0.113.7"},
+		{"", "[2001:db8::1]:4711", nil, "2001:db8::1"},
+		{"", "pipe", nil, "pipe"},
+
+		// Without TRUST_PROXY the header is a client's word and ignored
+		{"", "10.0.0.1:4711", []string{"198.51.100.9"}, "10.0.0.1"},
+		{"0", "10.0.0.1:4711", []string{"198.51.100.9"}, "10.0.0.1"},
+
+		// Behind a proxy the last hop it appended is the client; earlier
+		// hops and earlier headers are client supplied
+		{"true", "10.0.0.1:4711", []string{"198.51.100.9"}, "198.51.100.9"},
+		{"1", "10.0.0.1:4711", []string{"1.2.3.4, 198.51.100.9"}, "198.51.100.9"},
+		{"yes", "10.0.0.1:4711", []string{"1.2.3.4", "5.6.7.8,198.51.100.9"}, "198.51.100.9"},
+		{"TRUE", "10.0.0.1:4711", []string{"2001:db8::2"}, "2001:db8::2"},
+
+		// A missing or garbled header falls back to the connection
+		{"true", "10.0.0.1:4711", nil, "10.0.0.1"},
+		{"true", "10.0.0.1:4711", []string{"198.51.100.9, not an address"}, "10.0.0.1"},
+		{"true", "10.0.0.1:4711", []string{""}, "10.0.0.1"},
+	} {
+		t.Setenv("TRUST_PROXY", tc.trust)
+		r := httptest.NewRequest("POST", "/contact", nil)
+		r.RemoteAddr = tc.remote
+		for _, value := range tc.forwarded {
+			r.Header.Add("X-Forwarded-For", value)
+		}
+		if got := clientIP(r); got != tc.want {
+			t.Errorf("TRUST_PROXY=%q, remote %s, X-Forwarded-For %q: got %s, want %s", tc.trust, tc.remote, tc.forwarded, got, tc.want)
+		}
+	}
+}
+
+func countLines(t *testing.T, path string) int {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return bytes.Count(data, []byte("\n"))
+}
+
+func TestContactStoreCompactsOnOpen(t *testing.T) {
+	store, path := openTestStore(t)
+	first := addTestSubmission(t, store)
+	second, _, _ := store.Add(ContactSubmission{IdempotencyKey: "k2", Form: ContactForm{Name: "Grace"}})
+	for _, status := range []string{deliverySent, deliveryDead, deliverySent} {
+		store.Update(first.ID, func(sub *ContactSubmission) { sub.Status = status; sub.Attempts++ })
+	}
+	want := store.List("")
+
+	// A crash mid-write leaves half a line at the end
+	f, _ := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0600)
+	f.WriteString(`{"id": "trunc`)
+	f.Close()
+	if n := countLines(t, path); n != 5 {
+		t.Fatalf("%d lines before compaction, want 5", n)
+	}
+
+	store, err := OpenContactStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := countLines(t, path); n != 2 {
+		t.Errorf("%d lines after compaction, want one per submission", n)
+	}
+	if got := store.List(""); !reflect.DeepEqual(got, want) {
+		t.Errorf("compaction changed the submissions\ngot  %+v\nwant %+v", got, want)
+	}
+	if got, ok := store.ByKey("k2"); !ok || got.ID != second.ID {
+		t.Errorf("idempotency key lost in compaction, got %+v", got)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0600 {
+		t.Errorf("compacted file mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	// The store keeps appending to the compacted file
+	third := addTestSubmission(t, store)
+	store, err = OpenContactStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := store.Get(third.ID); !ok || len(store.List("")) != 3 || countLines(t, path) != 3 {
+		t.Errorf("after reopening got %d submissions in %d lines", len(store.List("")), countLines(t, path))
+	}
+	if entries, _ := os.ReadDir(strings.TrimSuffix(path, "contact.jsonl")); len(entries) != 1 {
+		t.Errorf("temporary files left behind: %v", entries)
+	}
+}
+
+func TestContactStoreCompactsWhileRunning(t *testing.T) {
+	store, path := openTestStore(t)
+	sub := addTestSubmission(t, store)
+	for i := 1; i < compactMinLines; i++ {
+		if _, err := store.Update(sub.ID, func(sub *ContactSubmission) { sub.Attempts++ }); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The update that reaches the threshold rewrites the file
+	if n := countLines(t, path); n != 1 {
+		t.Errorf("%d lines after %d updates, want the file compacted", n, compactMinLines-1)
+	}
+	store.Update(sub.ID, func(sub *ContactSubmission) { sub.Attempts++ })
+	reopened, err := OpenContactStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := reopened.Get(sub.ID); got.Attempts != compactMinLines {
+		t.Errorf("attempts = %d after reopening, want %d", got.Attempts, compactMinLines)
+	}
+}
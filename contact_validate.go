@@ -0,0 +1,144 @@
+package main
+
+import (
+	"fmt"
+	"net/mail"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Contact form field limits, in characters after normalization
+const (
+	maxContactName    = 100
+	maxContactEmail   = 254
+	maxContactSubject = 150
+	maxContactMessage = 5000
+	maxContactSpamKey = 512 // The honeypot, form token and challenge fields
+)
+
+// FieldErrors maps a contact form field's JSON name to what is wrong with it
+type FieldErrors map[string]string
+
+// Normalize cleans up a posted form before it is validated: text is put in
+// Unicode NFC form, control characters are removed and surrounding space is
+// trimmed. The single-line fields have runs of whitespace, line breaks
+// included, collapsed to one space, so nothing can break out of a mail
+// header; the message keeps its line breaks and tabs. The email address is
+// reduced to its bare address when it parses, and an empty subject is
+// replaced with one naming the sender.
+func (f *ContactForm) Normalize() {
+	f.Name = normalizeLine(f.Name)
+	f.Email = normalizeLine(f.Email)
+	f.Subject = normalizeLine(f.Subject)
+	f.Message = normalizeText(f.Message)
+
+	if addr, err := mail.ParseAddress(f.Email); err == nil && addr.Name == "" {
+		f.Email = addr.Address
+	}
+	if f.Subject == "" && f.Name != "" {
+		f.Subject = "Message from " + f.Name
+	}
+}
+
+// Validate returns the problems with a normalized form, if any
+func (f ContactForm) Validate() FieldErrors {
+	errs := make(FieldErrors)
+	checkLength := func(field, value string, limit int) {
+		if n := utf8.RuneCountInString(value); n > limit {
+			errs[field] = fmt.Sprintf("Please keep this under %d characters (currently %d).", limit, n)
+		}
+	}
+
+	if f.Name == "" {
+		errs["name"] = "Please enter your name."
+	}
+	checkLength("name", f.Name, maxContactName)
+
+	switch addr, err := mail.ParseAddress(f.Email); {
+	case f.Email == "":
+		errs["email"] = "Please enter your email address."
+	case err != nil || addr.Name != "" || addr.Address != f.Email:
+		errs["email"] = "Please enter a valid email address, like name@example.com."
+	case !strings.Contains(f.Email[strings.LastIndex(f.Email, "@"):], "."):
+		errs["email"] = "Please enter a full email address, including the domain."
+	}
+	checkLength("email", f.Email, maxContactEmail)
+
+	checkLength("subject", f.Subject, maxContactSubject)
+
+	if f.Message == "" {
+		errs["message"] = "Please enter a message."
+	}
+	checkLength("message", f.Message, maxContactMessage)
+
+	// Not shown to visitors, but not worth storing at any length
+	for field, value := range map[string]string{
+		"website": f.Website, "form_token": f.FormToken, "challenge": f.Challenge, "solution": f.Solution,
+	} {
+		if len(value) > maxContactSpamKey {
+			errs[field] = "This field is too long."
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// normalizeLine normalizes a single-line form field
+func normalizeLine(s string) string {
+	s = norm.NFC.String(s)
+	var b strings.Builder
+	space := false
+	for _, r := range s {
+		switch {
+		case unicode.IsSpace(r):
+			space = true
+			continue
+		case !keepRune(r):
+			continue
+		}
+		if space && b.Len() > 0 {
+			b.WriteByte(' ')
+		}
+		space = false
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// normalizeText normalizes a multi-line form field, keeping its line breaks,
+// which become "\n", and its tabs
+func normalizeText(s string) string {
+	s = norm.NFC.String(s)
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '\r' || r == '\u2028' || r == '\u2029' || r == '\u0085':
+			b.WriteByte('\n')
+		case r == '\n' || r == '\t':
+			b.WriteRune(r)
+		case keepRune(r):
+			b.WriteRune(r)
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// keepRune reports whether r may appear in a form field: control
+// characters and the invisible bidirectional overrides that can disguise
+// text are dropped, as is the replacement for invalid UTF-8
+func keepRune(r rune) bool {
+	switch {
+	case unicode.IsControl(r), r == utf8.RuneError:
+		return false
+	case r >= '\u202a' && r <= '\u202e', r >= '\u2066' && r <= '\u2069':
+		return false
+	}
+	return true
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestContactFormNormalize(t *testing.T) {
+	form := ContactForm{
+		Name:    "  Zoe\u0301\tBell\x00 ",
+		Email:   " <ada@example.com> ",
+		Subject: "Hello\r\nBcc: victim@example.com",
+		Message: "\r\nLine one\r\n\u202eLine\x07 two\u2028\tthree  \n",
+	}
+	form.Normalize()
+
+	want := ContactForm{
+		Name:    "Zo\u00e9 Bell", // Composed, as NFC
+		Email:   "ada@example.com",
+		Subject: "Hello Bcc: victim@example.com",
+		Message: "Line one\nLine two\n\tthree",
+	}
+	if form != want {
+		t.Errorf("got %+q\nwant %+q", form, want)
+	}
+
+	empty := ContactForm{Name: "Ada", Subject: " \r\n "}
+	empty.Normalize()
+	if empty.Subject != "Message from Ada" {
+		t.Errorf("empty subject became %q", empty.Subject)
+	}
+}
+
+func TestContactFormValidate(t *testing.T) {
+	valid := ContactForm{Name: "Ada", Email: "ada@example.com", Subject: "Hi", Message: "Hello"}
+	if errs := valid.Validate(); errs != nil {
+		t.Errorf("valid form got %v", errs)
+	}
+
+	for _, tc := range []struct {
+		change func(*ContactForm)
+		field  string
+	}{
+		{func(f *ContactForm) { f.Name = "" }, "name"},
+		{func(f *ContactForm) { f.Name = strings.Repeat("é", maxContactName+1) }, "name"},
+		{func(f *ContactForm) { f.Email = "" }, "email"},
+		{func(f *ContactForm) { f.Email = "not an address" }, "email"},
+		{func(f *ContactForm) { f.Email = "Ada <ada@example.com>" }, "email"},
+		{func(f *ContactForm) { f.Email = "ada@localhost" }, "email"},
+		{func(f *ContactForm) { f.Subject = strings.Repeat("x", maxContactSubject+1) }, "subject"},
+		{func(f *ContactForm) { f.Message = "" }, "message"},
+		{func(f *ContactForm) { f.Message = strings.Repeat("x", maxContactMessage+1) }, "message"},
+		{func(f *ContactForm) { f.Website = strings.Repeat("x", maxContactSpamKey+1) }, "website"},
+	} {
+		form := valid
+		tc.change(&form)
+		errs := form.Validate()
+		if len(errs) != 1 || errs[tc.field] == "" {
+			t.Errorf("%+q: got %v, want an error for %s", form, errs, tc.field)
+		}
+	}
+
+	// Limits count characters, not bytes
+	form := valid
+	form.Name = strings.Repeat("é", maxContactName)
+	if errs := form.Validate(); errs != nil {
+		t.Errorf("name of %d accented letters got %v", maxContactName, errs)
+	}
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"html/template"
+	"log"
+	"os"
+	"path/filepath"
+	texttemplate "text/template"
+
+	"github.com/daveonthegit/Personal_Portfolio/config"
+)
+
+// siteContent is everything the page handlers render from. It is replaced as
+// a whole on reload so a request never mixes templates and data from
+// different versions.
+type siteContent struct {
+	templates *template.Template
+	projects  []Project
+	personal  config.PersonalInfo
+	search    *SearchIndex // Rebuilt from projects on every load
+	resumeTeX *texttemplate.Template
+	variants  []config.ResumeVariant
+	ats       *atsProfile // Rebuilt from personal info and projects on every load
+}
+
+// builtinPersonal is content/personal.yaml as it was at build time. It is
+// used when the content directory has no valid personal file, so the
+// fallback never drifts from the shipped content.
+//
+//go:embed content/personal.yaml
+var builtinPersonal []byte
+
+// builtinPersonalInfo returns the personal info built into the binary
+func builtinPersonalInfo() config.PersonalInfo {
+	info, err := config.ParsePersonalInfo("content/personal.yaml", builtinPersonal)
+	if err != nil {
+		// Checked by the tests, so only a broken build gets here
+		panic(fmt.Sprintf("built-in personal info: %v", err))
+	}
+	return info
+}
+
+// content returns the current content snapshot
+func (s *Server) content() *siteContent {
+	return s.current.Load()
+}
+
+// loadSiteContent parses the templates and loads projects and personal info
+// from contentDir. Missing content falls back to the built-in data; invalid
+// content also falls back, but the problems are returned so the caller can
+// decide whether to accept the result.
+func loadSiteContent(contentDir string) (*siteContent, error) {
+	templates, err := template.ParseGlob("templates/*.html")
+	if err != nil {
+		return nil, err
+	}
+
+	var errs []error
+
+	resumeTeX, err := parseResumeTeXTemplate()
+	if err != nil {
+		errs = append(errs, err)
+		log.Printf("⚠️  Could not parse resume LaTeX template: %v", err)
+	}
+
+	projectsDir := filepath.Join(contentDir, "projects")
+	projects, err := LoadProjectsFromDir(projectsDir)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			errs = append(errs, err)
+		}
+		log.Printf("⚠️  Could not load projects from %s, using built-in list:", projectsDir)
+		logContentErrors(err)
+		projects = LoadProjects()
+	} else {
+		log.Printf("Loaded %d projects from %s", len(projects), projectsDir)
+	}
+
+	personal, err := loadPersonal(contentDir)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			errs = append(errs, err)
+		}
+		log.Printf("⚠️  Could not load personal info, using built-in defaults:")
+		logContentErrors(err)
+		personal = builtinPersonalInfo()
+	}
+
+	variantsPath := filepath.Join(contentDir, resumeVariantsFile)
+	variants, err := config.LoadResumeVariants(variantsPath, personal, validProjectTypes)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		errs = append(errs, err)
+		log.Printf("⚠️  Could not load resume variants from %s:", variantsPath)
+		logContentErrors(err)
+	}
+
+	return &siteContent{
+		templates: templates,
+		projects:  projects,
+		personal:  personal,
+		search:    NewSearchIndex(projects),
+		resumeTeX: resumeTeX,
+		variants:  variants,
+		ats:       newATSProfile(personal, projects),
+	}, errors.Join(errs...)
+}
+
+// loadPersonal loads personal info from PERSONAL_FILE, or from the
+// personal.yaml/personal.toml file in the content directory
+func loadPersonal(contentDir string) (config.PersonalInfo, error) {
+	path := getEnv("PERSONAL_FILE", "")
+	if path == "" {
+		found, err := config.FindPersonalFile(contentDir)
+		if err != nil {
+			return config.PersonalInfo{}, err
+		}
+		path = found
+	}
+
+	personal, err := config.LoadPersonalInfo(path)
+	if err != nil {
+		return config.PersonalInfo{}, err
+	}
+	log.Printf("Loaded personal info from %s", path)
+	return personal, nil
+}
+
+// logContentErrors logs each error joined by the content loaders on its own line
+func logContentErrors(err error) {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		for _, e := range joined.Unwrap() {
+			logContentErrors(e)
+		}
+		return
+	}
+	log.Printf("   %v", err)
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/daveonthegit/Personal_Portfolio/config"
+)
+
+// The fallback personal info is the shipped personal.yaml, not a copy
+func TestBuiltinPersonalInfo(t *testing.T) {
+	want, err := config.LoadPersonalInfo("content/personal.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := builtinPersonalInfo(); !reflect.DeepEqual(got, want) {
+		t.Errorf("built-in personal info differs from content/personal.yaml\ngot  %+v\nwant %+v", got, want)
+	}
+
+	t.Setenv("PERSONAL_FILE", "")
+	c, err := loadSiteContent(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(c.personal, want) {
+		t.Error("missing personal file did not fall back to the built-in info")
+	}
+}
+
+// The project page opens and closes its elements inside the same
+// template block, so it is well formed with and without a project
+func TestProjectContentBalanced(t *testing.T) {
+	c, err := loadSiteContent("content")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, data := range []PageData{
+		{Project: &c.projects[0], NextProject: &c.projects[1]},
+		{Project: &c.projects[1], PrevProject: &c.projects[0]},
+		{},
+	} {
+		var b bytes.Buffer
+		if err := c.templates.ExecuteTemplate(&b, "project-content", data); err != nil {
+			t.Fatal(err)
+		}
+		page := b.String()
+		for _, tag := range []string{"div", "section"} {
+			if open, closed := strings.Count(page, "<"+tag), strings.Count(page, "</"+tag+">"); open != closed {
+				t.Errorf("%d <%s> and %d </%s> in\n%s", open, tag, closed, tag, page)
+			}
+		}
+		if data.Project == nil {
+			if strings.TrimSpace(page) != "" {
+				t.Errorf("page without a project = %q", page)
+			}
+			continue
+		}
+		for _, neighbour := range []*Project{data.PrevProject, data.NextProject} {
+			if neighbour != nil && !strings.Contains(page, `href="/projects/`+neighbour.ID+`"`) {
+				t.Errorf("page for %s does not link to %s", data.Project.ID, neighbour.ID)
+			}
+		}
+	}
+}
@@ -0,0 +1,181 @@
+// Package docx writes richtext documents as Word documents (Office Open
+// XML) without external tools. It supports the small set of features a
+// resume needs: styled paragraphs, bold and italic runs, hyperlinks, bullet
+// lists and a right aligned tab stop for dates.
+package docx
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/daveonthegit/Personal_Portfolio/richtext"
+)
+
+// Page layout in twentieths of a point: US Letter with 0.6in margins
+const (
+	pageWidth  = 12240
+	pageHeight = 15840
+	pageMargin = 864
+	textWidth  = pageWidth - 2*pageMargin
+)
+
+// zipTime is stamped on every part so the same document always produces
+// the same bytes
+var zipTime = time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// Document is a Word document built paragraph by paragraph, in the styles
+// defined in styles.xml
+type Document struct {
+	richtext.Body
+
+	Title  string // Stored in the document properties
+	Author string
+
+	links []string // Hyperlink targets; relationship IDs follow their index
+}
+
+// New returns an empty document
+func New() *Document {
+	return &Document{}
+}
+
+// Bytes returns the document as a .docx file
+func (d *Document) Bytes() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := d.Write(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Write writes the document to w as a .docx file
+func (d *Document) Write(w io.Writer) error {
+	d.links = nil
+	document := d.documentXML()
+
+	parts := []struct {
+		name string
+		data string
+	}{
+		{"[Content_Types].xml", contentTypesXML},
+		{"_rels/.rels", packageRelsXML},
+		{"docProps/core.xml", d.coreXML()},
+		{"docProps/app.xml", appXML},
+		{"word/document.xml", document},
+		{"word/_rels/document.xml.rels", d.documentRelsXML()},
+		{"word/styles.xml", stylesXML},
+		{"word/numbering.xml", numberingXML},
+		{"word/settings.xml", settingsXML},
+	}
+
+	zw := zip.NewWriter(w)
+	for _, part := range parts {
+		f, err := zw.CreateHeader(&zip.FileHeader{Name: part.name, Method: zip.Deflate, Modified: zipTime})
+		if err != nil {
+			return fmt.Errorf("failed to add %s: %v", part.name, err)
+		}
+		if _, err := io.WriteString(f, part.data); err != nil {
+			return fmt.Errorf("failed to write %s: %v", part.name, err)
+		}
+	}
+	return zw.Close()
+}
+
+func (d *Document) documentXML() string {
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	b.WriteString(`<w:document xmlns:w="` + nsMain + `" xmlns:r="` + nsRel + `"><w:body>`)
+	for _, p := range d.Paragraphs {
+		b.WriteString(`<w:p><w:pPr><w:pStyle w:val="` + escape(p.Style) + `"/></w:pPr>`)
+		for _, run := range p.Runs {
+			if run.Link != "" {
+				d.links = append(d.links, run.Link)
+				fmt.Fprintf(&b, `<w:hyperlink r:id="rIdLink%d" w:history="1">`, len(d.links))
+				writeRun(&b, run, "Hyperlink")
+				b.WriteString(`</w:hyperlink>`)
+				continue
+			}
+			writeRun(&b, run, "")
+		}
+		b.WriteString(`</w:p>`)
+	}
+	fmt.Fprintf(&b, `<w:sectPr><w:pgSz w:w="%d" w:h="%d"/><w:pgMar w:top="%d" w:right="%d" w:bottom="%d" w:left="%d" w:header="0" w:footer="0" w:gutter="0"/></w:sectPr>`,
+		pageWidth, pageHeight, pageMargin, pageMargin, pageMargin, pageMargin)
+	b.WriteString(`</w:body></w:document>`)
+	return b.String()
+}
+
+// writeRun writes r, splitting tabs and newlines into their elements
+func writeRun(b *strings.Builder, r richtext.Run, charStyle string) {
+	b.WriteString(`<w:r>`)
+	if r.Bold || r.Italic || charStyle != "" {
+		b.WriteString(`<w:rPr>`)
+		if charStyle != "" {
+			b.WriteString(`<w:rStyle w:val="` + charStyle + `"/>`)
+		}
+		if r.Bold {
+			b.WriteString(`<w:b/>`)
+		}
+		if r.Italic {
+			b.WriteString(`<w:i/>`)
+		}
+		b.WriteString(`</w:rPr>`)
+	}
+
+	var text strings.Builder
+	flush := func() {
+		if text.Len() > 0 {
+			b.WriteString(`<w:t xml:space="preserve">` + escape(text.String()) + `</w:t>`)
+			text.Reset()
+		}
+	}
+	for _, c := range r.Text {
+		switch c {
+		case '\t':
+			flush()
+			b.WriteString(`<w:tab/>`)
+		case '\n':
+			flush()
+			b.WriteString(`<w:br/>`)
+		default:
+			text.WriteRune(c)
+		}
+	}
+	flush()
+	b.WriteString(`</w:r>`)
+}
+
+func (d *Document) documentRelsXML() string {
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	b.WriteString(`<Relationships xmlns="` + nsPackageRels + `">`)
+	b.WriteString(`<Relationship Id="rIdStyles" Type="` + nsRel + `/styles" Target="styles.xml"/>`)
+	b.WriteString(`<Relationship Id="rIdNumbering" Type="` + nsRel + `/numbering" Target="numbering.xml"/>`)
+	b.WriteString(`<Relationship Id="rIdSettings" Type="` + nsRel + `/settings" Target="settings.xml"/>`)
+	for i, link := range d.links {
+		fmt.Fprintf(&b, `<Relationship Id="rIdLink%d" Type="%s/hyperlink" Target="%s" TargetMode="External"/>`, i+1, nsRel, escape(link))
+	}
+	b.WriteString(`</Relationships>`)
+	return b.String()
+}
+
+func (d *Document) coreXML() string {
+	return xml.Header +
+		`<cp:coreProperties xmlns:cp="http://schemas.openxmlformats.org/package/2006/metadata/core-properties" xmlns:dc="http://purl.org/dc/elements/1.1/">` +
+		`<dc:title>` + escape(d.Title) + `</dc:title>` +
+		`<dc:creator>` + escape(d.Author) + `</dc:creator>` +
+		`</cp:coreProperties>`
+}
+
+// escape escapes text for XML character data and attribute values. Control
+// characters XML cannot represent are replaced.
+func escape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
@@ -0,0 +1,237 @@
+package docx
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/daveonthegit/Personal_Portfolio/richtext"
+)
+
+func TestDocumentPackage(t *testing.T) {
+	doc := New()
+	doc.Title = "Jane Doe - Resume"
+	doc.Author = "Jane Doe"
+	doc.Paragraph(richtext.StyleTitle, richtext.Text("Jane Doe"))
+	doc.Paragraph(richtext.StyleSubtitle, richtext.Link("jane@example.com", "mailto:jane@example.com"), richtext.Text(" | "), richtext.Link("example.com", "https://example.com/?a=1&b=2"))
+	doc.Heading(1, richtext.Text("Experience"))
+	doc.Heading(2, richtext.Bold("Engineer"), richtext.Text("\tJan 2024 – Present"))
+	doc.Paragraph(richtext.StyleDetail, richtext.Text("R&D <Lab>\tNew York, NY"))
+	doc.Bullet(richtext.Text("Shipped things"), richtext.Italic(" quickly"))
+	doc.Paragraph(richtext.StyleNormal, richtext.Text("control \x01 characters\nand a break"))
+
+	data, err := doc.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := unzip(t, data)
+
+	for _, name := range []string{
+		"[Content_Types].xml",
+		"_rels/.rels",
+		"word/document.xml",
+		"word/_rels/document.xml.rels",
+		"word/styles.xml",
+		"word/numbering.xml",
+		"docProps/core.xml",
+	} {
+		if _, ok := parts[name]; !ok {
+			t.Errorf("package is missing %s", name)
+		}
+	}
+	for name, content := range parts {
+		if err := wellFormed(content); err != nil {
+			t.Errorf("%s is not well-formed XML: %v", name, err)
+		}
+	}
+
+	// Every XML part except the content types must have a content type
+	var types struct {
+		Defaults []struct {
+			Extension string `xml:"Extension,attr"`
+		} `xml:"Default"`
+		Overrides []struct {
+			PartName    string `xml:"PartName,attr"`
+			ContentType string `xml:"ContentType,attr"`
+		} `xml:"Override"`
+	}
+	if err := xml.Unmarshal(parts["[Content_Types].xml"], &types); err != nil {
+		t.Fatal(err)
+	}
+	overrides := make(map[string]string)
+	for _, o := range types.Overrides {
+		overrides[o.PartName] = o.ContentType
+		if _, ok := parts[strings.TrimPrefix(o.PartName, "/")]; !ok {
+			t.Errorf("content type override for missing part %s", o.PartName)
+		}
+	}
+	if got := overrides["/word/document.xml"]; got != "application/vnd.openxmlformats-officedocument.wordprocessingml.document.main+xml" {
+		t.Errorf("document content type = %q", got)
+	}
+	for name := range parts {
+		if name == "[Content_Types].xml" || strings.HasSuffix(name, ".rels") {
+			continue
+		}
+		if _, ok := overrides["/"+name]; !ok {
+			t.Errorf("no content type override for %s", name)
+		}
+	}
+
+	// Internal relationships must point at parts in the package
+	packageRels := relationships(t, parts["_rels/.rels"])
+	checkTargets(t, parts, "", packageRels)
+	if packageRels["rId1"].Target != "word/document.xml" {
+		t.Errorf("officeDocument relationship targets %q", packageRels["rId1"].Target)
+	}
+	docRels := relationships(t, parts["word/_rels/document.xml.rels"])
+	checkTargets(t, parts, "word", docRels)
+
+	// Every relationship the document references must exist
+	document := string(parts["word/document.xml"])
+	for _, id := range []string{"rIdLink1", "rIdLink2"} {
+		if !strings.Contains(document, `r:id="`+id+`"`) {
+			t.Errorf("document does not reference %s", id)
+		}
+		rel, ok := docRels[id]
+		if !ok {
+			t.Fatalf("missing relationship %s", id)
+		}
+		if rel.TargetMode != "External" {
+			t.Errorf("%s target mode = %q, want External", id, rel.TargetMode)
+		}
+	}
+	if got := docRels["rIdLink2"].Target; got != "https://example.com/?a=1&b=2" {
+		t.Errorf("hyperlink target = %q", got)
+	}
+
+	for _, want := range []string{
+		`<w:pStyle w:val="ListBullet"/>`,
+		`<w:tab/>`,
+		`<w:br/>`,
+		`R&amp;D &lt;Lab&gt;`,
+		`<w:b/>`,
+		`<w:i/>`,
+	} {
+		if !strings.Contains(document, want) {
+			t.Errorf("document.xml is missing %s", want)
+		}
+	}
+	if strings.Contains(document, "\x01") {
+		t.Error("document.xml contains a control character")
+	}
+
+	// Every paragraph style used must be defined
+	styles := string(parts["word/styles.xml"])
+	for _, style := range []string{richtext.StyleNormal, richtext.StyleTitle, richtext.StyleSubtitle, richtext.StyleHeading1, richtext.StyleHeading2, richtext.StyleDetail, richtext.StyleBullet} {
+		if !strings.Contains(styles, `w:styleId="`+style+`"`) {
+			t.Errorf("styles.xml does not define %s", style)
+		}
+	}
+	if !strings.Contains(string(parts["docProps/core.xml"]), "<dc:title>Jane Doe - Resume</dc:title>") {
+		t.Error("core properties are missing the title")
+	}
+}
+
+func TestDocumentDeterministic(t *testing.T) {
+	build := func() []byte {
+		doc := New()
+		doc.Paragraph(richtext.StyleNormal, richtext.Link("a", "https://a.example"))
+		data, err := doc.Bytes()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data
+	}
+	if !bytes.Equal(build(), build()) {
+		t.Error("the same document produced different bytes")
+	}
+
+	// Writing twice must not duplicate hyperlink relationships
+	doc := New()
+	doc.Paragraph(richtext.StyleNormal, richtext.Link("a", "https://a.example"))
+	first, _ := doc.Bytes()
+	second, _ := doc.Bytes()
+	if !bytes.Equal(first, second) {
+		t.Error("writing a document twice produced different bytes")
+	}
+}
+
+func unzip(t *testing.T, data []byte) map[string][]byte {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("not a zip archive: %v", err)
+	}
+	parts := make(map[string][]byte)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("open %s: %v", f.Name, err)
+		}
+		content, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatalf("read %s: %v", f.Name, err)
+		}
+		if _, dup := parts[f.Name]; dup {
+			t.Errorf("duplicate part %s", f.Name)
+		}
+		parts[f.Name] = content
+	}
+	return parts
+}
+
+func wellFormed(data []byte) error {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		_, err := dec.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+type relationship struct {
+	ID         string `xml:"Id,attr"`
+	Target     string `xml:"Target,attr"`
+	TargetMode string `xml:"TargetMode,attr"`
+}
+
+func relationships(t *testing.T, data []byte) map[string]relationship {
+	t.Helper()
+	var rels struct {
+		Relationships []relationship `xml:"Relationship"`
+	}
+	if err := xml.Unmarshal(data, &rels); err != nil {
+		t.Fatal(err)
+	}
+	byID := make(map[string]relationship)
+	for _, rel := range rels.Relationships {
+		if _, dup := byID[rel.ID]; dup {
+			t.Errorf("duplicate relationship id %s", rel.ID)
+		}
+		byID[rel.ID] = rel
+	}
+	return byID
+}
+
+// checkTargets verifies that internal relationships resolve to parts,
+// relative to the directory of the part that owns them
+func checkTargets(t *testing.T, parts map[string][]byte, dir string, rels map[string]relationship) {
+	t.Helper()
+	for id, rel := range rels {
+		if rel.TargetMode == "External" {
+			continue
+		}
+		if _, ok := parts[path.Join(dir, rel.Target)]; !ok {
+			t.Errorf("relationship %s targets missing part %s", id, path.Join(dir, rel.Target))
+		}
+	}
+}
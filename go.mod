@@ -2,10 +2,13 @@ module github.com/daveonthegit/Personal_Portfolio
 
 go 1.21
 
-require github.com/gorilla/mux v1.8.0
-
 require (
-	github.com/joho/godotenv v1.5.1 // indirect
-	gopkg.in/alexcesaro/quotedprintable.v3 v3.0.0-20150716171945-2caba252f4dc // indirect
-	gopkg.in/mail.v2 v2.3.1 // indirect
+	github.com/BurntSushi/toml v1.3.2
+	github.com/gorilla/mux v1.8.0
+	github.com/joho/godotenv v1.5.1
+	golang.org/x/text v0.21.0
+	gopkg.in/mail.v2 v2.3.1
+	gopkg.in/yaml.v3 v3.0.1
 )
+
+require gopkg.in/alexcesaro/quotedprintable.v3 v3.0.0-20150716171945-2caba252f4dc // indirect
@@ -0,0 +1,289 @@
+package latex
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Limits that keep expansion bounded for recursive or exponentially growing
+// macro definitions
+const (
+	maxExpansions     = 10000
+	maxExpandedTokens = 1 << 18
+)
+
+// macro is a user definition made with \newcommand and friends
+type macro struct {
+	nargs      int
+	optDefault []token // Default for the optional first argument, if it has one
+	hasOpt     bool
+	body       []token
+}
+
+// expander records \newcommand, \renewcommand and \providecommand
+// definitions and expands uses of them. Commands in native are left alone
+// because the renderer understands them directly.
+type expander struct {
+	in         tokenReader
+	macros     map[string]macro
+	native     map[string]bool
+	expansions int
+	pushed     int
+}
+
+// expand returns toks with macro definitions removed and macro uses
+// replaced by their bodies
+func expand(toks []token, native map[string]bool) []token {
+	e := &expander{
+		macros: make(map[string]macro),
+		native: native,
+	}
+	e.in.push(toks)
+
+	var out []token
+	for len(out) < maxExpandedTokens {
+		t, ok := e.in.next()
+		if !ok {
+			break
+		}
+		if t.kind != tokCommand {
+			out = append(out, t)
+			continue
+		}
+
+		switch t.text {
+		case "newcommand", "renewcommand", "providecommand":
+			e.define(t.text == "providecommand")
+			continue
+		}
+
+		m, ok := e.macros[t.text]
+		if !ok || e.native[t.text] || !e.withinBudget(len(m.body)) {
+			out = append(out, t)
+			continue
+		}
+		e.in.push(e.substitute(m))
+	}
+
+	return out
+}
+
+func (e *expander) withinBudget(n int) bool {
+	return e.expansions < maxExpansions && e.pushed+n < maxExpandedTokens
+}
+
+// define reads the rest of a definition: the macro name, an optional
+// argument count, an optional default for the first argument and the body
+func (e *expander) define(keepExisting bool) {
+	e.in.skipSpaces()
+	name, ok := e.readMacroName()
+	if !ok {
+		return
+	}
+
+	var m macro
+	if opt, ok := e.readOptional(); ok {
+		if n, err := strconv.Atoi(plainText(opt)); err == nil && n >= 0 && n <= 9 {
+			m.nargs = n
+		}
+		if def, ok := e.readOptional(); ok {
+			m.optDefault = def
+			m.hasOpt = true
+		}
+	}
+	m.body = e.readArgument()
+
+	if _, exists := e.macros[name]; exists && keepExisting {
+		return
+	}
+	e.macros[name] = m
+}
+
+// readMacroName accepts both \newcommand{\name} and \newcommand\name
+func (e *expander) readMacroName() (string, bool) {
+	t, ok := e.in.peek()
+	if !ok {
+		return "", false
+	}
+	if t.kind == tokCommand {
+		e.in.next()
+		return t.text, true
+	}
+	if t.kind != tokOpen {
+		return "", false
+	}
+
+	arg := e.readArgument()
+	for _, t := range arg {
+		if t.kind == tokCommand {
+			return t.text, true
+		}
+	}
+	return "", false
+}
+
+// readArgument reads a braced group, returning its contents, or a single
+// token. Spaces before the argument are skipped.
+func (e *expander) readArgument() []token {
+	e.in.skipSpaces()
+	t, ok := e.in.peek()
+	if !ok || t.kind == tokClose {
+		return nil
+	}
+	e.in.next()
+	if t.kind != tokOpen {
+		return []token{t}
+	}
+
+	var arg []token
+	depth := 1
+	for {
+		t, ok := e.in.next()
+		if !ok {
+			return arg
+		}
+		switch t.kind {
+		case tokOpen:
+			depth++
+		case tokClose:
+			depth--
+			if depth == 0 {
+				return arg
+			}
+		}
+		arg = append(arg, t)
+	}
+}
+
+// readOptional reads a bracketed argument at brace depth zero, if present
+func (e *expander) readOptional() ([]token, bool) {
+	e.in.skipSpaces()
+	t, ok := e.in.peek()
+	if !ok || t.kind != tokOptOpen {
+		return nil, false
+	}
+	e.in.next()
+
+	var arg []token
+	depth := 0
+	for {
+		t, ok := e.in.next()
+		if !ok {
+			return arg, true
+		}
+		switch t.kind {
+		case tokOpen:
+			depth++
+		case tokClose:
+			depth--
+		case tokOptClose:
+			if depth <= 0 {
+				return arg, true
+			}
+		}
+		arg = append(arg, t)
+	}
+}
+
+// substitute reads the arguments of a macro use and returns its body with
+// the parameters replaced
+func (e *expander) substitute(m macro) []token {
+	e.expansions++
+
+	args := make([][]token, m.nargs)
+	first := 0
+	if m.hasOpt && m.nargs > 0 {
+		if opt, ok := e.readOptional(); ok {
+			args[0] = opt
+		} else {
+			args[0] = m.optDefault
+		}
+		first = 1
+	}
+	for i := first; i < m.nargs; i++ {
+		args[i] = e.readArgument()
+	}
+
+	var out []token
+	for _, t := range m.body {
+		if t.kind != tokParam {
+			out = append(out, t)
+			continue
+		}
+		if n := int(t.text[0] - '1'); n < len(args) {
+			out = append(out, args[n]...)
+		}
+		if len(out) > maxExpandedTokens {
+			break
+		}
+	}
+
+	e.pushed += len(out)
+	return out
+}
+
+// plainText concatenates the text tokens in toks
+func plainText(toks []token) string {
+	var b strings.Builder
+	for _, t := range toks {
+		if t.kind == tokText {
+			b.WriteString(t.text)
+		}
+	}
+	return b.String()
+}
+
+// tokenReader reads tokens from a stack of slices so macro bodies can be
+// pushed in front of the remaining input without copying it
+type tokenReader struct {
+	frames []tokenFrame
+}
+
+type tokenFrame struct {
+	toks []token
+	pos  int
+}
+
+func (r *tokenReader) push(toks []token) {
+	r.dropExhausted()
+	if len(toks) > 0 {
+		r.frames = append(r.frames, tokenFrame{toks: toks})
+	}
+}
+
+func (r *tokenReader) dropExhausted() {
+	for len(r.frames) > 0 {
+		top := r.frames[len(r.frames)-1]
+		if top.pos < len(top.toks) {
+			return
+		}
+		r.frames = r.frames[:len(r.frames)-1]
+	}
+}
+
+func (r *tokenReader) peek() (token, bool) {
+	r.dropExhausted()
+	if len(r.frames) == 0 {
+		return token{}, false
+	}
+	top := r.frames[len(r.frames)-1]
+	return top.toks[top.pos], true
+}
+
+func (r *tokenReader) next() (token, bool) {
+	t, ok := r.peek()
+	if ok {
+		r.frames[len(r.frames)-1].pos++
+	}
+	return t, ok
+}
+
+func (r *tokenReader) skipSpaces() {
+	for {
+		t, ok := r.peek()
+		if !ok || t.kind != tokSpace {
+			return
+		}
+		r.next()
+	}
+}
@@ -0,0 +1,514 @@
+// Package latex converts the subset of LaTeX used by the resume into HTML.
+//
+// Source is tokenized, user macros defined with \newcommand are expanded and
+// the result is parsed into a tree, so every HTML element is opened and
+// closed by the same node and the output is always balanced. Text is escaped
+// and links are limited to safe URL schemes.
+package latex
+
+import (
+	"html"
+	"net/url"
+	"strings"
+)
+
+// ToHTML renders the body of the document environment in src as an HTML
+// fragment. Source without a document environment is rendered whole.
+func ToHTML(src string) string {
+	nodes := parse(src)
+	for _, n := range nodes {
+		if n.kind == envNode && n.name == "document" {
+			nodes = n.children
+			break
+		}
+	}
+
+	var b strings.Builder
+	renderBlocks(&b, nodes, true)
+	return b.String()
+}
+
+// Inline formatting commands and the elements they render as
+var inlineTags = map[string]string{
+	"textbf":          "strong",
+	"textit":          "em",
+	"textsl":          "em",
+	"emph":            "em",
+	"underline":       "u",
+	"texttt":          "code",
+	"textsuperscript": "sup",
+	"textsubscript":   "sub",
+}
+
+var headingTags = map[string]string{
+	"section":       "h2",
+	"subsection":    "h3",
+	"subsubsection": "h4",
+}
+
+// symbols maps commands and control symbols to the text they produce
+var symbols = map[string]string{
+	"&":               "&",
+	"%":               "%",
+	"$":               "$",
+	"#":               "#",
+	"_":               "_",
+	"{":               "{",
+	"}":               "}",
+	" ":               " ",
+	",":               " ",
+	"textbackslash":   `\`,
+	"textasciitilde":  "~",
+	"textasciicircum": "^",
+	"textless":        "<",
+	"textgreater":     ">",
+	"textbar":         "|",
+	"textbullet":      "•",
+	"bullet":          "•",
+	"cdot":            "·",
+	"times":           "×",
+	"ldots":           "…",
+	"dots":            "…",
+	"textendash":      "–",
+	"textemdash":      "—",
+	"copyright":       "©",
+	"textregistered":  "®",
+	"quad":            " ",
+	"qquad":           "  ",
+	"LaTeX":           "LaTeX",
+	"TeX":             "TeX",
+}
+
+// textReplacer applies TeX's dash and quote ligatures
+var textReplacer = strings.NewReplacer(
+	"---", "—",
+	"--", "–",
+	"``", "“",
+	"''", "”",
+)
+
+// safeURLSchemes are the link schemes rendered as anchors; other links keep
+// only their text
+var safeURLSchemes = map[string]bool{
+	"http":   true,
+	"https":  true,
+	"mailto": true,
+	"tel":    true,
+}
+
+// isBlock reports whether n renders as a block element
+func isBlock(n node) bool {
+	switch n.kind {
+	case envNode:
+		return true
+	case commandNode:
+		_, heading := headingTags[n.name]
+		return heading || nativeCommands[n.name]
+	}
+	return false
+}
+
+// wrappedContent reports whether n is inline formatting around other
+// nodes, such as \textbf or a group, and where that content is kept: in its
+// children (-1) or in one of its arguments
+func wrappedContent(n node) (int, bool) {
+	switch n.kind {
+	case groupNode, mathNode:
+		return -1, true
+	case commandNode:
+		if _, ok := inlineTags[n.name]; ok {
+			return 0, true
+		}
+		switch n.name {
+		case "textsc", "mbox", "hbox", "vcenter", "textrm", "textsf":
+			return 0, true
+		case "href", "textcolor":
+			return 1, true
+		}
+	}
+	return 0, false
+}
+
+// withContent returns a copy of the wrapper n holding content instead
+func withContent(n node, i int, content []node) node {
+	if i < 0 {
+		n.children = content
+		return n
+	}
+	args := make([][]node, max(len(n.args), i+1))
+	copy(args, n.args)
+	args[i] = content
+	n.args = args
+	return n
+}
+
+// hoistBlocks lifts blocks nested in inline formatting, such as a list
+// inside \textbf, up into nodes. Like browsers do with misnested HTML, the
+// formatting is closed before the block and reopened after it, so a block
+// element never ends up inside a paragraph or an inline element.
+func hoistBlocks(nodes []node) []node {
+	var out []node
+	for _, n := range nodes {
+		i, ok := wrappedContent(n)
+		if !ok {
+			out = append(out, n)
+			continue
+		}
+		content := arg(n, i)
+		if i < 0 {
+			content = n.children
+		}
+		inner := hoistBlocks(content)
+		if !containsBlock(inner) {
+			out = append(out, n)
+			continue
+		}
+
+		var run []node
+		wrap := func() {
+			if !isBlank(run) {
+				out = append(out, withContent(n, i, run))
+			}
+			run = nil
+		}
+		for _, c := range inner {
+			if isBlock(c) {
+				wrap()
+				out = append(out, c)
+				continue
+			}
+			run = append(run, c)
+		}
+		wrap()
+	}
+	return out
+}
+
+func containsBlock(nodes []node) bool {
+	for _, n := range nodes {
+		if isBlock(n) {
+			return true
+		}
+	}
+	return false
+}
+
+// renderBlocks renders a sequence that may mix blocks and running text.
+// Runs of inline nodes between blocks and paragraph breaks are wrapped in
+// <p> when paragraphs is set.
+func renderBlocks(b *strings.Builder, nodes []node, paragraphs bool) {
+	nodes = hoistBlocks(nodes)
+	var run []node
+	flush := func() {
+		var inline strings.Builder
+		renderInline(&inline, run)
+		run = run[:0]
+
+		text := strings.TrimSpace(inline.String())
+		if text == "" {
+			return
+		}
+		if paragraphs {
+			b.WriteString("<p>")
+			b.WriteString(text)
+			b.WriteString("</p>\n")
+		} else {
+			b.WriteString(text)
+		}
+	}
+
+	for _, n := range nodes {
+		switch {
+		case n.kind == parNode:
+			flush()
+		case isBlock(n):
+			flush()
+			renderBlock(b, n)
+		default:
+			run = append(run, n)
+		}
+	}
+	flush()
+}
+
+func renderBlock(b *strings.Builder, n node) {
+	if n.kind == commandNode {
+		if tag, ok := headingTags[n.name]; ok {
+			b.WriteString("<" + tag + ">")
+			renderInline(b, arg(n, 0))
+			b.WriteString("</" + tag + ">\n")
+			return
+		}
+		// Resume entries outside a list still render, just without the <li>
+		renderResumeEntry(b, n)
+		return
+	}
+
+	switch n.name {
+	case "itemize", "enumerate":
+		renderList(b, n)
+	case "tabular", "tabular*", "tabularx":
+		renderTable(b, n)
+	case "center", "flushleft", "flushright":
+		b.WriteString(`<div class="` + n.name + `">`)
+		renderBlocks(b, n.children, true)
+		b.WriteString("</div>\n")
+	default:
+		renderBlocks(b, n.children, true)
+	}
+}
+
+// renderList renders itemize and enumerate. Each \item or resume entry
+// starts a list item that runs until the next one, so nested lists end up
+// inside the item they follow.
+func renderList(b *strings.Builder, n node) {
+	tag := "ul"
+	if n.name == "enumerate" {
+		tag = "ol"
+	}
+	b.WriteString("<" + tag + ">\n")
+
+	var head *node
+	var body []node
+	flush := func() {
+		if head == nil && isBlank(body) {
+			body = body[:0]
+			return
+		}
+		b.WriteString("<li>")
+		if head != nil && head.name != "item" {
+			renderResumeEntry(b, *head)
+		}
+		renderBlocks(b, body, false)
+		b.WriteString("</li>\n")
+		head, body = nil, body[:0]
+	}
+
+	for i, child := range n.children {
+		if child.kind == commandNode && (child.name == "item" || nativeCommands[child.name]) {
+			flush()
+			head = &n.children[i]
+			continue
+		}
+		body = append(body, child)
+	}
+	flush()
+
+	b.WriteString("</" + tag + ">\n")
+}
+
+// renderResumeEntry renders the structural resume macros
+func renderResumeEntry(b *strings.Builder, n node) {
+	switch n.name {
+	case "resumeItem", "resumeSubItem":
+		renderBlocks(b, arg(n, 0), false)
+	case "resumeSubheading":
+		b.WriteString(`<div class="resume-subheading">`)
+		renderRow(b, "strong", arg(n, 0), "span", arg(n, 1))
+		renderRow(b, "em", arg(n, 2), "em", arg(n, 3))
+		b.WriteString("</div>")
+	case "resumeSubSubheading":
+		b.WriteString(`<div class="resume-subheading">`)
+		renderRow(b, "em", arg(n, 0), "em", arg(n, 1))
+		b.WriteString("</div>")
+	case "resumeProjectHeading":
+		b.WriteString(`<div class="resume-project-heading">`)
+		renderRow(b, "span", arg(n, 0), "span", arg(n, 1))
+		b.WriteString("</div>")
+	}
+}
+
+// renderRow renders a left and right aligned pair of cells
+func renderRow(b *strings.Builder, leftTag string, left []node, rightTag string, right []node) {
+	b.WriteString(`<div class="resume-row">`)
+	b.WriteString("<" + leftTag + ">")
+	renderInline(b, left)
+	b.WriteString("</" + leftTag + ">")
+	b.WriteString("<" + rightTag + ` class="resume-right">`)
+	renderInline(b, right)
+	b.WriteString("</" + rightTag + ">")
+	b.WriteString("</div>")
+}
+
+// renderTable renders tabular environments, splitting rows on \\ and cells
+// on &. Column alignment comes from the l, c and r letters of the column
+// specification.
+func renderTable(b *strings.Builder, n node) {
+	var aligns []string
+	for _, spec := range arg(n, len(n.args)-1) {
+		if spec.kind != textNode {
+			continue
+		}
+		for _, c := range spec.text {
+			switch c {
+			case 'l':
+				aligns = append(aligns, "left")
+			case 'c':
+				aligns = append(aligns, "center")
+			case 'r':
+				aligns = append(aligns, "right")
+			case 'p', 'X':
+				aligns = append(aligns, "left")
+			}
+		}
+	}
+
+	var rows [][][]node
+	row := [][]node{nil}
+	for _, child := range n.children {
+		switch {
+		case child.kind == commandNode && child.name == `\`:
+			rows = append(rows, row)
+			row = [][]node{nil}
+		case child.kind == alignNode:
+			row = append(row, nil)
+		default:
+			row[len(row)-1] = append(row[len(row)-1], child)
+		}
+	}
+	if len(row) > 1 || !isBlank(row[0]) {
+		rows = append(rows, row)
+	}
+
+	b.WriteString(`<table class="tabular">` + "\n")
+	for _, cells := range rows {
+		b.WriteString("<tr>")
+		for i, cell := range cells {
+			if i < len(aligns) && aligns[i] != "left" {
+				b.WriteString(`<td style="text-align:` + aligns[i] + `">`)
+			} else {
+				b.WriteString("<td>")
+			}
+			renderBlocks(b, cell, false)
+			b.WriteString("</td>")
+		}
+		b.WriteString("</tr>\n")
+	}
+	b.WriteString("</table>\n")
+}
+
+// renderInline renders running text. renderBlocks has already lifted blocks
+// out of running text, so the only blocks left are inside headings, resume
+// rows and links, which cannot hold them; they render as their text.
+func renderInline(b *strings.Builder, nodes []node) {
+	for _, n := range nodes {
+		switch n.kind {
+		case textNode:
+			b.WriteString(html.EscapeString(textReplacer.Replace(n.text)))
+		case spaceNode, alignNode:
+			b.WriteByte(' ')
+		case parNode:
+			b.WriteString("<br>")
+		case tildeNode:
+			b.WriteString("&nbsp;")
+		case groupNode:
+			renderInline(b, n.children)
+		case mathNode:
+			b.WriteString(`<span class="math">`)
+			renderInline(b, n.children)
+			b.WriteString("</span>")
+		case envNode:
+			renderInline(b, n.children)
+		case commandNode:
+			renderCommand(b, n)
+		}
+	}
+}
+
+func renderCommand(b *strings.Builder, n node) {
+	if tag, ok := inlineTags[n.name]; ok {
+		b.WriteString("<" + tag + ">")
+		renderInline(b, arg(n, 0))
+		b.WriteString("</" + tag + ">")
+		return
+	}
+	if s, ok := symbols[n.name]; ok {
+		b.WriteString(html.EscapeString(s))
+		return
+	}
+	if isBlock(n) {
+		for _, a := range n.args {
+			b.WriteByte(' ')
+			renderInline(b, a)
+		}
+		return
+	}
+
+	switch n.name {
+	case `\`, "newline":
+		b.WriteString("<br>")
+	case "item":
+		b.WriteByte(' ')
+	case "textsc":
+		b.WriteString(`<span class="small-caps">`)
+		renderInline(b, arg(n, 0))
+		b.WriteString("</span>")
+	case "href":
+		renderLink(b, nodeText(arg(n, 0)), arg(n, 1))
+	case "url":
+		target := nodeText(arg(n, 0))
+		renderLink(b, target, []node{{kind: textNode, text: target}})
+	case "mbox", "hbox", "vcenter", "textrm", "textsf":
+		renderInline(b, arg(n, 0))
+	case "textcolor":
+		renderInline(b, arg(n, 1))
+	}
+	// Anything else is layout or preamble and renders nothing
+}
+
+// renderLink renders an anchor, or just the text when the target is not a
+// safe URL
+func renderLink(b *strings.Builder, target string, text []node) {
+	target = strings.TrimSpace(target)
+	u, err := url.Parse(target)
+	if err != nil || target == "" || (u.Scheme != "" && !safeURLSchemes[strings.ToLower(u.Scheme)]) {
+		renderInline(b, text)
+		return
+	}
+	b.WriteString(`<a href="` + html.EscapeString(target) + `">`)
+	renderInline(b, text)
+	b.WriteString("</a>")
+}
+
+// nodeText returns the literal text of nodes, as needed for URLs. Escaped
+// characters such as \% and \# are unescaped; formatting is dropped.
+func nodeText(nodes []node) string {
+	var b strings.Builder
+	var walk func([]node)
+	walk = func(nodes []node) {
+		for _, n := range nodes {
+			switch n.kind {
+			case textNode:
+				b.WriteString(n.text)
+			case tildeNode:
+				b.WriteByte('~')
+			case groupNode, mathNode:
+				walk(n.children)
+			case commandNode:
+				if s, ok := symbols[n.name]; ok {
+					b.WriteString(s)
+				}
+			}
+		}
+	}
+	walk(nodes)
+	return b.String()
+}
+
+// arg returns argument i of n, or nil if it is missing
+func arg(n node, i int) []node {
+	if i < 0 || i >= len(n.args) {
+		return nil
+	}
+	return n.args[i]
+}
+
+// isBlank reports whether nodes contain nothing but whitespace
+func isBlank(nodes []node) bool {
+	for _, n := range nodes {
+		if n.kind != spaceNode && n.kind != parNode {
+			return false
+		}
+	}
+	return true
+}
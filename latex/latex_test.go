@@ -0,0 +1,240 @@
+package latex
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestToHTML(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{
+			name: "nested formatting",
+			src:  `\textbf{Bold \textit{and italic}} then \underline{under}`,
+			want: "<p><strong>Bold <em>and italic</em></strong> then <u>under</u></p>\n",
+		},
+		{
+			name: "href",
+			src:  `\href{mailto:me@example.com}{\underline{me@example.com}}`,
+			want: `<p><a href="mailto:me@example.com"><u>me@example.com</u></a></p>` + "\n",
+		},
+		{
+			name: "unsafe href keeps text only",
+			src:  `\href{javascript:alert(1)}{click}`,
+			want: "<p>click</p>\n",
+		},
+		{
+			name: "escaping",
+			src:  `<script> \& 25\% \$4K \textless{}b\textgreater{} "q"`,
+			want: "<p>&lt;script&gt; &amp; 25% $4K &lt;b&gt; &#34;q&#34;</p>\n",
+		},
+		{
+			name: "ligatures and comments",
+			src:  "May 2024 -- Present % comment\n",
+			want: "<p>May 2024 – Present</p>\n",
+		},
+		{
+			name: "sections and paragraphs",
+			src:  "\\section{Summary}\nFirst.\n\nSecond.",
+			want: "<h2>Summary</h2>\n<p>First.</p>\n<p>Second.</p>\n",
+		},
+		{
+			name: "only the document body",
+			src:  "\\documentclass{article}\\usepackage[empty]{fullpage}\n\\begin{document}Body\\end{document}",
+			want: "<p>Body</p>\n",
+		},
+		{
+			name: "macro expansion",
+			src:  `\newcommand{\hi}[2]{Hello #1 and #2}\newcommand\x{X}\hi{\x}{\textbf{B}}`,
+			want: "<p>Hello X and <strong>B</strong></p>\n",
+		},
+		{
+			name: "macro with optional argument",
+			src:  `\newcommand{\greet}[2][World]{#1, #2}\greet{a} \greet[You]{b}`,
+			want: "<p>World, a You, b</p>\n",
+		},
+		{
+			name: "macros opening and closing an environment",
+			src:  `\newcommand{\ListStart}{\begin{itemize}}\newcommand{\ListEnd}{\end{itemize}}\ListStart\item One\item Two\ListEnd`,
+			want: "<ul>\n<li>One</li>\n<li>Two</li>\n</ul>\n",
+		},
+		{
+			name: "tabular",
+			src:  `\begin{tabular*}{0.97\textwidth}[t]{l@{\extracolsep{\fill}}r}\textbf{A} & B \\ C & D \\\end{tabular*}`,
+			want: "<table class=\"tabular\">\n<tr><td><strong>A</strong></td><td style=\"text-align:right\">B</td></tr>\n<tr><td>C</td><td style=\"text-align:right\">D</td></tr>\n</table>\n",
+		},
+		{
+			name: "resume entries",
+			src: `\begin{itemize}
+  \resumeSubheading{Engineer}{2025}{Company}{New York}
+  \begin{itemize}\resumeItem{Built things}\end{itemize}
+\end{itemize}`,
+			want: "<ul>\n<li><div class=\"resume-subheading\"><div class=\"resume-row\"><strong>Engineer</strong><span class=\"resume-right\">2025</span></div><div class=\"resume-row\"><em>Company</em><em class=\"resume-right\">New York</em></div></div><ul>\n<li>Built things</li>\n</ul>\n</li>\n</ul>\n",
+		},
+		{
+			name: "unbalanced input",
+			src:  `\textbf{open \begin{itemize}\item x } \end{center} }`,
+			want: "<p><strong>open </strong></p>\n<ul>\n<li>x</li>\n</ul>\n",
+		},
+		{
+			name: "block inside inline formatting",
+			src:  `Intro \textbf{bold \emph{both \begin{itemize}\item x\end{itemize} after} tail} end`,
+			want: "<p>Intro <strong>bold <em>both </em></strong></p>\n<ul>\n<li>x</li>\n</ul>\n<p><strong><em> after</em> tail</strong> end</p>\n",
+		},
+		{
+			name: "block inside a resume item",
+			src:  `\begin{itemize}\resumeItem{Led \textbf{a \begin{itemize}\item b\end{itemize}}}\end{itemize}`,
+			want: "<ul>\n<li>Led <strong>a </strong><ul>\n<li>b</li>\n</ul>\n</li>\n</ul>\n",
+		},
+		{
+			name: "block inside a heading",
+			src:  `\section{A \begin{itemize}\item b\item c\end{itemize}}`,
+			want: "<h2>A  b c</h2>\n",
+		},
+		{
+			name: "recursive macro terminates",
+			src:  `\newcommand\loop{a\loop}\loop`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ToHTML(tt.src)
+			if tt.want != "" && got != tt.want {
+				t.Errorf("ToHTML(%q)\n got: %q\nwant: %q", tt.src, got, tt.want)
+			}
+			if err := checkBalanced(got); err != nil {
+				t.Errorf("ToHTML(%q) is not balanced: %v\n%s", tt.src, err, got)
+			}
+		})
+	}
+}
+
+func TestToHTMLResume(t *testing.T) {
+	// A hand-written resume in the same template the server generates
+	src, err := os.ReadFile("testdata/resume.tex")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := ToHTML(string(src))
+	if err := checkBalanced(got); err != nil {
+		t.Fatalf("resume HTML is not balanced: %v", err)
+	}
+	for _, want := range []string{"<h2>Work Experience</h2>", `class="resume-subheading"`, `<a href="mailto:`, "<li>"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("resume HTML is missing %q", want)
+		}
+	}
+	for _, unwanted := range []string{`\`, "#1", "usepackage"} {
+		if strings.Contains(got, unwanted) {
+			t.Errorf("resume HTML contains %q", unwanted)
+		}
+	}
+}
+
+func FuzzToHTML(f *testing.F) {
+	seeds := []string{
+		`\textbf{a}\textit{b}\underline{c}\href{http://x}{y}`,
+		`\begin{itemize}\item a\begin{enumerate}\item b\end{enumerate}\end{itemize}`,
+		`\begin{tabular}{lr}a & b \\ c & d\end{tabular}`,
+		`\newcommand{\m}[1]{<#1>}\m{x}\m\m`,
+		`\newcommand\a{\a\a}\a`,
+		`{{{[}]}}}$$\end{x}\begin{y}`,
+		`\resumeSubheading{a}{b}{c}{d}\resumeItem{e}\resumeProjectHeading{f}{g}`,
+		"% comment\n\n\\section*{S}~--``''",
+	}
+	if src, err := os.ReadFile("testdata/resume.tex"); err == nil {
+		seeds = append(seeds, string(src))
+	}
+	for _, s := range seeds {
+		f.Add(s)
+	}
+
+	f.Fuzz(func(t *testing.T, src string) {
+		got := ToHTML(src)
+		if err := checkBalanced(got); err != nil {
+			t.Fatalf("ToHTML(%q) is not balanced: %v\n%s", src, err, got)
+		}
+		if utf8.ValidString(src) && !utf8.ValidString(got) {
+			t.Fatalf("ToHTML(%q) produced invalid UTF-8", src)
+		}
+	})
+}
+
+var (
+	tagPattern = regexp.MustCompile(`<(/?)([a-z0-9]+)((?:\s+[a-z-]+="[^"<>]*")*)\s*>`)
+
+	// allowedTags are the elements the renderer may emit; anything else
+	// means input text leaked through unescaped
+	allowedTags = map[string]bool{
+		"p": true, "br": true, "h2": true, "h3": true, "h4": true,
+		"strong": true, "em": true, "u": true, "code": true, "sup": true, "sub": true,
+		"span": true, "a": true, "div": true, "ul": true, "ol": true, "li": true,
+		"table": true, "tr": true, "td": true,
+	}
+	voidTags = map[string]bool{"br": true}
+
+	// blockTags may not appear inside a phrasingTags element
+	blockTags = map[string]bool{
+		"p": true, "h2": true, "h3": true, "h4": true, "div": true,
+		"ul": true, "ol": true, "li": true, "table": true, "tr": true, "td": true,
+	}
+	phrasingTags = map[string]bool{
+		"p": true, "h2": true, "h3": true, "h4": true,
+		"strong": true, "em": true, "u": true, "code": true, "sup": true, "sub": true,
+		"span": true, "a": true,
+	}
+)
+
+// checkBalanced verifies that every tag in s is known and properly nested,
+// that no block element is inside a paragraph, heading or inline element,
+// and that no stray '<' or '>' remains outside tags
+func checkBalanced(s string) error {
+	var stack []string
+	pos := 0
+	for _, m := range tagPattern.FindAllStringSubmatchIndex(s, -1) {
+		if strings.ContainsAny(s[pos:m[0]], "<>") {
+			return fmt.Errorf("unescaped angle bracket in %q", s[pos:m[0]])
+		}
+		pos = m[1]
+
+		closing := s[m[2]:m[3]] == "/"
+		name := s[m[4]:m[5]]
+		if !allowedTags[name] {
+			return fmt.Errorf("unexpected tag <%s>", name)
+		}
+		if voidTags[name] {
+			continue
+		}
+		if !closing {
+			if blockTags[name] {
+				for _, open := range stack {
+					if phrasingTags[open] {
+						return fmt.Errorf("block <%s> inside <%s>, open tags %v", name, open, stack)
+					}
+				}
+			}
+			stack = append(stack, name)
+			continue
+		}
+		if len(stack) == 0 || stack[len(stack)-1] != name {
+			return fmt.Errorf("unexpected </%s> with open tags %v", name, stack)
+		}
+		stack = stack[:len(stack)-1]
+	}
+	if strings.ContainsAny(s[pos:], "<>") {
+		return fmt.Errorf("unescaped angle bracket in %q", s[pos:])
+	}
+	if len(stack) > 0 {
+		return fmt.Errorf("unclosed tags %v", stack)
+	}
+	return nil
+}
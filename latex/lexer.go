@@ -0,0 +1,147 @@
+package latex
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+type tokenKind int
+
+const (
+	tokText     tokenKind = iota // Run of ordinary characters
+	tokSpace                     // Whitespace within a paragraph
+	tokPar                       // Blank line
+	tokCommand                   // \name or a control symbol such as \\ or \&
+	tokOpen                      // {
+	tokClose                     // }
+	tokOptOpen                   // [
+	tokOptClose                  // ]
+	tokAlign                     // &
+	tokMath                      // $
+	tokTilde                     // ~
+	tokParam                     // #1 .. #9 inside macro bodies
+)
+
+// token is a lexical unit. text holds the characters of a text run, the
+// name of a command (without the backslash) or the digit of a parameter.
+type token struct {
+	kind tokenKind
+	text string
+}
+
+// tokenize splits LaTeX source into tokens. Comments are dropped and, as in
+// TeX, spaces after a control word are skipped.
+func tokenize(src string) []token {
+	var toks []token
+	i := 0
+
+	for i < len(src) {
+		c := src[i]
+		switch {
+		case c == '\\':
+			i++
+			if i == len(src) {
+				break
+			}
+			if isLetter(src[i]) {
+				start := i
+				for i < len(src) && isLetter(src[i]) {
+					i++
+				}
+				toks = append(toks, token{kind: tokCommand, text: src[start:i]})
+				i = skipSpacesAfterCommand(src, i)
+				continue
+			}
+			// Control symbol: a single character, which may be multi-byte
+			_, size := utf8.DecodeRuneInString(src[i:])
+			toks = append(toks, token{kind: tokCommand, text: src[i : i+size]})
+			i += size
+
+		case c == '%':
+			// Comments run to the end of the line and swallow the leading
+			// whitespace of the next one
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+			if i < len(src) {
+				i++
+			}
+			for i < len(src) && (src[i] == ' ' || src[i] == '\t') {
+				i++
+			}
+
+		case isSpace(c):
+			start := i
+			for i < len(src) && isSpace(src[i]) {
+				i++
+			}
+			if strings.Count(src[start:i], "\n") >= 2 {
+				toks = append(toks, token{kind: tokPar})
+			} else {
+				toks = append(toks, token{kind: tokSpace})
+			}
+
+		case c == '#' && i+1 < len(src) && src[i+1] >= '1' && src[i+1] <= '9':
+			toks = append(toks, token{kind: tokParam, text: src[i+1 : i+2]})
+			i += 2
+
+		default:
+			if kind, ok := specialTokens[c]; ok {
+				toks = append(toks, token{kind: kind})
+				i++
+				continue
+			}
+			start := i
+			for i < len(src) && !isSpecial(src[i]) {
+				i++
+			}
+			if i == start {
+				// A lone '#' not followed by a digit
+				i++
+			}
+			toks = append(toks, token{kind: tokText, text: src[start:i]})
+		}
+	}
+
+	return toks
+}
+
+var specialTokens = map[byte]tokenKind{
+	'{': tokOpen,
+	'}': tokClose,
+	'[': tokOptOpen,
+	']': tokOptClose,
+	'&': tokAlign,
+	'$': tokMath,
+	'~': tokTilde,
+}
+
+func isSpecial(c byte) bool {
+	_, ok := specialTokens[c]
+	return ok || c == '\\' || c == '%' || c == '#' || isSpace(c)
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+// skipSpacesAfterCommand skips the whitespace after a control word, stopping
+// before a blank line so paragraph breaks survive
+func skipSpacesAfterCommand(src string, i int) int {
+	newlines := 0
+	j := i
+	for j < len(src) && isSpace(src[j]) {
+		if src[j] == '\n' {
+			newlines++
+			if newlines == 2 {
+				return i
+			}
+		}
+		j++
+	}
+	return j
+}
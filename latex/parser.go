@@ -0,0 +1,449 @@
+package latex
+
+import "strings"
+
+// maxNesting bounds how deeply groups, environments and arguments may nest;
+// anything deeper is dropped rather than risking unbounded recursion
+const maxNesting = 100
+
+type nodeKind int
+
+const (
+	textNode    nodeKind = iota
+	spaceNode            // Inter-word space
+	parNode              // Paragraph break
+	groupNode            // {...}
+	commandNode          // \name with its arguments
+	envNode              // \begin{name} ... \end{name}
+	mathNode             // $...$
+	alignNode            // & column separator
+	tildeNode            // ~ non-breaking space
+)
+
+// node is an element of the parse tree. name holds the command or
+// environment name; children holds the contents of groups, environments and
+// math; args holds command and environment arguments in the order given by
+// their argSpec, with optional arguments that were absent left nil.
+type node struct {
+	kind     nodeKind
+	text     string
+	name     string
+	args     [][]node
+	children []node
+}
+
+// argSpec describes the arguments a command takes: one '{' per mandatory
+// argument and one '[' per optional argument, in order. star marks commands
+// with a starred form such as \section*.
+type argSpec struct {
+	args string
+	star bool
+}
+
+// commandSpecs lists the arguments of the commands the parser knows about.
+// Unknown commands take no arguments; any groups after them are parsed as
+// ordinary groups so their contents still render.
+var commandSpecs = map[string]argSpec{
+	"textbf":               {args: "{"},
+	"textit":               {args: "{"},
+	"textsl":               {args: "{"},
+	"emph":                 {args: "{"},
+	"underline":            {args: "{"},
+	"texttt":               {args: "{"},
+	"textsc":               {args: "{"},
+	"textrm":               {args: "{"},
+	"textsf":               {args: "{"},
+	"textsuperscript":      {args: "{"},
+	"textsubscript":        {args: "{"},
+	"mbox":                 {args: "{"},
+	"hbox":                 {args: "{"},
+	"vcenter":              {args: "{"},
+	"textcolor":            {args: "{{"},
+	"href":                 {args: "{{"},
+	"url":                  {args: "{"},
+	"section":              {args: "{", star: true},
+	"subsection":           {args: "{", star: true},
+	"subsubsection":        {args: "{", star: true},
+	"item":                 {args: "["},
+	"resumeItem":           {args: "{"},
+	"resumeSubItem":        {args: "{"},
+	"resumeSubheading":     {args: "{{{{"},
+	"resumeSubSubheading":  {args: "{{"},
+	"resumeProjectHeading": {args: "{{"},
+	"vspace":               {args: "{", star: true},
+	"hspace":               {args: "{", star: true},
+	"documentclass":        {args: "[{"},
+	"usepackage":           {args: "[{"},
+	"input":                {args: "{"},
+	"include":              {args: "{"},
+	"setlength":            {args: "{{"},
+	"addtolength":          {args: "{{"},
+	"pagestyle":            {args: "{"},
+	"thispagestyle":        {args: "{"},
+	"fancyhf":              {args: "{"},
+	"fancyhead":            {args: "[{"},
+	"fancyfoot":            {args: "[{"},
+	"urlstyle":             {args: "{"},
+	"titleformat":          {args: "{[{{{{["},
+	"titlespacing":         {args: "{{{{", star: true},
+	"color":                {args: "{"},
+	"extracolsep":          {args: "{"},
+	"label":                {args: "{"},
+}
+
+// envSpecs lists the arguments that follow \begin{name}
+var envSpecs = map[string]argSpec{
+	"itemize":   {args: "["},
+	"enumerate": {args: "["},
+	"tabular":   {args: "[{"},
+	"tabular*":  {args: "{[{"},
+	"tabularx":  {args: "{[{"},
+	"minipage":  {args: "[{"},
+}
+
+// nativeCommands are resume macros the renderer handles itself. Their
+// \newcommand definitions are recorded but not expanded so the HTML keeps
+// their structure instead of the tables they expand to.
+var nativeCommands = map[string]bool{
+	"resumeItem":           true,
+	"resumeSubItem":        true,
+	"resumeSubheading":     true,
+	"resumeSubSubheading":  true,
+	"resumeProjectHeading": true,
+}
+
+type contextKind int
+
+const (
+	ctxGroup contextKind = iota
+	ctxOptional
+	ctxMath
+	ctxEnv
+)
+
+type context struct {
+	kind contextKind
+	env  string
+}
+
+// parser builds a tree from expanded tokens. It never fails: unbalanced
+// braces and environments are closed implicitly and stray closers dropped.
+type parser struct {
+	toks []token
+	pos  int
+	ctx  []context // Open constructs, innermost last
+	args int       // Nesting of single-token arguments such as \textbf\textbf x
+}
+
+// parse tokenizes, expands and parses src
+func parse(src string) []node {
+	p := &parser{toks: expand(tokenize(src), nativeCommands)}
+	return p.parseNodes()
+}
+
+// parseNodes parses until a token that closes one of the open contexts
+func (p *parser) parseNodes() []node {
+	var nodes []node
+	for p.pos < len(p.toks) && !p.isCloser(p.pos) {
+		if n, ok := p.parseOne(); ok {
+			nodes = append(nodes, n)
+		}
+	}
+	return nodes
+}
+
+// isCloser reports whether the token at pos ends an open context. A closing
+// brace or \end also closes any contexts nested inside the one it matches.
+func (p *parser) isCloser(pos int) bool {
+	t := p.toks[pos]
+	switch t.kind {
+	case tokClose:
+		for _, c := range p.ctx {
+			if c.kind == ctxGroup {
+				return true
+			}
+		}
+	case tokOptClose:
+		return len(p.ctx) > 0 && p.ctx[len(p.ctx)-1].kind == ctxOptional
+	case tokMath:
+		return len(p.ctx) > 0 && p.ctx[len(p.ctx)-1].kind == ctxMath
+	case tokCommand:
+		if t.text != "end" {
+			return false
+		}
+		name, _, ok := p.envNameAt(pos + 1)
+		if !ok {
+			return false
+		}
+		for _, c := range p.ctx {
+			if c.kind == ctxEnv && c.env == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// parseOne parses the node starting at the current token. It returns false
+// when the tokens produce no node, such as a stray \end.
+func (p *parser) parseOne() (node, bool) {
+	t := p.toks[p.pos]
+	p.pos++
+
+	switch t.kind {
+	case tokText:
+		return node{kind: textNode, text: t.text}, true
+	case tokSpace:
+		return node{kind: spaceNode}, true
+	case tokPar:
+		return node{kind: parNode}, true
+	case tokOptOpen:
+		return node{kind: textNode, text: "["}, true
+	case tokOptClose:
+		return node{kind: textNode, text: "]"}, true
+	case tokAlign:
+		return node{kind: alignNode}, true
+	case tokTilde:
+		return node{kind: tildeNode}, true
+	case tokParam:
+		// Parameters outside a macro body have nothing to refer to
+		return node{}, false
+	case tokClose:
+		// A stray brace with no open group
+		return node{}, false
+	case tokOpen:
+		return node{kind: groupNode, children: p.parseGroup()}, true
+	case tokMath:
+		if len(p.ctx) >= maxNesting {
+			return node{kind: textNode, text: "$"}, true
+		}
+		p.ctx = append(p.ctx, context{kind: ctxMath})
+		children := p.parseNodes()
+		p.ctx = p.ctx[:len(p.ctx)-1]
+		if p.pos < len(p.toks) && p.toks[p.pos].kind == tokMath {
+			p.pos++
+		}
+		return node{kind: mathNode, children: children}, true
+	}
+
+	switch t.text {
+	case "begin":
+		return p.parseEnv()
+	case "end":
+		// An \end that matches no open environment
+		if _, end, ok := p.envNameAt(p.pos); ok {
+			p.pos = end
+		}
+		return node{}, false
+	}
+
+	n := node{kind: commandNode, name: t.text}
+	spec := commandSpecs[t.text]
+	if spec.star {
+		p.skipStar()
+	}
+	n.args = p.parseArgs(spec.args)
+	return n, true
+}
+
+// parseGroup parses the contents of a group whose opening brace has been
+// consumed, along with its closing brace
+func (p *parser) parseGroup() []node {
+	if len(p.ctx) >= maxNesting {
+		p.skipBalanced()
+		return nil
+	}
+
+	p.ctx = append(p.ctx, context{kind: ctxGroup})
+	children := p.parseNodes()
+	p.ctx = p.ctx[:len(p.ctx)-1]
+
+	// The group may also have been ended by the \end of an enclosing
+	// environment, in which case that token is left for it
+	if p.pos < len(p.toks) && p.toks[p.pos].kind == tokClose {
+		p.pos++
+	}
+	return children
+}
+
+// skipBalanced skips the rest of a group without parsing it
+func (p *parser) skipBalanced() {
+	depth := 1
+	for p.pos < len(p.toks) {
+		switch p.toks[p.pos].kind {
+		case tokOpen:
+			depth++
+		case tokClose:
+			depth--
+		}
+		p.pos++
+		if depth == 0 {
+			return
+		}
+	}
+}
+
+// parseArgs reads arguments described by spec
+func (p *parser) parseArgs(spec string) [][]node {
+	if spec == "" {
+		return nil
+	}
+	args := make([][]node, len(spec))
+	for i, kind := range spec {
+		if kind == '[' {
+			args[i] = p.parseOptional()
+		} else {
+			args[i] = p.parseArgument()
+		}
+	}
+	return args
+}
+
+// parseArgument reads a mandatory argument: a group, or a single token as
+// TeX does for \textbf x
+func (p *parser) parseArgument() []node {
+	p.skipSpaces()
+	if p.pos >= len(p.toks) || p.isCloser(p.pos) {
+		return nil
+	}
+
+	t := p.toks[p.pos]
+	switch t.kind {
+	case tokOpen:
+		p.pos++
+		return p.parseGroup()
+	case tokText:
+		// Only the first character of a text run is the argument
+		first, rest := splitFirstRune(t.text)
+		if rest != "" {
+			p.toks[p.pos].text = rest
+		} else {
+			p.pos++
+		}
+		return []node{{kind: textNode, text: first}}
+	case tokPar:
+		return nil
+	}
+
+	if len(p.ctx)+p.args >= maxNesting {
+		return nil
+	}
+	p.args++
+	defer func() { p.args-- }()
+	if n, ok := p.parseOne(); ok {
+		return []node{n}
+	}
+	return nil
+}
+
+// parseOptional reads an optional [argument], returning nil if absent
+func (p *parser) parseOptional() []node {
+	save := p.pos
+	p.skipSpaces()
+	if p.pos >= len(p.toks) || p.toks[p.pos].kind != tokOptOpen || len(p.ctx) >= maxNesting {
+		p.pos = save
+		return nil
+	}
+	p.pos++
+
+	p.ctx = append(p.ctx, context{kind: ctxOptional})
+	children := p.parseNodes()
+	p.ctx = p.ctx[:len(p.ctx)-1]
+
+	if p.pos < len(p.toks) && p.toks[p.pos].kind == tokOptClose {
+		p.pos++
+	}
+	if children == nil {
+		// Present but empty, which is distinct from absent
+		children = []node{}
+	}
+	return children
+}
+
+// parseEnv parses an environment after its \begin
+func (p *parser) parseEnv() (node, bool) {
+	name, end, ok := p.envNameAt(p.pos)
+	if !ok {
+		return node{}, false
+	}
+	p.pos = end
+
+	if len(p.ctx) >= maxNesting {
+		// Too deep: drop the \begin and let the body flow into the parent
+		return node{}, false
+	}
+
+	n := node{kind: envNode, name: name}
+	n.args = p.parseArgs(envSpecs[name].args)
+
+	p.ctx = append(p.ctx, context{kind: ctxEnv, env: name})
+	n.children = p.parseNodes()
+	p.ctx = p.ctx[:len(p.ctx)-1]
+
+	if p.pos < len(p.toks) && p.toks[p.pos].kind == tokCommand && p.toks[p.pos].text == "end" {
+		if endName, next, ok := p.envNameAt(p.pos + 1); ok && endName == name {
+			p.pos = next
+		}
+	}
+	return n, true
+}
+
+// envNameAt reads an environment name group such as {tabular*} starting at
+// pos, skipping leading spaces. It returns the name and the position after
+// the closing brace.
+func (p *parser) envNameAt(pos int) (string, int, bool) {
+	for pos < len(p.toks) && p.toks[pos].kind == tokSpace {
+		pos++
+	}
+	if pos >= len(p.toks) || p.toks[pos].kind != tokOpen {
+		return "", 0, false
+	}
+	pos++
+
+	var name strings.Builder
+	for ; pos < len(p.toks); pos++ {
+		t := p.toks[pos]
+		switch t.kind {
+		case tokClose:
+			if name.Len() == 0 {
+				return "", 0, false
+			}
+			return name.String(), pos + 1, true
+		case tokText:
+			name.WriteString(t.text)
+		case tokSpace:
+		default:
+			return "", 0, false
+		}
+	}
+	return "", 0, false
+}
+
+func (p *parser) skipSpaces() {
+	for p.pos < len(p.toks) && p.toks[p.pos].kind == tokSpace {
+		p.pos++
+	}
+}
+
+// skipStar consumes the * of a starred command
+func (p *parser) skipStar() {
+	if p.pos >= len(p.toks) || p.toks[p.pos].kind != tokText {
+		return
+	}
+	if rest, ok := strings.CutPrefix(p.toks[p.pos].text, "*"); ok {
+		if rest == "" {
+			p.pos++
+		} else {
+			p.toks[p.pos].text = rest
+		}
+	}
+}
+
+func splitFirstRune(s string) (string, string) {
+	for i := range s {
+		if i > 0 {
+			return s[:i], s[i:]
+		}
+	}
+	return s, ""
+}
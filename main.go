@@ -3,24 +3,40 @@ package main
 import (
 	"encoding/json"
 	"fmt"
-	"html/template"
+	"html"
 	"log"
+	"mime"
 	"net/http"
 	"os"
-	"os/exec"
+	"path/filepath"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/daveonthegit/Personal_Portfolio/config"
+	"github.com/daveonthegit/Personal_Portfolio/latex"
 	"github.com/joho/godotenv"
 
 	"github.com/gorilla/mux"
-	"gopkg.in/mail.v2"
 )
 
 type Server struct {
-	templates   *template.Template
-	projects    []Project
+	current     atomic.Pointer[siteContent]
+	contentDir  string
+	devMode     bool
+	reloadMu    sync.RWMutex
+	reloadErr   error
+	builds      buildGroup // Deduplicates concurrent resume HTML builds
+	cache       *artifactCache
+	resumes     *ResumeBuilder
+	contacts    *ContactStore
+	outbox      *Outbox
+	formTokens  *formTokenSigner
+	pow         *powIssuer
+	spam        SpamChain
+	rejections  *rateLimiter // Rejected submissions stored per rateWindow
+	flashes     *flashStore
 	emailConfig EmailConfig
 }
 
@@ -38,27 +54,44 @@ type ContactForm struct {
 	Email   string `json:"email"`
 	Subject string `json:"subject"`
 	Message string `json:"message"`
+
+	// Spam checks: a honeypot field that people leave empty, the token the
+	// form was rendered with and a solved proof-of-work challenge
+	Website   string `json:"website,omitempty"`
+	FormToken string `json:"form_token,omitempty"`
+	Challenge string `json:"challenge,omitempty"`
+	Solution  string `json:"solution,omitempty"`
 }
 
 type PageData struct {
 	Title        string
 	Description  string
 	Projects     []Project
+	Project      *Project // Set on single project pages
+	PrevProject  *Project
+	NextProject  *Project
+	Path         string
 	Personal     config.PersonalInfo
 	Year         int
 	TemplateName string
 	Timestamp    int64
+	FormToken    string        // Signed render time for the contact form
+	ContactKey   string        // Idempotency key for posting the contact form without JavaScript
+	Challenge    string        // Question challenge for posting the contact form without JavaScript, if offered
+	Question     string        // The question it asks
+	Flash        *contactFlash // Outcome of a contact form post without JavaScript
 }
 
 func NewServer() *Server {
-	// Parse all templates
-	templates, err := template.ParseGlob("templates/*.html")
-	if err != nil {
-		log.Fatal("Error parsing templates:", err)
-	}
+	contentDir := getEnv("CONTENT_DIR", "content")
+	devMode := isDevMode()
 
-	// Load projects data
-	projects := LoadProjects()
+	// Parse templates and load projects and personal info. Invalid content
+	// falls back to the built-in data; only template errors are fatal here.
+	content, contentErr := loadSiteContent(contentDir)
+	if content == nil {
+		log.Fatal("Error parsing templates:", contentErr)
+	}
 
 	// Initialize email configuration from environment variables
 	emailConfig := EmailConfig{
@@ -75,20 +108,68 @@ func NewServer() *Server {
 		emailConfig.SMTPHost, emailConfig.SMTPPort,
 		emailConfig.Username, emailConfig.FromEmail, emailConfig.ToEmail)
 
-	if emailConfig.Username == "" || emailConfig.Password == "" || emailConfig.ToEmail == "" {
+	if !emailConfig.configured() {
 		log.Printf("⚠️  Email configuration incomplete - please check your .env file")
 		log.Printf("Required: SMTP_USERNAME, SMTP_PASSWORD, TO_EMAIL")
+		log.Printf("Contact submissions will be stored and queued until it is complete")
 	}
 
-	return &Server{
-		templates:   templates,
-		projects:    projects,
+	contactsPath := getEnv("CONTACT_STORE_FILE", ".data/contact.jsonl")
+	contacts, err := OpenContactStore(contactsPath)
+	if err != nil {
+		log.Fatalf("Error opening contact store %s: %v", contactsPath, err)
+	}
+
+	outbox := NewOutbox(contacts, emailConfig, outboxOptionsFromEnv())
+	if reply, err := autoReplierFromEnv(emailConfig); err != nil {
+		log.Printf("⚠️  Contact auto-reply disabled: %v", err)
+	} else {
+		outbox.autoReplier = reply
+	}
+
+	formTokens := newFormTokenSigner()
+	pow := newPowIssuer(formTokens, getEnvInt("CONTACT_POW_DIFFICULTY", defaultPowDifficulty))
+
+	cache := newArtifactCache(getEnv("RESUME_CACHE_DIR", ".cache/resume"))
+	server := &Server{
+		contentDir:  contentDir,
+		devMode:     devMode,
+		cache:       cache,
+		resumes:     NewResumeBuilder(cache, compileOptionsFromEnv()),
+		contacts:    contacts,
+		outbox:      outbox,
+		formTokens:  formTokens,
+		pow:         pow,
+		spam:        spamChainFromEnv(formTokens, pow),
+		rejections:  newRateLimiter(maxStoredRejections, rateWindow),
+		flashes:     newFlashStore(),
 		emailConfig: emailConfig,
 	}
+	server.current.Store(content)
+
+	// Build the resume PDFs in the background, now and whenever they change
+	server.resumes.AddTarget(defaultResumeTarget, server.prepareResumeSource)
+	server.syncResumeTargets()
+	go server.resumes.Run()
+
+	// Email queued contact submissions in the background
+	if emailConfig.configured() {
+		go server.outbox.Run()
+	}
+
+	if devMode {
+		// Surface startup content errors in the overlay too
+		server.setReloadError(contentErr)
+		log.Printf("🛠️  Dev mode: watching templates/ and %s for changes", contentDir)
+		go server.watchContent()
+	}
+
+	return server
 }
 
 func (s *Server) terminalHandler(w http.ResponseWriter, r *http.Request) {
-	personal := config.GetPersonalInfo()
+	c := s.content()
+	personal := c.personal
 	data := PageData{
 		Title:        "xiaoOS Terminal - " + personal.Name,
 		Description:  "Welcome to xiaoOS - Portfolio system initialization and access point.",
@@ -98,70 +179,108 @@ func (s *Server) terminalHandler(w http.ResponseWriter, r *http.Request) {
 		Timestamp:    time.Now().Unix(),
 	}
 
-	if err := s.templates.ExecuteTemplate(w, "terminal.html", data); err != nil {
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
-		log.Printf("Template execution error: %v", err)
-	}
+	s.render(w, c, "terminal.html", data)
 }
 
 func (s *Server) homeHandler(w http.ResponseWriter, r *http.Request) {
-	personal := config.GetPersonalInfo()
+	c := s.content()
+	personal := c.personal
 	data := PageData{
 		Title:        personal.Name + " - " + personal.Title,
 		Description:  "Welcome to my portfolio showcasing my work in web development, software engineering, and creative projects.",
-		Projects:     s.projects[:min(3, len(s.projects))], // Show only first 3 projects on home
+		Projects:     c.projects[:min(3, len(c.projects))], // Show only first 3 projects on home
 		Personal:     personal,
 		Year:         time.Now().Year(),
 		TemplateName: "home",
 		Timestamp:    time.Now().Unix(),
 	}
 
-	if err := s.templates.ExecuteTemplate(w, "base.html", data); err != nil {
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
-		log.Printf("Template execution error: %v", err)
-	}
+	s.render(w, c, "base.html", data)
 }
 
 func (s *Server) aboutHandler(w http.ResponseWriter, r *http.Request) {
-	personal := config.GetPersonalInfo()
+	c := s.content()
+	personal := c.personal
 	data := PageData{
 		Title:        "About Me - " + personal.Name,
 		Description:  "Learn more about my background, skills, and experience in software development.",
-		Projects:     s.projects,
+		Projects:     c.projects,
 		Personal:     personal,
 		Year:         time.Now().Year(),
 		TemplateName: "about",
 		Timestamp:    time.Now().Unix(),
 	}
 
-	if err := s.templates.ExecuteTemplate(w, "base.html", data); err != nil {
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
-		log.Printf("Template execution error: %v", err)
-	}
+	s.render(w, c, "base.html", data)
 }
 
 func (s *Server) projectsHandler(w http.ResponseWriter, r *http.Request) {
-	personal := config.GetPersonalInfo()
+	c := s.content()
+	personal := c.personal
 	data := PageData{
 		Title:        "Projects - " + personal.Name,
 		Description:  "Explore my portfolio of web applications, software projects, and creative work.",
-		Projects:     s.projects,
+		Projects:     c.projects,
 		Personal:     personal,
 		Year:         time.Now().Year(),
 		TemplateName: "projects",
 		Timestamp:    time.Now().Unix(),
 	}
 
-	if err := s.templates.ExecuteTemplate(w, "base.html", data); err != nil {
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
-		log.Printf("Template execution error: %v", err)
+	s.render(w, c, "base.html", data)
+}
+
+func (s *Server) projectDetailHandler(w http.ResponseWriter, r *http.Request) {
+	c := s.content()
+	id := mux.Vars(r)["id"]
+
+	idx := ProjectIndex(c.projects, id)
+	if idx == -1 {
+		s.notFoundHandler(w, r)
+		return
+	}
+
+	project := c.projects[idx]
+	data := PageData{
+		Title:        project.Title + " - " + c.personal.Name,
+		Description:  project.Description,
+		Project:      &project,
+		Personal:     c.personal,
+		Year:         time.Now().Year(),
+		TemplateName: "project",
+		Timestamp:    time.Now().Unix(),
+	}
+	if idx > 0 {
+		data.PrevProject = &c.projects[idx-1]
+	}
+	if idx < len(c.projects)-1 {
+		data.NextProject = &c.projects[idx+1]
+	}
+
+	s.render(w, c, "base.html", data)
+}
+
+func (s *Server) notFoundHandler(w http.ResponseWriter, r *http.Request) {
+	c := s.content()
+	data := PageData{
+		Title:        "Not Found - " + c.personal.Name,
+		Description:  "The requested page could not be found.",
+		Path:         r.URL.Path,
+		Personal:     c.personal,
+		Year:         time.Now().Year(),
+		TemplateName: "notfound",
+		Timestamp:    time.Now().Unix(),
 	}
+
+	s.renderStatus(w, c, http.StatusNotFound, "base.html", data)
 }
 
 func (s *Server) contactHandler(w http.ResponseWriter, r *http.Request) {
-	personal := config.GetPersonalInfo()
+	c := s.content()
+	personal := c.personal
 
 	if r.Method == "GET" {
+		challenge, question := s.pow.IssueQuestion(clientIP(r), time.Now())
 		data := PageData{
 			Title:        "Contact Me - " + personal.Name,
 			Description:  "Get in touch with me for collaboration opportunities or project inquiries.",
@@ -169,12 +288,17 @@ func (s *Server) contactHandler(w http.ResponseWriter, r *http.Request) {
 			Year:         time.Now().Year(),
 			TemplateName: "contact",
 			Timestamp:    time.Now().Unix(),
+			FormToken:    s.formTokens.Issue(time.Now()),
+			ContactKey:   newSubmissionID(),
+			Challenge:    challenge,
+			Question:     question,
+			Flash:        s.takeFlash(w, r),
 		}
-
-		if err := s.templates.ExecuteTemplate(w, "base.html", data); err != nil {
-			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
-			log.Printf("Template execution error: %v", err)
+		if data.Flash != nil {
+			w.Header().Set("Cache-Control", "no-store")
 		}
+
+		s.render(w, c, "base.html", data)
 		return
 	}
 
@@ -187,7 +311,8 @@ func (s *Server) contactHandler(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *Server) resumeHandler(w http.ResponseWriter, r *http.Request) {
-	personal := config.GetPersonalInfo()
+	c := s.content()
+	personal := c.personal
 	data := PageData{
 		Title:        "Resume - " + personal.Name,
 		Description:  "View my professional experience, education, and skills.",
@@ -197,240 +322,89 @@ func (s *Server) resumeHandler(w http.ResponseWriter, r *http.Request) {
 		Timestamp:    time.Now().Unix(),
 	}
 
-	if err := s.templates.ExecuteTemplate(w, "base.html", data); err != nil {
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
-		log.Printf("Template execution error: %v", err)
-	}
+	s.render(w, c, "base.html", data)
 }
 
 func (s *Server) resumePDFHandler(w http.ResponseWriter, r *http.Request) {
-	texPath := "./static/assets/resume.tex"
-	pdfPath := "./static/assets/resume.pdf"
-
-	// Check if LaTeX file exists
-	if _, err := os.Stat(texPath); os.IsNotExist(err) {
-		http.Error(w, "Resume LaTeX file not found", http.StatusNotFound)
-		return
-	}
-
-	// Check if PDF exists and is newer than LaTeX file
-	texInfo, err := os.Stat(texPath)
-	if err != nil {
-		http.Error(w, "Error reading LaTeX file", http.StatusInternalServerError)
-		return
-	}
-
-	pdfInfo, err := os.Stat(pdfPath)
-	needsRebuild := os.IsNotExist(err) || pdfInfo.ModTime().Before(texInfo.ModTime())
-
-	if needsRebuild {
-		// Build PDF from LaTeX
-		if err := s.buildPDFFromLaTeX(texPath, pdfPath); err != nil {
-			// Try using the existing build script as fallback
-			if err := s.buildPDFUsingScript(); err != nil {
-				// If all else fails, create a simple HTML fallback
-				if err := s.createHTMLFallback(texPath, pdfPath); err != nil {
-					http.Error(w, "Failed to build PDF from LaTeX: "+err.Error(), http.StatusInternalServerError)
-					return
-				}
-			}
-		}
-	}
-
-	// Set headers for PDF display (inline)
-	w.Header().Set("Content-Type", "application/pdf")
-	w.Header().Set("Content-Disposition", "inline; filename=\"David_Xiao_Resume.pdf\"")
-	w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
-	w.Header().Set("Pragma", "no-cache")
-	w.Header().Set("Expires", "0")
-
-	http.ServeFile(w, r, pdfPath)
+	s.serveResumePDF(w, r, defaultResumeTarget, "inline")
 }
 
 func (s *Server) resumeDownloadHandler(w http.ResponseWriter, r *http.Request) {
-	texPath := "./static/assets/resume.tex"
-	pdfPath := "./static/assets/resume.pdf"
-
-	// Check if LaTeX file exists
-	if _, err := os.Stat(texPath); os.IsNotExist(err) {
-		http.Error(w, "Resume LaTeX file not found", http.StatusNotFound)
-		return
-	}
-
-	// Check if PDF exists and is newer than LaTeX file
-	texInfo, err := os.Stat(texPath)
-	if err != nil {
-		http.Error(w, "Error reading LaTeX file", http.StatusInternalServerError)
-		return
-	}
-
-	pdfInfo, err := os.Stat(pdfPath)
-	needsRebuild := os.IsNotExist(err) || pdfInfo.ModTime().Before(texInfo.ModTime())
-
-	if needsRebuild {
-		// Build PDF from LaTeX
-		if err := s.buildPDFFromLaTeX(texPath, pdfPath); err != nil {
-			// Try using the existing build script as fallback
-			if err := s.buildPDFUsingScript(); err != nil {
-				// If all else fails, create a simple HTML fallback
-				if err := s.createHTMLFallback(texPath, pdfPath); err != nil {
-					http.Error(w, "Failed to build PDF from LaTeX: "+err.Error(), http.StatusInternalServerError)
-					return
-				}
-			}
-		}
-	}
-
-	// Set headers for PDF download (attachment)
-	w.Header().Set("Content-Type", "application/pdf")
-	w.Header().Set("Content-Disposition", "attachment; filename=\"David_Xiao_Resume.pdf\"")
-	w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
-	w.Header().Set("Pragma", "no-cache")
-	w.Header().Set("Expires", "0")
-
-	http.ServeFile(w, r, pdfPath)
+	s.serveResumePDF(w, r, defaultResumeTarget, "attachment")
 }
 
-func (s *Server) buildPDFFromLaTeX(texPath, pdfPath string) error {
-	// Change to the assets directory
-	originalDir, err := os.Getwd()
-	if err != nil {
-		return fmt.Errorf("failed to get current directory: %v", err)
-	}
-
-	if err := os.Chdir("./static/assets"); err != nil {
-		return fmt.Errorf("failed to change to assets directory: %v", err)
-	}
-	defer os.Chdir(originalDir)
+// serveResumePDF serves the last successfully built PDF of a resume target.
+// Builds run in the background; a request only nudges the builder to check
+// for changes.
+func (s *Server) serveResumePDF(w http.ResponseWriter, r *http.Request, target, disposition string) {
+	s.resumes.Trigger()
 
-	// Try different LaTeX engines in order of preference
-	engines := []struct {
-		name string
-		cmd  []string
-	}{
-		{"latexmk", []string{"latexmk", "-pdf", "-interaction=nonstopmode", "resume.tex"}},
-		{"lualatex", []string{"lualatex", "-interaction=nonstopmode", "resume.tex"}},
-		{"xelatex", []string{"xelatex", "-interaction=nonstopmode", "resume.tex"}},
-		{"pdflatex", []string{"pdflatex", "-interaction=nonstopmode", "resume.tex"}},
+	name := resumeFileName(s.content().personal.Name, "pdf")
+	if target != defaultResumeTarget {
+		name = resumeFileName(s.content().personal.Name+" "+target, "pdf")
 	}
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", disposition+"; filename=\""+name+"\"")
 
-	var lastErr error
-	for _, engine := range engines {
-		if _, err := exec.LookPath(engine.name); err != nil {
-			continue // Skip if engine not found
-		}
-
-		// Try the engine
-		cmd := exec.Command(engine.cmd[0], engine.cmd[1:]...)
-		output, err := cmd.CombinedOutput()
-
-		if err == nil {
-			// Success! Clean up and return
-			s.cleanupAuxFiles()
-			if _, err := os.Stat("resume.pdf"); err == nil {
-				return nil
-			}
-		}
-
-		lastErr = fmt.Errorf("%s failed: %v\nOutput: %s", engine.name, err, string(output))
-
-		// For engines that need multiple passes, try again
-		if engine.name == "lualatex" || engine.name == "xelatex" || engine.name == "pdflatex" {
-			cmd = exec.Command(engine.cmd[0], engine.cmd[1:]...)
-			_, err = cmd.CombinedOutput()
-			if err == nil {
-				s.cleanupAuxFiles()
-				if _, err := os.Stat("resume.pdf"); err == nil {
-					return nil
-				}
-			}
-		}
-	}
-
-	return fmt.Errorf("all LaTeX engines failed. Last error: %v", lastErr)
-}
-
-func (s *Server) cleanupAuxFiles() {
-	auxFiles := []string{"resume.aux", "resume.log", "resume.out", "resume.fdb_latexmk", "resume.fls", "resume.synctex.gz", "resume.toc", "resume.nav", "resume.snm"}
-	for _, file := range auxFiles {
-		os.Remove(file)
+	// Until LaTeX has built the PDF, or when no engine is installed, the
+	// built-in typesetter stands in
+	pdf, ok := s.resumes.LastGood(target)
+	if !ok {
+		s.serveBuiltinResumePDF(w, r, target)
+		return
 	}
+	serveResumeArtifact(w, r, pdf)
 }
 
-func (s *Server) buildPDFUsingScript() error {
-	// Try using the existing build script
-	cmd := exec.Command("scripts/build-resume.bat")
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("build script failed: %v\nOutput: %s", err, string(output))
-	}
-	return nil
+func (s *Server) resumeHTMLHandler(w http.ResponseWriter, r *http.Request) {
+	texPath, err := s.prepareResumeSource()
+	s.serveResumeHTML(w, r, texPath, err)
 }
 
-func (s *Server) createHTMLFallback(texPath, pdfPath string) error {
-	// Create a simple HTML version as fallback
-	htmlPath := strings.Replace(pdfPath, ".pdf", ".html", 1)
-
-	// Read the LaTeX file
-	texContent, err := os.ReadFile(texPath)
-	if err != nil {
-		return err
-	}
-
-	// Convert to HTML
-	htmlContent := s.convertLaTeXToHTML(string(texContent))
-
-	// Write HTML file
-	if err := os.WriteFile(htmlPath, []byte(htmlContent), 0644); err != nil {
-		return err
+// serveResumeHTML serves the HTML rendering of the LaTeX source at texPath.
+// prepareErr is the error from writing that source, if any.
+func (s *Server) serveResumeHTML(w http.ResponseWriter, r *http.Request, texPath string, prepareErr error) {
+	if err := prepareErr; err != nil {
+		log.Printf("Failed to prepare resume source: %v", err)
+		http.Error(w, "Failed to generate resume", http.StatusInternalServerError)
+		return
 	}
 
-	// Update the PDF handler to serve HTML instead
-	return nil
-}
-
-func (s *Server) resumeHTMLHandler(w http.ResponseWriter, r *http.Request) {
-	texPath := "./static/assets/resume.tex"
-	htmlPath := "./static/assets/resume.html"
-
 	// Check if LaTeX file exists
 	if _, err := os.Stat(texPath); os.IsNotExist(err) {
 		http.Error(w, "Resume LaTeX file not found", http.StatusNotFound)
 		return
 	}
 
-	// Check if HTML exists and is newer than LaTeX file
-	texInfo, err := os.Stat(texPath)
+	// Build the HTML unless the cache already has it for this source
+	page, err := s.ensureResumeHTML(texPath)
 	if err != nil {
-		http.Error(w, "Error reading LaTeX file", http.StatusInternalServerError)
+		log.Printf("Failed to create HTML from LaTeX: %v", err)
+		http.Error(w, "Failed to create HTML from LaTeX", http.StatusInternalServerError)
 		return
 	}
 
-	htmlInfo, err := os.Stat(htmlPath)
-	needsRebuild := os.IsNotExist(err) || htmlInfo.ModTime().Before(texInfo.ModTime())
-
-	if needsRebuild {
-		// Try to convert LaTeX to HTML using pandoc
-		cmd := exec.Command("pandoc", texPath, "-o", htmlPath, "--mathjax", "--standalone", "--css", "resume.css")
-		cmd.Dir = "./static/assets"
-
-		if err := cmd.Run(); err != nil {
-			// If pandoc fails, try htlatex
-			cmd = exec.Command("htlatex", "resume.tex", "xhtml,2", "charset=utf-8", "")
-			cmd.Dir = "./static/assets"
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	serveResumeArtifact(w, r, page)
+}
 
-			if err := cmd.Run(); err != nil {
-				// If both fail, create a simple HTML version from the LaTeX content
-				if err := s.createSimpleHTMLFromLaTeX(texPath, htmlPath); err != nil {
-					http.Error(w, "Failed to create HTML from LaTeX", http.StatusInternalServerError)
-					return
-				}
-			}
-		}
+// serveResumeArtifact serves a built resume file with a strong ETag derived
+// from its cache key. Browsers revalidate on every request and get a 304
+// while the inputs are unchanged.
+func serveResumeArtifact(w http.ResponseWriter, r *http.Request, artifact resumeArtifact) {
+	f, err := os.Open(artifact.Path)
+	if err != nil {
+		log.Printf("Failed to open resume artifact: %v", err)
+		http.Error(w, "Resume is not available", http.StatusInternalServerError)
+		return
 	}
+	defer f.Close()
 
-	// Serve the HTML file
-	http.ServeFile(w, r, htmlPath)
+	w.Header().Set("ETag", artifact.ETag())
+	w.Header().Set("Cache-Control", "no-cache")
+	// A zero modification time leaves out Last-Modified, which is
+	// meaningless after checkouts and image builds
+	http.ServeContent(w, r, filepath.Base(artifact.Path), time.Time{}, f)
 }
 
 func (s *Server) createSimpleHTMLFromLaTeX(texPath, htmlPath string) error {
@@ -444,126 +418,185 @@ func (s *Server) createSimpleHTMLFromLaTeX(texPath, htmlPath string) error {
 	htmlContent := s.convertLaTeXToHTML(string(texContent))
 
 	// Write the HTML file
-	return os.WriteFile(htmlPath, []byte(htmlContent), 0644)
+	return writeFileAtomic(htmlPath, []byte(htmlContent))
 }
 
+// convertLaTeXToHTML renders the resume LaTeX as a standalone HTML page
 func (s *Server) convertLaTeXToHTML(texContent string) string {
-	// Basic LaTeX to HTML conversion
-	html := `<!DOCTYPE html>
+	title := html.EscapeString(s.content().personal.Name + " - Resume")
+
+	return `<!DOCTYPE html>
 <html lang="en">
 <head>
     <meta charset="UTF-8">
     <meta name="viewport" content="width=device-width, initial-scale=1.0">
-    <title>David Xiao - Resume</title>
-    <link rel="stylesheet" href="resume.css">
+    <title>` + title + `</title>
+    <link rel="stylesheet" href="/static/assets/resume.css">
 </head>
 <body>
+` + latex.ToHTML(texContent) + `</body>
+</html>
 `
+}
+
+// contactThanks is the answer to an accepted contact form post
+const contactThanks = "Thank you for your message! I'll get back to you soon."
 
-	// Extract content between \begin{document} and \end{document}
-	start := "\\begin{document}"
-	end := "\\end{document}"
-	startIdx := strings.Index(texContent, start)
-	endIdx := strings.Index(texContent, end)
-
-	if startIdx == -1 || endIdx == -1 {
-		return html + "<p>Error: Could not find document content</p></body></html>"
-	}
-
-	content := texContent[startIdx+len(start) : endIdx]
-
-	// Convert LaTeX commands to HTML
-	content = strings.ReplaceAll(content, "\\textbf{", "<strong>")
-	content = strings.ReplaceAll(content, "\\textit{", "<em>")
-	content = strings.ReplaceAll(content, "\\href{", "<a href=\"")
-	content = strings.ReplaceAll(content, "\\underline{", "<u>")
-	content = strings.ReplaceAll(content, "\\scshape", "")
-	content = strings.ReplaceAll(content, "\\Huge", "")
-	content = strings.ReplaceAll(content, "\\large", "")
-	content = strings.ReplaceAll(content, "\\small", "")
-	content = strings.ReplaceAll(content, "\\tiny", "")
-
-	// Handle closing braces
-	content = strings.ReplaceAll(content, "}", "</strong>")
-	content = strings.ReplaceAll(content, "}", "</em>")
-	content = strings.ReplaceAll(content, "}", "\">")
-	content = strings.ReplaceAll(content, "}", "</u>")
-
-	// Convert sections
-	content = strings.ReplaceAll(content, "\\section{", "<h2>")
-	content = strings.ReplaceAll(content, "\\subsection{", "<h3>")
-
-	// Convert itemize environments
-	content = strings.ReplaceAll(content, "\\begin{itemize}", "<ul>")
-	content = strings.ReplaceAll(content, "\\end{itemize}", "</ul>")
-	content = strings.ReplaceAll(content, "\\item", "<li>")
-
-	// Convert resumeItem commands
-	content = strings.ReplaceAll(content, "\\resumeItem{", "<li>")
-	content = strings.ReplaceAll(content, "\\resumeSubheading{", "<div class=\"resumeSubheading\">")
-	content = strings.ReplaceAll(content, "\\resumeProjectHeading{", "<div class=\"resumeProjectHeading\">")
-
-	// Handle special characters
-	content = strings.ReplaceAll(content, "\\&", "&")
-	content = strings.ReplaceAll(content, "\\$", "$")
-	content = strings.ReplaceAll(content, "\\%", "%")
-	content = strings.ReplaceAll(content, "\\#", "#")
-	content = strings.ReplaceAll(content, "\\_", "_")
-	content = strings.ReplaceAll(content, "\\{", "{")
-	content = strings.ReplaceAll(content, "\\}", "}")
-
-	// Clean up extra spaces and line breaks
-	content = strings.ReplaceAll(content, "\n\n", "\n")
-	content = strings.TrimSpace(content)
-
-	html += content
-	html += `
-</body>
-</html>`
-
-	return html
+// contactResult is the outcome of a contact form post
+type contactResult struct {
+	Status  int // HTTP status for JSON clients
+	Message string
+	Errors  FieldErrors // Per-field problems, when validation failed
+	ID      string      // Stored submission, on success
 }
 
 func (s *Server) handleContactForm(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxContactBody)
+
+	// Browsers without JavaScript post the form itself and get a redirect
+	// back to the contact page; the script posts JSON and reads the result
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	htmlForm := mediaType == "application/x-www-form-urlencoded" || mediaType == "multipart/form-data"
+
 	var form ContactForm
+	var result contactResult
+	key := r.Header.Get("Idempotency-Key")
+	if htmlForm {
+		var err error
+		form, err = parseContactForm(r, mediaType)
+		if err != nil {
+			result = contactResult{Status: http.StatusBadRequest, Message: "Invalid form data"}
+		}
+		if key == "" {
+			key = r.PostFormValue("idempotency_key")
+		}
+	} else if err := json.NewDecoder(r.Body).Decode(&form); err != nil {
+		result = contactResult{Status: http.StatusBadRequest, Message: "Invalid form data"}
+	}
+	if result.Status == 0 {
+		result = s.submitContact(r, form, key, htmlForm)
+	}
 
-	if err := json.NewDecoder(r.Body).Decode(&form); err != nil {
-		http.Error(w, "Invalid form data", http.StatusBadRequest)
+	if htmlForm {
+		s.redirectWithFlash(w, r, result, form)
 		return
 	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(result.Status)
+	resp := map[string]interface{}{"status": "success", "message": result.Message}
+	if result.Status != http.StatusOK {
+		resp["status"] = "error"
+	}
+	if result.ID != "" {
+		resp["id"] = result.ID
+	}
+	if result.Errors != nil {
+		resp["errors"] = result.Errors
+	}
+	json.NewEncoder(w).Encode(resp)
+}
 
-	// Validate required fields
-	if form.Name == "" || form.Email == "" || form.Message == "" {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{
-			"status":  "error",
-			"message": "Name, email, and message are required fields.",
-		})
-		return
+// parseContactForm reads a contact form posted by a browser
+func parseContactForm(r *http.Request, mediaType string) (ContactForm, error) {
+	var err error
+	if mediaType == "multipart/form-data" {
+		err = r.ParseMultipartForm(maxContactBody)
+	} else {
+		err = r.ParseForm()
 	}
+	if err != nil {
+		return ContactForm{}, err
+	}
+	return ContactForm{
+		Name:      r.PostFormValue("name"),
+		Email:     r.PostFormValue("email"),
+		Subject:   r.PostFormValue("subject"),
+		Message:   r.PostFormValue("message"),
+		Website:   r.PostFormValue("website"),
+		FormToken: r.PostFormValue("form_token"),
+		Challenge: r.PostFormValue("challenge"),
+		Solution:  r.PostFormValue("solution"),
+	}, nil
+}
 
-	// Log the contact form submission
-	log.Printf("Contact form submission from %s (%s): %s", form.Name, form.Email, form.Subject)
+// submitContact validates a posted form, runs it through the spam filters
+// and queues it in the outbox. formPost is set for a post by the page's own
+// form rather than its script.
+func (s *Server) submitContact(r *http.Request, form ContactForm, key string, formPost bool) contactResult {
+	form.Normalize()
+	if errs := form.Validate(); errs != nil {
+		return contactResult{Status: http.StatusBadRequest, Message: "Please correct the highlighted fields.", Errors: errs}
+	}
 
-	// Send email
-	if err := s.sendEmail(form); err != nil {
-		log.Printf("Failed to send email: %v", err)
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]string{
-			"status":  "error",
-			"message": "Failed to send message. Please try again or contact me directly.",
-		})
-		return
+	if len(key) > maxIdempotencyKey {
+		return contactResult{Status: http.StatusBadRequest, Message: "Idempotency-Key is too long."}
+	}
+	// A retried request gets the submission it created the first time
+	if existing, ok := s.contacts.ByKey(key); ok {
+		return contactAccepted(existing, form)
 	}
 
-	// Success response
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{
-		"status":  "success",
-		"message": "Thank you for your message! I'll get back to you soon.",
-	})
+	sub := ContactSubmission{
+		IP:        clientIP(r),
+		UserAgent: r.UserAgent(),
+		Form:      form,
+		formPost:  formPost,
+	}
+	if rej := s.spam.Check(sub, time.Now()); rej != nil {
+		return s.rejectContact(sub, rej)
+	}
+
+	// Queue the submission in the outbox; the email is sent in the
+	// background, retried if it fails
+	sub.IdempotencyKey = key
+	sub, added, err := s.contacts.Add(sub)
+	if err != nil {
+		log.Printf("Failed to store contact submission: %v", err)
+		return contactResult{Status: http.StatusInternalServerError, Message: "Failed to send message. Please try again or contact me directly."}
+	}
+	if added {
+		log.Printf("Contact form submission %s from %s (%s): %s", sub.ID, form.Name, form.Email, form.Subject)
+		s.outbox.Trigger()
+	}
+	return contactAccepted(sub, form)
+}
+
+// rejectContact stores a submission a spam filter turned away, for review,
+// and answers as the filter asked. Submissions the filter discards, and
+// any past maxStoredRejections in a rateWindow, are only logged, so a
+// flood of posts cannot fill the disk.
+func (s *Server) rejectContact(sub ContactSubmission, rej *SpamRejection) contactResult {
+	sub.Status = deliveryRejected
+	sub.Rejection = rej.Filter + ": " + rej.Reason
+	id := "(not stored)"
+	if !rej.Discard && s.rejections.Allow("", time.Now()) {
+		stored, _, err := s.contacts.Add(sub)
+		if err != nil {
+			log.Printf("Failed to store rejected contact submission: %v", err)
+		} else {
+			id = stored.ID
+			sub = stored
+		}
+	}
+	log.Printf("🚫 Contact submission %s from %s rejected by %s: %s", id, sub.IP, rej.Filter, rej.Reason)
+
+	if rej.Status != 0 {
+		return contactResult{Status: rej.Status, Message: rej.Message}
+	}
+	// Whether or not it was stored, the bot is told it succeeded
+	return contactResult{Status: http.StatusOK, Message: contactThanks, ID: sub.ID}
+}
+
+// contactAccepted answers a contact form post with the submission it was
+// stored as. form is what was posted, whose message must match a
+// submission found by idempotency key; the spam check fields may differ
+// between retries.
+func contactAccepted(sub ContactSubmission, form ContactForm) contactResult {
+	if sub.Form.Name != form.Name || sub.Form.Email != form.Email ||
+		sub.Form.Subject != form.Subject || sub.Form.Message != form.Message {
+		return contactResult{Status: http.StatusUnprocessableEntity, Message: "Idempotency-Key was already used for a different message."}
+	}
+	return contactResult{Status: http.StatusOK, Message: contactThanks, ID: sub.ID}
 }
 
 func min(a, b int) int {
@@ -590,71 +623,81 @@ func getEnvInt(key string, defaultValue int) int {
 	return defaultValue
 }
 
-// Email sending function
-func (s *Server) sendEmail(form ContactForm) error {
-	// Check if email configuration is properly set
-	if s.emailConfig.Username == "" || s.emailConfig.Password == "" || s.emailConfig.ToEmail == "" {
-		return fmt.Errorf("email configuration incomplete")
-	}
-
-	// Create new message
-	m := mail.NewMessage()
-	m.SetHeader("From", s.emailConfig.FromEmail)
-	m.SetHeader("To", s.emailConfig.ToEmail)
-	m.SetHeader("Subject", fmt.Sprintf("Portfolio Contact: %s", form.Subject))
+// API Handlers for project filtering
+func (s *Server) projectsAPIHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
 
-	// Create email body
-	body := fmt.Sprintf(`
-New contact form submission from your portfolio:
+	query, err := ParseProjectQuery(r.URL.Query())
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{
+			"status":  "error",
+			"message": err.Error(),
+		})
+		return
+	}
 
-Name: %s
-Email: %s
-Subject: %s
+	json.NewEncoder(w).Encode(query.Apply(s.content().projects))
+}
 
-Message:
-%s
+func (s *Server) projectAPIHandler(w http.ResponseWriter, r *http.Request) {
+	projects := s.content().projects
+	idx := ProjectIndex(projects, mux.Vars(r)["id"])
 
----
-This message was sent from your portfolio contact form.
-`, form.Name, form.Email, form.Subject, form.Message)
+	w.Header().Set("Content-Type", "application/json")
+	if idx == -1 {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{
+			"status":  "error",
+			"message": "Project not found.",
+		})
+		return
+	}
 
-	m.SetBody("text/plain", body)
+	json.NewEncoder(w).Encode(projects[idx])
+}
 
-	// Create dialer
-	d := mail.NewDialer(s.emailConfig.SMTPHost, s.emailConfig.SMTPPort, s.emailConfig.Username, s.emailConfig.Password)
+func (s *Server) searchAPIHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
 
-	// Send email
-	if err := d.DialAndSend(m); err != nil {
-		return fmt.Errorf("failed to send email: %v", err)
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if query == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{
+			"status":  "error",
+			"message": "Missing search query.",
+		})
+		return
 	}
 
-	return nil
-}
+	limit, err := intParam(r.URL.Query(), "limit")
+	if err != nil || limit == 0 || limit > 50 {
+		limit = 10
+	}
 
-// API Handlers for project filtering
-func (s *Server) projectsAPIHandler(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(s.projects)
+	results := s.content().search.Search(query, limit)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":  "success",
+		"query":   query,
+		"count":   len(results),
+		"results": results,
+	})
 }
 
 func (s *Server) projectsByTypeAPIHandler(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	projectType := vars["type"]
-
-	filteredProjects := GetProjectsByType(projectType)
+	query := ProjectQuery{Types: []string{mux.Vars(r)["type"]}}
+	page := query.Apply(s.content().projects)
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(filteredProjects)
+	json.NewEncoder(w).Encode(page.Projects)
 }
 
 func (s *Server) projectsByStatusAPIHandler(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	status := vars["status"]
-
-	filteredProjects := GetProjectsByStatus(status)
+	query := ProjectQuery{Statuses: []string{mux.Vars(r)["status"]}}
+	page := query.Apply(s.content().projects)
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(filteredProjects)
+	json.NewEncoder(w).Encode(page.Projects)
 }
 
 // HTTPS redirect middleware
@@ -689,6 +732,14 @@ func main() {
 		log.Println("No .env file found, using system environment variables")
 	}
 
+	// "resume-tex" prints the generated resume LaTeX instead of serving
+	if len(os.Args) > 1 && os.Args[1] == "resume-tex" {
+		if err := printResumeTeX(os.Stdout, getEnv("CONTENT_DIR", "content")); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	server := NewServer()
 
 	r := mux.NewRouter()
@@ -698,11 +749,19 @@ func main() {
 	r.HandleFunc("/home", server.homeHandler).Methods("GET")
 	r.HandleFunc("/about", server.aboutHandler).Methods("GET")
 	r.HandleFunc("/projects", server.projectsHandler).Methods("GET")
+	r.HandleFunc("/projects/{id}", server.projectDetailHandler).Methods("GET")
 	r.HandleFunc("/contact", server.contactHandler).Methods("GET", "POST")
-	r.HandleFunc("/resume", server.resumeHandler).Methods("GET")
+	r.HandleFunc("/resume", server.resumeNegotiated).Methods("GET")
+	r.HandleFunc("/resume.json", server.resumeJSONHandler).Methods("GET")
+	r.HandleFunc("/resume.txt", server.resumeTextHandler).Methods("GET")
+	r.HandleFunc("/resume.md", server.resumeMarkdownHandler).Methods("GET")
 	r.HandleFunc("/resume/pdf", server.resumePDFHandler).Methods("GET")
 	r.HandleFunc("/resume/download", server.resumeDownloadHandler).Methods("GET")
 	r.HandleFunc("/resume/html", server.resumeHTMLHandler).Methods("GET")
+	r.HandleFunc("/resume/docx", server.resumeDOCXHandler).Methods("GET")
+	r.HandleFunc("/resume/{variant}/pdf", server.resumeVariantPDFHandler).Methods("GET")
+	r.HandleFunc("/resume/{variant}/html", server.resumeVariantHTMLHandler).Methods("GET")
+	r.HandleFunc("/resume/{variant}/txt", server.resumeVariantTextHandler).Methods("GET")
 
 	// Debug route for animation troubleshooting
 	r.HandleFunc("/debug", func(w http.ResponseWriter, r *http.Request) {
@@ -713,6 +772,18 @@ func main() {
 	r.HandleFunc("/api/projects", server.projectsAPIHandler).Methods("GET")
 	r.HandleFunc("/api/projects/type/{type}", server.projectsByTypeAPIHandler).Methods("GET")
 	r.HandleFunc("/api/projects/status/{status}", server.projectsByStatusAPIHandler).Methods("GET")
+	r.HandleFunc("/api/projects/{id}", server.projectAPIHandler).Methods("GET")
+	r.HandleFunc("/api/search", server.searchAPIHandler).Methods("GET")
+	r.HandleFunc("/api/resume/analyze", server.resumeAnalyzeHandler).Methods("POST")
+	r.HandleFunc("/api/contact/challenge", server.contactChallengeHandler).Methods("GET")
+
+	// Admin routes
+	r.HandleFunc("/admin/resume/builds", requireAdmin(server.resumeBuildsHandler)).Methods("GET")
+	r.HandleFunc("/admin/resume/builds/{id:[0-9]+}/log", requireAdmin(server.resumeBuildLogHandler)).Methods("GET")
+	r.HandleFunc("/admin/contact/submissions", requireAdmin(server.contactSubmissionsHandler)).Methods("GET")
+	r.HandleFunc("/admin/contact/submissions/{id}/retry", requireAdmin(server.contactRetryHandler)).Methods("POST")
+
+	r.NotFoundHandler = http.HandlerFunc(server.notFoundHandler)
 
 	// Hosted projects routes
 	r.PathPrefix("/hosted/").Handler(http.StripPrefix("/hosted/", http.FileServer(http.Dir("./hosted-projects/"))))
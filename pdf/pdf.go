@@ -0,0 +1,442 @@
+// Package pdf writes richtext documents as PDF without external tools. It
+// supports the small set of features a resume needs, as package docx does:
+// styled paragraphs, bold and italic runs, hyperlinks, bullet lists and a
+// right aligned tab stop for dates. Text is set in the standard Helvetica
+// fonts, which every PDF viewer provides.
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/daveonthegit/Personal_Portfolio/richtext"
+)
+
+// Page layout in points: US Letter with 0.6in margins
+const (
+	pageWidth  = 612.0
+	pageHeight = 792.0
+	pageMargin = 43.2
+	textWidth  = pageWidth - 2*pageMargin
+
+	lineSpacing = 1.2 // Line height as a multiple of the font size
+	tabGap      = 12  // Minimum space between text and a right aligned tab
+)
+
+type style struct {
+	size          float64
+	bold, italic  bool
+	centered      bool
+	indent        float64 // Left indent of the text
+	before, after float64 // Space around the paragraph
+	rule          bool    // Draw a line under the paragraph
+	keepWithNext  bool    // Never end a page after this paragraph
+}
+
+var styles = map[string]style{
+	richtext.StyleNormal:   {size: 10.5, after: 2},
+	richtext.StyleTitle:    {size: 22, bold: true, centered: true, after: 2},
+	richtext.StyleSubtitle: {size: 10, centered: true, after: 3},
+	richtext.StyleHeading1: {size: 13, bold: true, before: 10, after: 4, rule: true, keepWithNext: true},
+	richtext.StyleHeading2: {size: 10.5, bold: true, before: 3, keepWithNext: true},
+	richtext.StyleDetail:   {size: 10, italic: true, after: 2, keepWithNext: true},
+	richtext.StyleBullet:   {size: 10, indent: 18, after: 1},
+}
+
+// Document is a PDF document built paragraph by paragraph
+type Document struct {
+	richtext.Body
+
+	Title  string // Stored in the document information
+	Author string
+}
+
+// New returns an empty document
+func New() *Document {
+	return &Document{}
+}
+
+// Bytes returns the document as a PDF file
+func (d *Document) Bytes() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := d.Write(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// fragment is part of a word in a single font
+type fragment struct {
+	text  []byte // WinAnsi encoded
+	font  font
+	link  string
+	width float64
+}
+
+// word is text between spaces; its fragments are never split across lines
+type word []fragment
+
+type line struct {
+	words []word
+	width float64
+}
+
+// lines breaks runs into lines no wider than maxWidth. Words wider than a
+// line are kept whole.
+func (st style) lines(runs []richtext.Run, maxWidth float64) []line {
+	space := fontRegular.width([]byte{' '}, st.size)
+
+	var lines []line
+	var cur line
+	var w word
+	var wordWidth float64
+
+	endWord := func() {
+		if len(w) == 0 {
+			return
+		}
+		if len(cur.words) > 0 && cur.width+space+wordWidth > maxWidth {
+			lines = append(lines, cur)
+			cur = line{}
+		}
+		if len(cur.words) > 0 {
+			cur.width += space
+		}
+		cur.words = append(cur.words, w)
+		cur.width += wordWidth
+		w, wordWidth = nil, 0
+	}
+
+	for _, run := range runs {
+		f := fontFor(run.Bold || st.bold, run.Italic || st.italic)
+		var pending strings.Builder
+		endFragment := func() {
+			if text := winAnsi(pending.String()); len(text) > 0 {
+				frag := fragment{text: text, font: f, link: run.Link, width: f.width(text, st.size)}
+				w = append(w, frag)
+				wordWidth += frag.width
+			}
+			pending.Reset()
+		}
+		for _, c := range run.Text {
+			switch c {
+			case ' ', '\t':
+				endFragment()
+				endWord()
+			case '\n':
+				endFragment()
+				endWord()
+				lines = append(lines, cur)
+				cur = line{}
+			default:
+				pending.WriteRune(c)
+			}
+		}
+		endFragment() // Words continue into the next run
+	}
+	endWord()
+
+	if len(cur.words) > 0 || len(lines) == 0 {
+		lines = append(lines, cur)
+	}
+	return lines
+}
+
+// splitTab splits runs at the first tab, into the text before and after the
+// tab stop
+func splitTab(runs []richtext.Run) (left, right []richtext.Run) {
+	for i, run := range runs {
+		before, after, found := strings.Cut(run.Text, "\t")
+		if !found {
+			continue
+		}
+		left = append(append(left, runs[:i]...), richtext.Run{Text: before, Bold: run.Bold, Italic: run.Italic, Link: run.Link})
+		right = append(right, richtext.Run{Text: after, Bold: run.Bold, Italic: run.Italic, Link: run.Link})
+		return left, append(right, runs[i+1:]...)
+	}
+	return runs, nil
+}
+
+type page struct {
+	content bytes.Buffer
+	links   []linkArea
+}
+
+// linkArea is the clickable rectangle of a hyperlink
+type linkArea struct {
+	x1, y1, x2, y2 float64
+	uri            string
+}
+
+// layout places paragraphs on pages, top to bottom
+type layout struct {
+	pages []*page
+	y     float64 // Top of the next line
+}
+
+func (l *layout) newPage() {
+	l.pages = append(l.pages, &page{})
+	l.y = pageHeight - pageMargin
+}
+
+func (l *layout) page() *page {
+	return l.pages[len(l.pages)-1]
+}
+
+func (l *layout) atTop() bool {
+	return l.y == pageHeight-pageMargin
+}
+
+func (l *layout) paragraph(p richtext.Paragraph) {
+	st, ok := styles[p.Style]
+	if !ok {
+		st = styles[richtext.StyleNormal]
+	}
+	leading := st.size * lineSpacing
+
+	left, right := splitTab(p.Runs)
+	var tab line
+	maxWidth := textWidth - st.indent
+	if len(right) > 0 {
+		tab = st.lines(right, textWidth)[0]
+		if tab.width > 0 {
+			maxWidth -= tab.width + tabGap
+		}
+	}
+	lines := st.lines(left, maxWidth)
+
+	if st.keepWithNext {
+		// Move to a new page unless the paragraph and the first lines of
+		// the next fit on this one
+		needed := st.before + float64(len(lines)+2)*leading + st.after
+		if !l.atTop() && l.y-needed < pageMargin {
+			l.newPage()
+		}
+	}
+	if !l.atTop() {
+		l.y -= st.before
+	}
+
+	for i, ln := range lines {
+		if l.y-leading < pageMargin {
+			l.newPage()
+		}
+		baseline := l.y - st.size
+		l.y -= leading
+
+		x := pageMargin + st.indent
+		if st.centered {
+			x = pageMargin + (textWidth-ln.width)/2
+		}
+		if i == 0 && p.Style == richtext.StyleBullet {
+			bullet := []byte{0x95}
+			l.write(pageMargin+st.indent-11, baseline, st.size, line{words: []word{{{text: bullet}}}})
+		}
+		l.write(x, baseline, st.size, ln)
+		if i == 0 && tab.width > 0 {
+			l.write(pageMargin+textWidth-tab.width, baseline, st.size, tab)
+		}
+	}
+
+	if st.rule {
+		y := l.y - 1
+		fmt.Fprintf(&l.page().content, "0.6 w %s %s m %s %s l S\n", num(pageMargin), num(y), num(pageMargin+textWidth), num(y))
+		l.y -= 2
+	}
+	l.y -= st.after
+}
+
+// write draws a line of text starting at x, recording its links. Words in
+// the same font are drawn as one string with real spaces, so text copied or
+// extracted from the PDF keeps them.
+func (l *layout) write(x, baseline, size float64, ln line) {
+	pg := l.page()
+	space := fontRegular.width([]byte{' '}, size)
+
+	var text []byte
+	var textFont font
+	var textX float64
+	flush := func() {
+		if len(text) > 0 {
+			fmt.Fprintf(&pg.content, "BT /F%d %s Tf %s %s Td (%s) Tj ET\n", textFont+1, num(size), num(textX), num(baseline), escapeString(text))
+		}
+		text = nil
+	}
+
+	prevLink := ""
+	for i, w := range ln.words {
+		for j, f := range w {
+			if i > 0 && j == 0 {
+				x += space
+				if len(text) > 0 && f.font == textFont {
+					text = append(text, ' ')
+				} else {
+					flush()
+				}
+			}
+			if len(text) > 0 && f.font != textFont {
+				flush()
+			}
+			if len(text) == 0 {
+				textFont, textX = f.font, x
+			}
+			text = append(text, f.text...)
+
+			if f.link != "" {
+				if f.link == prevLink {
+					// Continue the link across words
+					pg.links[len(pg.links)-1].x2 = x + f.width
+				} else {
+					pg.links = append(pg.links, linkArea{
+						x1: x, y1: baseline - 0.25*size,
+						x2: x + f.width, y2: baseline + 0.85*size,
+						uri: f.link,
+					})
+				}
+			}
+			prevLink = f.link
+			x += f.width
+		}
+	}
+	flush()
+}
+
+// Write writes the document to w as a PDF file
+func (d *Document) Write(w io.Writer) error {
+	l := &layout{}
+	l.newPage()
+	for _, p := range d.Paragraphs {
+		l.paragraph(p)
+	}
+
+	// Objects 1-3 are the catalog, page tree and document information, 4-7
+	// the fonts, then each page is followed by its contents and links
+	const firstPageObject = 8
+	pageObjects := make([]int, len(l.pages))
+	next := firstPageObject
+	for i, pg := range l.pages {
+		pageObjects[i] = next
+		next += 2 + len(pg.links)
+	}
+
+	pw := &writer{offsets: make([]int, next-1)}
+	pw.buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	pw.object(1, "<< /Type /Catalog /Pages 2 0 R >>")
+	kids := make([]string, len(pageObjects))
+	for i, n := range pageObjects {
+		kids[i] = fmt.Sprintf("%d 0 R", n)
+	}
+	pw.object(2, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(kids)))
+	pw.object(3, fmt.Sprintf("<< /Title %s /Author %s >>", textString(d.Title), textString(d.Author)))
+	for i, name := range fontNames {
+		pw.object(4+i, "<< /Type /Font /Subtype /Type1 /BaseFont /"+name+" /Encoding /WinAnsiEncoding >>")
+	}
+
+	for i, pg := range l.pages {
+		n := pageObjects[i]
+		annots := ""
+		if len(pg.links) > 0 {
+			refs := make([]string, len(pg.links))
+			for j := range pg.links {
+				refs[j] = fmt.Sprintf("%d 0 R", n+2+j)
+			}
+			annots = " /Annots [" + strings.Join(refs, " ") + "]"
+		}
+		pw.object(n, fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] /Resources << /Font << /F1 4 0 R /F2 5 0 R /F3 6 0 R /F4 7 0 R >> >> /Contents %d 0 R%s >>",
+			num(pageWidth), num(pageHeight), n+1, annots))
+		if err := pw.stream(n+1, pg.content.Bytes()); err != nil {
+			return err
+		}
+		for j, link := range pg.links {
+			pw.object(n+2+j, fmt.Sprintf("<< /Type /Annot /Subtype /Link /Rect [%s %s %s %s] /Border [0 0 0] /A << /S /URI /URI (%s) >> >>",
+				num(link.x1), num(link.y1), num(link.x2), num(link.y2), escapeString([]byte(link.uri))))
+		}
+	}
+
+	xref := pw.buf.Len()
+	fmt.Fprintf(&pw.buf, "xref\n0 %d\n0000000000 65535 f \n", len(pw.offsets)+1)
+	for _, offset := range pw.offsets {
+		fmt.Fprintf(&pw.buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&pw.buf, "trailer\n<< /Size %d /Root 1 0 R /Info 3 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(pw.offsets)+1, xref)
+
+	_, err := w.Write(pw.buf.Bytes())
+	return err
+}
+
+// writer assembles the file, recording where each object starts for the
+// cross-reference table
+type writer struct {
+	buf     bytes.Buffer
+	offsets []int // Indexed by object number - 1
+}
+
+func (pw *writer) object(n int, body string) {
+	pw.offsets[n-1] = pw.buf.Len()
+	fmt.Fprintf(&pw.buf, "%d 0 obj\n%s\nendobj\n", n, body)
+}
+
+// stream writes a compressed content stream
+func (pw *writer) stream(n int, data []byte) error {
+	var compressed bytes.Buffer
+	zw := zlib.NewWriter(&compressed)
+	if _, err := zw.Write(data); err != nil {
+		return fmt.Errorf("failed to compress page contents: %v", err)
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("failed to compress page contents: %v", err)
+	}
+
+	pw.offsets[n-1] = pw.buf.Len()
+	fmt.Fprintf(&pw.buf, "%d 0 obj\n<< /Length %d /Filter /FlateDecode >>\nstream\n", n, compressed.Len())
+	pw.buf.Write(compressed.Bytes())
+	pw.buf.WriteString("\nendstream\nendobj\n")
+	return nil
+}
+
+// escapeString escapes bytes for a PDF literal string, without the parentheses
+func escapeString(b []byte) string {
+	var s strings.Builder
+	for _, c := range b {
+		switch c {
+		case '\\', '(', ')':
+			s.WriteByte('\\')
+			s.WriteByte(c)
+		case '\r':
+			s.WriteString(`\r`)
+		case '\n':
+			s.WriteString(`\n`)
+		default:
+			s.WriteByte(c)
+		}
+	}
+	return s.String()
+}
+
+// textString encodes s as a UTF-16 hex string, which PDF viewers show in
+// document properties whatever its characters
+func textString(s string) string {
+	var b strings.Builder
+	b.WriteString("<FEFF")
+	for _, u := range utf16.Encode([]rune(s)) {
+		fmt.Fprintf(&b, "%04X", u)
+	}
+	b.WriteString(">")
+	return b.String()
+}
+
+// num formats a coordinate with at most two decimals
+func num(v float64) string {
+	s := strconv.FormatFloat(v, 'f', 2, 64)
+	s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	if s == "-0" {
+		return "0"
+	}
+	return s
+}
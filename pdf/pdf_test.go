@@ -0,0 +1,240 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/daveonthegit/Personal_Portfolio/richtext"
+)
+
+func TestDocumentStructure(t *testing.T) {
+	doc := New()
+	doc.Title = "Jane Doe - Résumé"
+	doc.Author = "Jane Doe"
+	doc.Paragraph(richtext.StyleTitle, richtext.Text("Jane Doe"))
+	doc.Paragraph(richtext.StyleSubtitle, richtext.Link("jane@example.com", "mailto:jane@example.com"), richtext.Text(" | "), richtext.Link("example.com/a (b)", "https://example.com/?a=(1)"))
+	doc.Heading(1, richtext.Text("Experience"))
+	doc.Heading(2, richtext.Bold("Engineer"), richtext.Text("\tJan 2024 – Present"))
+	doc.Paragraph(richtext.StyleDetail, richtext.Text("R&D (Lab) \\ Co.\tNew York, NY"))
+	doc.Bullet(richtext.Text("Shipped things"), richtext.Italic(" quickly"))
+	doc.Paragraph(richtext.StyleNormal, richtext.Text("control \x01 characters\nand a break, plus 日本"))
+
+	data, err := doc.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	objects := parse(t, data)
+
+	if got := len(pageObjects(objects)); got != 1 {
+		t.Errorf("got %d pages, want 1", got)
+	}
+
+	var links []string
+	for _, obj := range objects {
+		if m := regexp.MustCompile(`/URI \((.*)\) >>`).FindStringSubmatch(obj.dict); m != nil {
+			links = append(links, m[1])
+		}
+	}
+	want := []string{"mailto:jane@example.com", `https://example.com/?a=\(1\)`}
+	if strings.Join(links, " ") != strings.Join(want, " ") {
+		t.Errorf("links = %q, want %q", links, want)
+	}
+
+	content := pageContents(t, objects)
+	for _, text := range []string{
+		"(Jane Doe)",
+		`(R&D \(Lab\) \\ Co.)`,
+		"(Jan 2024 \x96 Present)", // En dash in WinAnsiEncoding
+		"(\x95)",                  // Bullet
+		"(Shipped things)",
+		"(quickly)",
+		"plus ??)", // Characters the fonts cannot show
+	} {
+		if !strings.Contains(content, text) {
+			t.Errorf("page contents missing %q", text)
+		}
+	}
+	if strings.Contains(content, "\x01") {
+		t.Error("control character written to page contents")
+	}
+	if !strings.Contains(objects[3].dict, "/Title <FEFF004A") {
+		t.Errorf("document information = %s", objects[3].dict)
+	}
+}
+
+func TestPageBreaks(t *testing.T) {
+	doc := New()
+	for i := 0; i < 40; i++ {
+		doc.Heading(1, richtext.Text(fmt.Sprintf("Section %d", i)))
+		doc.Paragraph(richtext.StyleNormal, richtext.Text(strings.Repeat("lorem ipsum dolor sit amet ", 12)))
+	}
+	data, err := doc.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	objects := parse(t, data)
+
+	pages := pageObjects(objects)
+	if len(pages) < 3 {
+		t.Fatalf("got %d pages, want at least 3", len(pages))
+	}
+	for _, n := range pages {
+		content := contents(t, objects, n)
+		// No page may end with a section heading
+		headings := strings.LastIndex(content, "(Section ")
+		body := strings.LastIndex(content, "(lorem ")
+		if headings > body {
+			t.Errorf("page object %d ends with a heading", n)
+		}
+		for _, m := range regexp.MustCompile(`Tf ([0-9.]+) ([0-9.]+) Td`).FindAllStringSubmatch(content, -1) {
+			x, _ := strconv.ParseFloat(m[1], 64)
+			y, _ := strconv.ParseFloat(m[2], 64)
+			if x < pageMargin-1 || x > pageWidth-pageMargin || y < pageMargin || y > pageHeight-pageMargin {
+				t.Errorf("text at (%v, %v) is outside the margins", x, y)
+			}
+		}
+	}
+}
+
+func TestWrapsLongParagraphs(t *testing.T) {
+	st := styles[richtext.StyleNormal]
+	lines := st.lines([]richtext.Run{richtext.Text(strings.Repeat("word ", 200))}, textWidth)
+	if len(lines) < 2 {
+		t.Fatalf("got %d lines, want the paragraph wrapped", len(lines))
+	}
+	for i, ln := range lines {
+		if ln.width > textWidth {
+			t.Errorf("line %d is %v wide, more than %v", i, ln.width, textWidth)
+		}
+	}
+
+	// Runs without a space between them form one word
+	lines = st.lines([]richtext.Run{richtext.Bold("Go"), richtext.Text(","), richtext.Text(" Rust")}, textWidth)
+	if len(lines) != 1 || len(lines[0].words) != 2 || len(lines[0].words[0]) != 2 {
+		t.Errorf("lines = %+v, want two words, the first of two fragments", lines)
+	}
+}
+
+func TestDeterministic(t *testing.T) {
+	build := func() []byte {
+		doc := New()
+		doc.Paragraph(richtext.StyleTitle, richtext.Text("Jane Doe"))
+		doc.Bullet(richtext.Link("example.com", "https://example.com"))
+		data, err := doc.Bytes()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data
+	}
+	if !bytes.Equal(build(), build()) {
+		t.Error("the same document produced different bytes")
+	}
+}
+
+type object struct {
+	dict   string
+	stream []byte
+}
+
+var objectPattern = regexp.MustCompile(`^(\d+) 0 obj\n`)
+
+// parse checks the file header, trailer and cross-reference table, and
+// returns the objects by number
+func parse(t *testing.T, data []byte) map[int]object {
+	t.Helper()
+	if !bytes.HasPrefix(data, []byte("%PDF-1.4\n")) {
+		t.Fatalf("missing PDF header: %q", data[:min(len(data), 16)])
+	}
+	if !bytes.HasSuffix(data, []byte("%%EOF\n")) {
+		t.Fatal("missing end of file marker")
+	}
+
+	m := regexp.MustCompile(`startxref\n(\d+)\n%%EOF\n$`).FindSubmatch(data)
+	if m == nil {
+		t.Fatal("missing startxref")
+	}
+	xref, _ := strconv.Atoi(string(m[1]))
+	if !bytes.HasPrefix(data[xref:], []byte("xref\n0 ")) {
+		t.Fatalf("startxref %d does not point at the xref table", xref)
+	}
+
+	lines := strings.Split(string(data[xref:]), "\n")
+	count, _ := strconv.Atoi(strings.Fields(lines[1])[1])
+	if !strings.Contains(string(data[xref:]), fmt.Sprintf("/Size %d ", count)) {
+		t.Errorf("trailer /Size does not match the %d xref entries", count)
+	}
+
+	objects := make(map[int]object)
+	for n := 1; n < count; n++ {
+		entry := lines[2+n]
+		if len(entry) != 19 || !strings.HasSuffix(entry, " 00000 n ") {
+			t.Fatalf("malformed xref entry %q", entry)
+		}
+		offset, _ := strconv.Atoi(entry[:10])
+		rest := data[offset:]
+		om := objectPattern.FindSubmatch(rest)
+		if om == nil || string(om[1]) != strconv.Itoa(n) {
+			t.Fatalf("xref entry for object %d points at %q", n, rest[:min(len(rest), 20)])
+		}
+		end := bytes.Index(rest, []byte("\nendobj\n"))
+		body := rest[len(om[0]):end]
+
+		obj := object{dict: string(body)}
+		if i := bytes.Index(body, []byte("\nstream\n")); i != -1 {
+			obj.dict = string(body[:i])
+			lm := regexp.MustCompile(`/Length (\d+)`).FindStringSubmatch(obj.dict)
+			length, _ := strconv.Atoi(lm[1])
+			stream := body[i+len("\nstream\n"):]
+			if !bytes.Equal(stream[length:], []byte("\nendstream")) {
+				t.Fatalf("object %d: /Length %d does not end at endstream", n, length)
+			}
+			obj.stream = stream[:length]
+		}
+		objects[n] = obj
+	}
+	return objects
+}
+
+// pageObjects returns the page object numbers listed in the page tree
+func pageObjects(objects map[int]object) []int {
+	var pages []int
+	kids := regexp.MustCompile(`/Kids \[([^\]]*)\]`).FindStringSubmatch(objects[2].dict)
+	for _, ref := range regexp.MustCompile(`(\d+) 0 R`).FindAllStringSubmatch(kids[1], -1) {
+		n, _ := strconv.Atoi(ref[1])
+		pages = append(pages, n)
+	}
+	return pages
+}
+
+// contents returns the decompressed content stream of a page
+func contents(t *testing.T, objects map[int]object, page int) string {
+	t.Helper()
+	m := regexp.MustCompile(`/Contents (\d+) 0 R`).FindStringSubmatch(objects[page].dict)
+	if m == nil {
+		t.Fatalf("page object %d has no contents", page)
+	}
+	n, _ := strconv.Atoi(m[1])
+	zr, err := zlib.NewReader(bytes.NewReader(objects[n].stream))
+	if err != nil {
+		t.Fatalf("object %d: %v", n, err)
+	}
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatalf("object %d: %v", n, err)
+	}
+	return string(data)
+}
+
+func pageContents(t *testing.T, objects map[int]object) string {
+	var all strings.Builder
+	for _, n := range pageObjects(objects) {
+		all.WriteString(contents(t, objects, n))
+	}
+	return all.String()
+}
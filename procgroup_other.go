@@ -0,0 +1,16 @@
+//go:build !unix
+
+package main
+
+import "os/exec"
+
+// setProcessGroup is a no-op on platforms without Unix process groups
+func setProcessGroup(cmd *exec.Cmd) {}
+
+// killProcessGroup kills cmd. Child processes it started may survive.
+func killProcessGroup(cmd *exec.Cmd) error {
+	if cmd.Process == nil {
+		return nil
+	}
+	return cmd.Process.Kill()
+}
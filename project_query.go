@@ -0,0 +1,261 @@
+package main
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxProjectPageSize caps the limit parameter of /api/projects
+const maxProjectPageSize = 100
+
+// ProjectQuery holds the filters, sort order and page requested from
+// /api/projects. Values within one filter are ORed together; different
+// filters are ANDed.
+type ProjectQuery struct {
+	Types        []string
+	Statuses     []string
+	DemoTypes    []string
+	Technologies []string
+	MatchAllTech bool // Require every technology instead of any of them
+	From         time.Time
+	To           time.Time
+	SortBy       string // "", "date" or "title"; empty keeps the curated order
+	Descending   bool
+	Limit        int // 0 returns every match
+	Offset       int
+}
+
+// ProjectPage is the JSON envelope returned by /api/projects
+type ProjectPage struct {
+	Status     string    `json:"status"`
+	Total      int       `json:"total"`     // Projects matching the filters
+	Available  int       `json:"available"` // Projects before filtering
+	Count      int       `json:"count"`     // Projects in this page
+	Offset     int       `json:"offset"`
+	Limit      int       `json:"limit"`
+	NextCursor string    `json:"next_cursor,omitempty"`
+	Projects   []Project `json:"projects"`
+}
+
+// ParseProjectQuery builds a ProjectQuery from URL query parameters:
+//
+//	type, status, demo_type   repeatable or comma separated
+//	technology                repeatable or comma separated
+//	tech_match                "any" (default) or "all"
+//	from, to                  inclusive dates, YYYY-MM-DD
+//	sort                      "date" or "title"
+//	order                     "asc" or "desc" (default desc for date, asc for title)
+//	limit, offset             page size and start
+//	cursor                    opaque next_cursor from a previous page
+func ParseProjectQuery(values url.Values) (ProjectQuery, error) {
+	q := ProjectQuery{
+		Types:        listParam(values, "type"),
+		Statuses:     listParam(values, "status"),
+		DemoTypes:    listParam(values, "demo_type"),
+		Technologies: listParam(values, "technology"),
+	}
+
+	switch strings.ToLower(values.Get("tech_match")) {
+	case "", "any", "or":
+	case "all", "and":
+		q.MatchAllTech = true
+	default:
+		return q, fmt.Errorf("tech_match must be \"any\" or \"all\"")
+	}
+
+	var err error
+	if v := values.Get("from"); v != "" {
+		if q.From, err = time.Parse("2006-01-02", v); err != nil {
+			return q, fmt.Errorf("from must be a date in YYYY-MM-DD format")
+		}
+	}
+	if v := values.Get("to"); v != "" {
+		if q.To, err = time.Parse("2006-01-02", v); err != nil {
+			return q, fmt.Errorf("to must be a date in YYYY-MM-DD format")
+		}
+	}
+	if !q.From.IsZero() && !q.To.IsZero() && q.To.Before(q.From) {
+		return q, fmt.Errorf("to must not be before from")
+	}
+
+	q.SortBy = strings.ToLower(values.Get("sort"))
+	switch q.SortBy {
+	case "":
+	case "date":
+		q.Descending = true
+	case "title":
+	default:
+		return q, fmt.Errorf("sort must be \"date\" or \"title\"")
+	}
+
+	switch strings.ToLower(values.Get("order")) {
+	case "":
+	case "asc":
+		q.Descending = false
+	case "desc":
+		q.Descending = true
+	default:
+		return q, fmt.Errorf("order must be \"asc\" or \"desc\"")
+	}
+
+	if q.Limit, err = intParam(values, "limit"); err != nil {
+		return q, err
+	}
+	if q.Limit > maxProjectPageSize {
+		return q, fmt.Errorf("limit must be at most %d", maxProjectPageSize)
+	}
+	if q.Offset, err = intParam(values, "offset"); err != nil {
+		return q, err
+	}
+
+	if cursor := values.Get("cursor"); cursor != "" {
+		if q.Offset, err = decodeCursor(cursor); err != nil {
+			return q, err
+		}
+	}
+
+	return q, nil
+}
+
+// Apply filters, sorts and pages projects. The input slice is not modified.
+func (q ProjectQuery) Apply(projects []Project) ProjectPage {
+	matched := make([]Project, 0, len(projects))
+	for _, project := range projects {
+		if q.matches(project) {
+			matched = append(matched, project)
+		}
+	}
+
+	switch q.SortBy {
+	case "date":
+		sort.SliceStable(matched, func(i, j int) bool {
+			if q.Descending {
+				return matched[i].Date.After(matched[j].Date)
+			}
+			return matched[i].Date.Before(matched[j].Date)
+		})
+	case "title":
+		sort.SliceStable(matched, func(i, j int) bool {
+			a, b := strings.ToLower(matched[i].Title), strings.ToLower(matched[j].Title)
+			if q.Descending {
+				return a > b
+			}
+			return a < b
+		})
+	}
+
+	page := ProjectPage{
+		Status:    "success",
+		Total:     len(matched),
+		Available: len(projects),
+		Offset:    q.Offset,
+		Limit:     q.Limit,
+	}
+
+	start := min(q.Offset, len(matched))
+	end := len(matched)
+	if q.Limit > 0 {
+		end = min(start+q.Limit, len(matched))
+	}
+
+	page.Projects = matched[start:end]
+	page.Count = len(page.Projects)
+	if end < len(matched) {
+		page.NextCursor = encodeCursor(end)
+	}
+
+	return page
+}
+
+func (q ProjectQuery) matches(p Project) bool {
+	if len(q.Types) > 0 && !containsFold(q.Types, p.Type) {
+		return false
+	}
+	if len(q.Statuses) > 0 && !containsFold(q.Statuses, p.Status) {
+		return false
+	}
+	if len(q.DemoTypes) > 0 && !containsFold(q.DemoTypes, p.DemoType) {
+		return false
+	}
+	if !q.From.IsZero() && p.Date.Before(q.From) {
+		return false
+	}
+	if !q.To.IsZero() && p.Date.After(q.To) {
+		return false
+	}
+
+	if len(q.Technologies) > 0 {
+		found := 0
+		for _, tech := range q.Technologies {
+			if containsFold(p.Technologies, tech) {
+				found++
+			}
+		}
+		if q.MatchAllTech && found < len(q.Technologies) {
+			return false
+		}
+		if !q.MatchAllTech && found == 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// listParam collects a repeatable parameter, also splitting comma separated
+// values, and drops the "all" placeholder used by the filter buttons
+func listParam(values url.Values, key string) []string {
+	var list []string
+	for _, raw := range values[key] {
+		for _, v := range strings.Split(raw, ",") {
+			v = strings.TrimSpace(v)
+			if v != "" && v != "all" {
+				list = append(list, v)
+			}
+		}
+	}
+	return list
+}
+
+func intParam(values url.Values, key string) (int, error) {
+	v := values.Get(key)
+	if v == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("%s must be a non-negative integer", key)
+	}
+	return n, nil
+}
+
+// Cursors are opaque to clients but simply encode the next offset
+func encodeCursor(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte("offset:" + strconv.Itoa(offset)))
+}
+
+func decodeCursor(cursor string) (int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err == nil {
+		if v, ok := strings.CutPrefix(string(raw), "offset:"); ok {
+			if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+				return n, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("invalid cursor")
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
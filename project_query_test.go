@@ -0,0 +1,164 @@
+package main
+
+import (
+	"encoding/base64"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+var queryTestProjects = []Project{
+	{ID: "site", Title: "Site", Type: "web", Status: "active", DemoType: "live", Technologies: []string{"Go", "HTML"}, Date: time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)},
+	{ID: "bot", Title: "bot", Type: "tool", Status: "archived", DemoType: "none", Technologies: []string{"JavaScript"}, Date: time.Date(2022, 9, 1, 0, 0, 0, 0, time.UTC)},
+	{ID: "rsa", Title: "RSA", Type: "security", Status: "active", DemoType: "none", Technologies: []string{"Python", "C"}, Date: time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)},
+	{ID: "game", Title: "Game", Type: "web", Status: "in-development", DemoType: "hosted", Technologies: []string{"go", "JavaScript"}, Date: time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)},
+	{ID: "labs", Title: "Labs", Type: "academic", Status: "archived", DemoType: "none", Technologies: []string{"C"}, Date: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)},
+}
+
+func queryIDs(page ProjectPage) string {
+	var ids []string
+	for _, p := range page.Projects {
+		ids = append(ids, p.ID)
+	}
+	return strings.Join(ids, ",")
+}
+
+func TestProjectQuery(t *testing.T) {
+	for _, tc := range []struct {
+		query string
+		want  string
+	}{
+		{"", "site,bot,rsa,game,labs"},
+		{"type=web", "site,game"},
+		{"type=web,security&type=tool", "site,bot,rsa,game"},
+		{"type=all", "site,bot,rsa,game,labs"},
+		{"status=ARCHIVED", "bot,labs"},
+		{"demo_type=none&type=academic", "labs"},
+		{"technology=go", "site,game"},
+		{"technology=Go,C", "site,rsa,game,labs"},
+		{"technology=Go&technology=JavaScript&tech_match=all", "game"},
+		{"from=2025-02-01", "site,rsa,game"},
+		{"from=2024-01-01&to=2025-02-01", "rsa,game,labs"},
+		{"sort=date", "site,rsa,game,labs,bot"},
+		{"sort=date&order=asc", "bot,labs,rsa,game,site"},
+		{"sort=title", "bot,game,labs,rsa,site"},
+		{"sort=title&order=desc", "site,rsa,labs,game,bot"},
+		{"order=desc", "site,bot,rsa,game,labs"},
+	} {
+		values, _ := url.ParseQuery(tc.query)
+		q, err := ParseProjectQuery(values)
+		if err != nil {
+			t.Errorf("%q: %v", tc.query, err)
+			continue
+		}
+		if got := queryIDs(q.Apply(queryTestProjects)); got != tc.want {
+			t.Errorf("%q = %s, want %s", tc.query, got, tc.want)
+		}
+	}
+}
+
+func TestProjectQueryErrors(t *testing.T) {
+	for query, want := range map[string]string{
+		"sort=name":                     `sort must be "date" or "title"`,
+		"order=up":                      `order must be "asc" or "desc"`,
+		"tech_match=some":               `tech_match must be "any" or "all"`,
+		"limit=ten":                     "limit must be a non-negative integer",
+		"limit=-1":                      "limit must be a non-negative integer",
+		"limit=101":                     "limit must be at most 100",
+		"offset=-5":                     "offset must be a non-negative integer",
+		"from=2025-13-01":               "from must be a date in YYYY-MM-DD format",
+		"to=yesterday":                  "to must be a date in YYYY-MM-DD format",
+		"from=2025-02-01&to=2025-01-1":  "to must be a date in YYYY-MM-DD format",
+		"from=2025-02-01&to=2025-01-01": "to must not be before from",
+		"cursor=!!!":                    "invalid cursor",
+		"cursor=" + base64.RawURLEncoding.EncodeToString([]byte("offset:-1")): "invalid cursor",
+		"cursor=" + base64.RawURLEncoding.EncodeToString([]byte("page:2")):    "invalid cursor",
+	} {
+		values, _ := url.ParseQuery(query)
+		if _, err := ParseProjectQuery(values); err == nil || err.Error() != want {
+			t.Errorf("%q: got %v, want %q", query, err, want)
+		}
+	}
+
+	values := url.Values{"limit": {"100"}}
+	if q, err := ParseProjectQuery(values); err != nil || q.Limit != 100 {
+		t.Errorf("limit=100 got %+v, %v", q, err)
+	}
+}
+
+func TestProjectQueryPaging(t *testing.T) {
+	page := func(query string) ProjectPage {
+		t.Helper()
+		values, _ := url.ParseQuery(query)
+		q, err := ParseProjectQuery(values)
+		if err != nil {
+			t.Fatalf("%q: %v", query, err)
+		}
+		return q.Apply(queryTestProjects)
+	}
+
+	// Walking the cursors visits every match once, in order
+	var seen []string
+	query := "type=web,security,academic&limit=2"
+	for pages := 0; ; pages++ {
+		p := page(query)
+		if p.Total != 4 || p.Available != 5 || p.Limit != 2 || p.Count != len(p.Projects) {
+			t.Fatalf("%q: got total %d, available %d, limit %d and count %d of %d", query, p.Total, p.Available, p.Limit, p.Count, len(p.Projects))
+		}
+		seen = append(seen, queryIDs(p))
+		if p.NextCursor == "" {
+			break
+		}
+		if pages > 3 {
+			t.Fatal("cursors never ran out")
+		}
+		if offset, err := decodeCursor(p.NextCursor); err != nil || offset != p.Offset+p.Count {
+			t.Fatalf("next_cursor %q decodes to %d, %v, want %d", p.NextCursor, offset, err, p.Offset+p.Count)
+		}
+		query = "type=web,security,academic&limit=2&cursor=" + p.NextCursor
+	}
+	if got := strings.Join(seen, "|"); got != "site,rsa|game,labs" {
+		t.Errorf("pages = %s", got)
+	}
+
+	for _, tc := range []struct {
+		query      string
+		want       string
+		count      int
+		nextOffset int // -1 for no next_cursor
+	}{
+		// A page that ends exactly at the last match has no next page
+		{"limit=5", "site,bot,rsa,game,labs", 5, -1},
+		{"limit=4", "site,bot,rsa,game", 4, 4},
+		{"offset=3&limit=2", "game,labs", 2, -1},
+		{"offset=3&limit=1", "game", 1, 4},
+		// Past the end is an empty page, not an error
+		{"offset=5", "", 0, -1},
+		{"offset=50&limit=2", "", 0, -1},
+		// No limit returns the rest
+		{"offset=2", "rsa,game,labs", 3, -1},
+		// A cursor overrides offset
+		{"offset=0&limit=2&cursor=" + encodeCursor(3), "game,labs", 2, -1},
+	} {
+		p := page(tc.query)
+		if queryIDs(p) != tc.want || p.Count != tc.count || p.Total != 5 || p.Available != 5 {
+			t.Errorf("%q: got %s (count %d, total %d, available %d), want %s", tc.query, queryIDs(p), p.Count, p.Total, p.Available, tc.want)
+		}
+		next := -1
+		if p.NextCursor != "" {
+			next, _ = decodeCursor(p.NextCursor)
+		}
+		if next != tc.nextOffset {
+			t.Errorf("%q: next_cursor is offset %d, want %d", tc.query, next, tc.nextOffset)
+		}
+	}
+
+	// Filters count against total but not available
+	if p := page("status=archived&limit=1"); p.Total != 2 || p.Available != 5 || p.Count != 1 || p.NextCursor == "" {
+		t.Errorf("got %+v", p)
+	}
+	if p := page("type=mobile"); p.Total != 0 || p.Available != 5 || p.Projects == nil {
+		t.Errorf("no matches got %+v, want an empty list", p)
+	}
+}
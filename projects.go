@@ -19,9 +19,13 @@ type Project struct {
 	HostedPath   string    `json:"hosted_path" yaml:"hosted_path"` // Path to hosted project files
 	Status       string    `json:"status" yaml:"status"`           // "active", "archived", "in-development"
 	Date         time.Time `json:"date" yaml:"date"`
+	Highlights   []string  `json:"highlights,omitempty" yaml:"highlights"` // Resume bullet points
+	Order        int       `json:"-" yaml:"order"`                         // Display position; 0 sorts after ordered projects
 }
 
-// LoadProjects returns all portfolio projects with live demo support
+// LoadProjects returns the built-in project list. Projects normally come from
+// the content directory via LoadProjectsFromDir; this list is only used as a
+// fallback when that directory is missing or invalid.
 func LoadProjects() []Project {
 	return []Project{
 		{
@@ -79,6 +83,11 @@ func LoadProjects() []Project {
 			DemoURL:      "",
 			Status:       "active",
 			Date:         time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC),
+			Highlights: []string{
+				"Built a GCC plugin to add compile-time kernel hardening with ABI randomization and data structure obfuscation",
+				"Automated pointer encryption and modular ABI handling while ensuring compatibility with kernel modules",
+				"Maintained less than 5% performance overhead while enhancing kernel security under malicious hypervisor threat models",
+			},
 		},
 		{
 			ID:           "minesweeper-game",
@@ -137,6 +146,11 @@ func LoadProjects() []Project {
 			DemoURL:      "",
 			Status:       "active",
 			Date:         time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC),
+			Highlights: []string{
+				"Automated RSA key recovery and TLS decryption by scripting modulus analysis and key extraction in Python",
+				"Factored 1024-bit RSA keys using GCD-based methods and verified with MSieve and Cado-NFS",
+				"Analyzed decrypted TLS session data with Wireshark to evaluate cryptographic weaknesses",
+			},
 		},
 		{
 			ID:           "hs-projects",
@@ -278,97 +292,34 @@ func LoadProjects() []Project {
 			Status:       "archived",
 			Date:         time.Date(2023, 12, 9, 0, 0, 0, 0, time.UTC),
 		},
+		{
+			ID:           "b2b-task-management",
+			Title:        "B2B Task Management Platform",
+			Description:  "Task management platform built during my Unadat internship, unifying the B2C and B2B flows in one application. Mobile-optimized task system with escalation logic, multi-round negotiation and role-based access across multi-tier organizational hierarchies.",
+			Image:        "",
+			Technologies: []string{"PHP", "JavaScript", "jQuery", "MySQL", "CSS", "GCP"},
+			Type:         "web",
+			GitHubURL:    "",
+			LiveURL:      "",
+			DemoType:     "none",
+			DemoURL:      "",
+			Status:       "archived",
+			Date:         time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC),
+			Highlights: []string{
+				"Built a mobile-optimized task system for 100+ concurrent users with escalation logic and multi-round negotiation, cutting delays by 30%",
+				"Implemented role-based access across multi-tier hierarchies, strengthening security and enabling efficient delegation",
+				"Unified B2C + B2B flows in one platform, reducing development overhead by 40% while maintaining feature continuity",
+			},
+		},
 	}
 }
 
-// GetProjectsByStatus returns projects filtered by status
-func GetProjectsByStatus(status string) []Project {
-	allProjects := LoadProjects()
-	var filtered []Project
-	for _, project := range allProjects {
-		if project.Status == status {
-			filtered = append(filtered, project)
-		}
-	}
-	return filtered
-}
-
-// GetProjectsWithLiveDemo returns projects that have live demos
-func GetProjectsWithLiveDemo() []Project {
-	allProjects := LoadProjects()
-	var liveProjects []Project
-	for _, project := range allProjects {
-		if project.DemoType == "live" && project.LiveURL != "" {
-			liveProjects = append(liveProjects, project)
-		}
-	}
-	return liveProjects
-}
-
-// GetProjectsByTechnology returns projects filtered by technology
-func GetProjectsByTechnology(technology string) []Project {
-	allProjects := LoadProjects()
-	var filtered []Project
-	for _, project := range allProjects {
-		for _, tech := range project.Technologies {
-			if tech == technology {
-				filtered = append(filtered, project)
-				break
-			}
-		}
-	}
-	return filtered
-}
-
-// GetProjectsByType returns projects filtered by type
-func GetProjectsByType(projectType string) []Project {
-	allProjects := LoadProjects()
-	var filtered []Project
-	for _, project := range allProjects {
-		if project.Type == projectType {
-			filtered = append(filtered, project)
-		}
-	}
-	return filtered
-}
-
-// GetAvailableTypes returns all unique project types
-func GetAvailableTypes() []string {
-	allProjects := LoadProjects()
-	typeMap := make(map[string]bool)
-
-	for _, project := range allProjects {
-		typeMap[project.Type] = true
-	}
-
-	var types []string
-	for projectType := range typeMap {
-		types = append(types, projectType)
-	}
-
-	return types
-}
-
-// GetProjectsByTypeAndStatus returns projects filtered by both type and status
-func GetProjectsByTypeAndStatus(projectType, status string) []Project {
-	allProjects := LoadProjects()
-	var filtered []Project
-	for _, project := range allProjects {
-		if project.Type == projectType && project.Status == status {
-			filtered = append(filtered, project)
-		}
-	}
-	return filtered
-}
-
-// GetHostedProjects returns projects that are hosted on this site
-func GetHostedProjects() []Project {
-	allProjects := LoadProjects()
-	var hosted []Project
-	for _, project := range allProjects {
-		if project.DemoType == "hosted" && project.HostedPath != "" {
-			hosted = append(hosted, project)
+// ProjectIndex returns the position of the project with the given ID, or -1
+func ProjectIndex(projects []Project, id string) int {
+	for i, project := range projects {
+		if project.ID == id {
+			return i
 		}
 	}
-	return hosted
+	return -1
 }
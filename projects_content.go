@@ -0,0 +1,259 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ContentError describes a problem with a single content file, pointing at
+// the offending line when it is known.
+type ContentError struct {
+	File string
+	Line int
+	Msg  string
+}
+
+func (e *ContentError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("%s:%d: %s", e.File, e.Line, e.Msg)
+	}
+	return fmt.Sprintf("%s: %s", e.File, e.Msg)
+}
+
+var (
+	projectIDPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+	validProjectTypes    = []string{"web", "mobile", "ai", "security", "academic", "research", "tool"}
+	validProjectStatuses = []string{"active", "archived", "in-development"}
+	validDemoTypes       = []string{"live", "video", "screenshot", "hosted", "none"}
+
+	// projectFileKeys lists every key accepted in a project file
+	projectFileKeys = []string{
+		"id", "title", "description", "image", "technologies", "type",
+		"github_url", "live_url", "demo_type", "demo_url", "hosted_path",
+		"status", "date", "order", "highlights",
+	}
+)
+
+// LoadProjectsFromDir reads one project per *.yaml, *.yml or *.json file in
+// dir, validates it and returns the projects in a stable order: explicit
+// "order" first, then newest date, then ID. All problems found are returned
+// together as ContentErrors so they can be fixed in one pass.
+func LoadProjectsFromDir(dir string) ([]Project, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var projects []Project
+	var errs []error
+	seen := make(map[string]string)
+
+	for _, entry := range entries {
+		if entry.IsDir() || !isProjectFile(entry.Name()) {
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		project, fileErrs := parseProjectFile(path)
+		if len(fileErrs) > 0 {
+			errs = append(errs, fileErrs...)
+			continue
+		}
+
+		if other, ok := seen[project.ID]; ok {
+			errs = append(errs, &ContentError{File: path, Msg: fmt.Sprintf("duplicate project id %q (also defined in %s)", project.ID, other)})
+			continue
+		}
+		seen[project.ID] = path
+		projects = append(projects, project)
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	if len(projects) == 0 {
+		return nil, fmt.Errorf("no project files found in %s", dir)
+	}
+
+	sortProjects(projects)
+	return projects, nil
+}
+
+func isProjectFile(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".yaml", ".yml", ".json":
+		return true
+	}
+	return false
+}
+
+// parseProjectFile decodes a single project file. JSON is a subset of YAML, so
+// both formats go through the YAML node tree, which gives line numbers for
+// every key.
+func parseProjectFile(path string) (Project, []error) {
+	var project Project
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return project, []error{&ContentError{File: path, Msg: err.Error()}}
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return project, []error{&ContentError{File: path, Msg: err.Error()}}
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return project, []error{&ContentError{File: path, Line: 1, Msg: "expected a mapping of project fields"}}
+	}
+	root := doc.Content[0]
+
+	var errs []error
+	lines := make(map[string]int)
+	fields := &yaml.Node{Kind: yaml.MappingNode, Tag: root.Tag}
+
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key, value := root.Content[i], root.Content[i+1]
+		lines[key.Value] = key.Line
+
+		if !contains(projectFileKeys, key.Value) {
+			errs = append(errs, &ContentError{File: path, Line: key.Line, Msg: fmt.Sprintf("unknown field %q", key.Value)})
+			continue
+		}
+
+		// Dates are parsed by hand so quoted JSON strings and bare YAML
+		// timestamps behave the same
+		if key.Value == "date" {
+			date, err := parseContentDate(value.Value)
+			if err != nil {
+				errs = append(errs, &ContentError{File: path, Line: value.Line, Msg: err.Error()})
+				continue
+			}
+			project.Date = date
+			continue
+		}
+
+		fields.Content = append(fields.Content, key, value)
+	}
+
+	if err := fields.Decode(&project); err != nil {
+		errs = append(errs, &ContentError{File: path, Msg: err.Error()})
+	}
+
+	for _, msg := range validateProject(project, lines) {
+		errs = append(errs, &ContentError{File: path, Line: msg.line, Msg: msg.text})
+	}
+
+	return project, errs
+}
+
+type validationMsg struct {
+	line int
+	text string
+}
+
+// validateProject checks required fields and enumerations. lines maps field
+// names to the line they were declared on so messages can point at them.
+func validateProject(p Project, lines map[string]int) []validationMsg {
+	var msgs []validationMsg
+	add := func(field, format string, args ...interface{}) {
+		line := lines[field]
+		if line == 0 {
+			line = 1
+		}
+		msgs = append(msgs, validationMsg{line: line, text: fmt.Sprintf(format, args...)})
+	}
+
+	required := map[string]string{
+		"id":          p.ID,
+		"title":       p.Title,
+		"description": p.Description,
+		"type":        p.Type,
+		"status":      p.Status,
+	}
+	for _, field := range []string{"id", "title", "description", "type", "status"} {
+		if strings.TrimSpace(required[field]) == "" {
+			add(field, "missing required field %q", field)
+		}
+	}
+	if _, ok := lines["date"]; !ok {
+		add("date", "missing required field %q", "date")
+	}
+
+	if p.ID != "" && !projectIDPattern.MatchString(p.ID) {
+		add("id", "id %q must be lowercase letters, digits and dashes", p.ID)
+	}
+	if p.Type != "" && !contains(validProjectTypes, p.Type) {
+		add("type", "type %q must be one of %s", p.Type, strings.Join(validProjectTypes, ", "))
+	}
+	if p.Status != "" && !contains(validProjectStatuses, p.Status) {
+		add("status", "status %q must be one of %s", p.Status, strings.Join(validProjectStatuses, ", "))
+	}
+	if p.DemoType != "" && !contains(validDemoTypes, p.DemoType) {
+		add("demo_type", "demo_type %q must be one of %s", p.DemoType, strings.Join(validDemoTypes, ", "))
+	}
+
+	switch p.DemoType {
+	case "hosted":
+		if p.HostedPath == "" {
+			add("demo_type", "hosted demos need a hosted_path")
+		}
+	case "live":
+		if p.LiveURL == "" && p.DemoURL == "" {
+			add("demo_type", "live demos need a live_url or demo_url")
+		}
+	case "video", "screenshot":
+		if p.DemoURL == "" {
+			add("demo_type", "%s demos need a demo_url", p.DemoType)
+		}
+	}
+
+	return msgs
+}
+
+// parseContentDate accepts ISO dates ("2025-09-11") and full RFC 3339 timestamps
+func parseContentDate(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.UTC(), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q, expected YYYY-MM-DD", value)
+}
+
+// sortProjects orders projects by explicit order, then newest first, then ID
+func sortProjects(projects []Project) {
+	sort.SliceStable(projects, func(i, j int) bool {
+		a, b := projects[i], projects[j]
+		if a.Order != b.Order {
+			// Projects without an explicit order go after ordered ones
+			if a.Order == 0 || b.Order == 0 {
+				return b.Order == 0
+			}
+			return a.Order < b.Order
+		}
+		if !a.Date.Equal(b.Date) {
+			return a.Date.After(b.Date)
+		}
+		return a.ID < b.ID
+	})
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeProjects writes each named file into a new projects directory
+func writeProjects(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// projectYAML is a valid project file for id, dated 2025-01-01 unless extra
+// sets a date
+func projectYAML(id, extra string) string {
+	s := "id: " + id + "\ntitle: Project " + id + "\ndescription: About " + id + "\ntype: web\nstatus: active\n"
+	if !strings.Contains(extra, "date:") {
+		s += "date: 2025-01-01\n"
+	}
+	return s + extra
+}
+
+// wantErrors checks err mentions each message, prefixed with dir
+func wantErrors(t *testing.T, err error, dir string, msgs ...string) {
+	t.Helper()
+	if err == nil {
+		t.Fatal("invalid projects loaded")
+	}
+	for _, msg := range msgs {
+		if want := filepath.Join(dir, msg); !strings.Contains(err.Error(), want) {
+			t.Errorf("error does not mention %q:\n%v", want, err)
+		}
+	}
+}
+
+func TestLoadProjectsFromDir(t *testing.T) {
+	dir := writeProjects(t, map[string]string{
+		"a.yaml":   projectYAML("a", "technologies: [Go]\nhighlights: [one, two]\n"),
+		"b.json":   `{"id": "b", "title": "B", "description": "About b", "type": "tool", "status": "archived", "date": "2024-03-01", "demo_type": "none"}`,
+		"notes.md": "not a project",
+	})
+	os.Mkdir(filepath.Join(dir, "drafts.yaml"), 0755)
+
+	projects, err := LoadProjectsFromDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(projects) != 2 {
+		t.Fatalf("got %d projects, want 2", len(projects))
+	}
+	a := projects[0]
+	if a.ID != "a" || !a.Date.Equal(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)) || !reflect.DeepEqual(a.Technologies, []string{"Go"}) || !reflect.DeepEqual(a.Highlights, []string{"one", "two"}) {
+		t.Errorf("a loaded as %+v", a)
+	}
+	if b := projects[1]; b.ID != "b" || b.Type != "tool" || !b.Date.Equal(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("b loaded as %+v", b)
+	}
+}
+
+func TestLoadProjectsFromDirErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		files map[string]string
+		want  []string
+	}{
+		{
+			"syntax error",
+			map[string]string{"a.yaml": "id: a\ntitle: A\ndescription\ntype: web\n"},
+			[]string{"a.yaml: yaml: line 3: could not find expected ':'"},
+		},
+		{
+			"missing required fields",
+			map[string]string{"a.yaml": "id: a\ntype: web\n"},
+			[]string{
+				`a.yaml:1: missing required field "title"`,
+				`a.yaml:1: missing required field "description"`,
+				`a.yaml:1: missing required field "status"`,
+				`a.yaml:1: missing required field "date"`,
+			},
+		},
+		{
+			"invalid enums",
+			map[string]string{"a.yaml": "id: a\ntitle: A\ndescription: About a\ntype: website\nstatus: done\ndate: 2025-01-01\ndemo_type: hosted\n"},
+			[]string{
+				`a.yaml:4: type "website" must be one of web, mobile, ai, security, academic, research, tool`,
+				`a.yaml:5: status "done" must be one of active, archived, in-development`,
+				`a.yaml:7: hosted demos need a hosted_path`,
+			},
+		},
+		{
+			"bad fields",
+			map[string]string{"a.yaml": projectYAML("A_1", "tags: [x]\n") + "order: first\n"},
+			[]string{
+				`a.yaml:1: id "A_1" must be lowercase letters, digits and dashes`,
+				`a.yaml:7: unknown field "tags"`,
+				"a.yaml: yaml: unmarshal errors:\n  line 8:",
+			},
+		},
+		{
+			"bad date",
+			map[string]string{"a.json": "{\n  \"id\": \"a\",\n  \"date\": \"June 2025\"\n}"},
+			[]string{`a.json:3: invalid date "June 2025", expected YYYY-MM-DD`},
+		},
+		{
+			"duplicate id",
+			map[string]string{"a.yaml": projectYAML("same", ""), "b.yml": projectYAML("same", "")},
+			[]string{`b.yml: duplicate project id "same" (also defined in `},
+		},
+		{
+			"not a mapping",
+			map[string]string{"a.yaml": "- id: a\n"},
+			[]string{"a.yaml:1: expected a mapping of project fields"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := writeProjects(t, tc.files)
+			projects, err := LoadProjectsFromDir(dir)
+			if projects != nil {
+				t.Errorf("got projects %+v alongside errors", projects)
+			}
+			wantErrors(t, err, dir, tc.want...)
+		})
+	}
+
+	// Every broken file is reported, not just the first
+	dir := writeProjects(t, map[string]string{
+		"a.yaml": "id: a\n",
+		"b.yaml": projectYAML("b", "status: gone\n"),
+	})
+	_, err := LoadProjectsFromDir(dir)
+	wantErrors(t, err, dir, `a.yaml:1: missing required field "title"`, `b.yaml:`)
+
+	if _, err := LoadProjectsFromDir(writeProjects(t, nil)); err == nil || !strings.Contains(err.Error(), "no project files found") {
+		t.Errorf("empty directory got %v", err)
+	}
+}
+
+func TestLoadProjectsFromDirOrder(t *testing.T) {
+	dir := writeProjects(t, map[string]string{
+		"new.yaml":     projectYAML("new", "date: 2025-06-01\n"),
+		"old.yaml":     projectYAML("old", "date: 2020-06-01\n"),
+		"tie-b.yaml":   projectYAML("tie-b", ""),
+		"tie-a.yaml":   projectYAML("tie-a", ""),
+		"second.yaml":  projectYAML("second", "order: 2\n"),
+		"first.yaml":   projectYAML("first", "order: 1\ndate: 2019-01-01\n"),
+		"second2.yaml": projectYAML("second2", "order: 2\ndate: 2026-01-01\n"),
+	})
+	projects, err := LoadProjectsFromDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, p := range projects {
+		ids = append(ids, p.ID)
+	}
+	// explicit order first, then newest, then ID
+	if got, want := strings.Join(ids, ","), "first,second2,second,new,tie-a,tie-b,old"; got != want {
+		t.Errorf("order = %s, want %s", got, want)
+	}
+}
+
+func TestLoadSiteContentFallsBackToBuiltInProjects(t *testing.T) {
+	t.Setenv("PERSONAL_FILE", "")
+
+	// Without a projects directory the built-in list is used quietly
+	c, err := loadSiteContent(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(c.projects, LoadProjects()) {
+		t.Error("missing projects directory did not fall back to the built-in list")
+	}
+
+	// and a broken one falls back too, but reports why
+	dir := t.TempDir()
+	projectsDir := filepath.Join(dir, "projects")
+	os.Mkdir(projectsDir, 0755)
+	os.WriteFile(filepath.Join(projectsDir, "a.yaml"), []byte("id: a\n"), 0644)
+	c, err = loadSiteContent(dir)
+	wantErrors(t, err, projectsDir, `a.yaml:1: missing required field "title"`)
+	if c == nil || !reflect.DeepEqual(c.projects, LoadProjects()) {
+		t.Error("broken projects directory did not fall back to the built-in list")
+	}
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// reloadInterval is how often dev mode checks templates and content for changes
+const reloadInterval = 500 * time.Millisecond
+
+// reload re-parses templates and content and swaps them in atomically. If
+// anything fails the previous version keeps serving and the error is shown
+// in the dev overlay until the next successful reload.
+func (s *Server) reload() {
+	content, err := loadSiteContent(s.contentDir)
+	if err != nil {
+		log.Printf("⚠️  Reload failed, keeping previous version: %v", err)
+		s.setReloadError(err)
+		return
+	}
+
+	s.current.Store(content)
+	s.syncResumeTargets()
+	s.setReloadError(nil)
+	log.Printf("🔄 Reloaded templates and content")
+}
+
+func (s *Server) setReloadError(err error) {
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+	s.reloadErr = err
+}
+
+func (s *Server) reloadError() error {
+	s.reloadMu.RLock()
+	defer s.reloadMu.RUnlock()
+	return s.reloadErr
+}
+
+// watchContent polls the template and content directories and reloads when
+// anything in them changes. It is only started in dev mode.
+func (s *Server) watchContent() {
+	paths := []string{"templates", s.contentDir}
+	if personalFile := getEnv("PERSONAL_FILE", ""); personalFile != "" {
+		paths = append(paths, personalFile)
+	}
+
+	last := fingerprint(paths)
+	ticker := time.NewTicker(reloadInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		current := fingerprint(paths)
+		if current == last {
+			continue
+		}
+		last = current
+		s.reload()
+	}
+}
+
+// fingerprint summarizes the name, size and modification time of every file
+// under paths, so any edit, addition or removal changes the result
+func fingerprint(paths []string) [sha256.Size]byte {
+	h := sha256.New()
+	for _, root := range paths {
+		filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				return nil
+			}
+			fmt.Fprintf(h, "%s|%d|%d\n", path, info.Size(), info.ModTime().UnixNano())
+			return nil
+		})
+	}
+
+	var sum [sha256.Size]byte
+	copy(sum[:], h.Sum(nil))
+	return sum
+}
+
+// render executes a page template into a buffer so a failing template never
+// sends a half-written page. In dev mode a pending reload error is shown as
+// an overlay on top of the last good page.
+func (s *Server) render(w http.ResponseWriter, c *siteContent, name string, data interface{}) {
+	s.renderStatus(w, c, http.StatusOK, name, data)
+}
+
+// renderStatus is render with an explicit HTTP status code
+func (s *Server) renderStatus(w http.ResponseWriter, c *siteContent, status int, name string, data interface{}) {
+	var buf bytes.Buffer
+	if err := c.templates.ExecuteTemplate(&buf, name, data); err != nil {
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		log.Printf("Template execution error: %v", err)
+		return
+	}
+
+	page := buf.Bytes()
+	if s.devMode {
+		if err := s.reloadError(); err != nil {
+			page = injectReloadOverlay(page, err)
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	w.Write(page)
+}
+
+var reloadOverlayTemplate = template.Must(template.New("reload-overlay").Parse(`
+<div id="dev-reload-overlay" style="position: fixed; inset: 0; z-index: 99999; background: rgba(0, 0, 0, 0.85); color: #ffffff; font-family: monospace; padding: 48px; overflow: auto;">
+    <div style="max-width: 960px; margin: 0 auto; border: 1px solid #ff0000; background: #111111; padding: 24px;">
+        <div style="background: #ff0000; color: #000000; display: inline-block; padding: 4px 8px; font-weight: bold; font-size: 12px; margin-bottom: 16px;">RELOAD FAILED</div>
+        <p style="color: #cccccc; margin-bottom: 16px;">Showing the last good version. Fix the error below and save to reload.</p>
+        <pre style="white-space: pre-wrap; color: #ff6666;">{{.}}</pre>
+        <button onclick="document.getElementById('dev-reload-overlay').remove()" style="margin-top: 16px; padding: 8px 16px; background: #333333; color: #ffffff; border: 1px solid #666666; cursor: pointer;">DISMISS</button>
+    </div>
+</div>
+`))
+
+// injectReloadOverlay inserts the error overlay before </body>, or appends it
+// when the page has no body tag
+func injectReloadOverlay(page []byte, reloadErr error) []byte {
+	var overlay bytes.Buffer
+	if err := reloadOverlayTemplate.Execute(&overlay, reloadErr.Error()); err != nil {
+		return page
+	}
+
+	idx := bytes.LastIndex(page, []byte("</body>"))
+	if idx == -1 {
+		return append(page, overlay.Bytes()...)
+	}
+
+	out := make([]byte, 0, len(page)+overlay.Len())
+	out = append(out, page[:idx]...)
+	out = append(out, overlay.Bytes()...)
+	return append(out, page[idx:]...)
+}
+
+// isDevMode reports whether DEV_MODE is set to a truthy value
+func isDevMode() bool {
+	switch os.Getenv("DEV_MODE") {
+	case "1", "true", "TRUE", "yes":
+		return true
+	}
+	return false
+}
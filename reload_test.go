@@ -0,0 +1,145 @@
+package main
+
+import (
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFingerprint(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "a.yaml")
+	os.WriteFile(file, []byte("one"), 0644)
+	os.Mkdir(filepath.Join(dir, "sub"), 0755)
+	paths := []string{dir, filepath.Join(dir, "missing")}
+
+	base := fingerprint(paths)
+	if fingerprint(paths) != base {
+		t.Fatal("fingerprint is not stable")
+	}
+
+	mtime := time.Now().Add(-time.Hour)
+	for _, tc := range []struct {
+		name   string
+		change func()
+	}{
+		{"edit", func() { os.WriteFile(file, []byte("two"), 0644); os.Chtimes(file, mtime, mtime) }},
+		{"touch", func() { mtime = mtime.Add(time.Second); os.Chtimes(file, mtime, mtime) }},
+		{"resize", func() { os.WriteFile(file, []byte("three"), 0644); os.Chtimes(file, mtime, mtime) }},
+		{"new file in a subdirectory", func() { os.WriteFile(filepath.Join(dir, "sub", "b.yaml"), nil, 0644) }},
+		{"removal", func() { os.Remove(file) }},
+	} {
+		tc.change()
+		if sum := fingerprint(paths); sum == base {
+			t.Errorf("%s kept the fingerprint", tc.name)
+		} else {
+			base = sum
+		}
+	}
+}
+
+// reloadTestServer runs in a copy of the templates with a one-project
+// content directory, and returns the server and a function that renders
+// its project titles
+func reloadTestServer(t *testing.T) (*Server, func() string) {
+	t.Helper()
+	t.Setenv("PERSONAL_FILE", "")
+	root := t.TempDir()
+	os.MkdirAll(filepath.Join(root, "templates"), 0755)
+	os.MkdirAll(filepath.Join(root, "content", "projects"), 0755)
+	entries, err := os.ReadDir("templates")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		data, err := os.ReadFile(filepath.Join("templates", e.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		os.WriteFile(filepath.Join(root, "templates", e.Name()), data, 0644)
+	}
+	os.WriteFile(filepath.Join(root, "templates", "titles.html"), []byte(`<html><body>{{range .}}{{.Title}};{{end}}</body></html>`), 0644)
+	os.WriteFile(filepath.Join(root, "content", "projects", "a.yaml"), []byte(projectYAML("a", "")), 0644)
+
+	wd, _ := os.Getwd()
+	if err := os.Chdir(root); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+
+	s := &Server{
+		contentDir: "content",
+		devMode:    true,
+		resumes:    NewResumeBuilder(newArtifactCache(filepath.Join(root, "cache")), CompileOptions{}),
+	}
+	c, err := loadSiteContent(s.contentDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.current.Store(c)
+
+	titles := func() string {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		c := s.content()
+		s.render(rec, c, "titles.html", c.projects)
+		return rec.Body.String()
+	}
+	return s, titles
+}
+
+func TestReload(t *testing.T) {
+	s, titles := reloadTestServer(t)
+	if page := titles(); !strings.Contains(page, "Project a;") || strings.Contains(page, "RELOAD FAILED") {
+		t.Fatalf("first page = %s", page)
+	}
+	before := s.content()
+
+	// A broken content file keeps the previous content and shows the overlay
+	project := filepath.Join("content", "projects", "a.yaml")
+	os.WriteFile(project, []byte("id: a\ntitle: Renamed\n"), 0644)
+	s.reload()
+	if s.content() != before {
+		t.Error("broken content replaced the previous version")
+	}
+	if err := s.reloadError(); err == nil || !strings.Contains(err.Error(), `a.yaml:1: missing required field "description"`) {
+		t.Errorf("reload error = %v", err)
+	}
+	if page := titles(); !strings.Contains(page, "Project a;") || !strings.Contains(page, "RELOAD FAILED") || !strings.Contains(page, "missing required field") {
+		t.Errorf("page after a broken reload = %s", page)
+	}
+
+	// A valid change swaps the content in and clears the overlay
+	os.WriteFile(project, []byte(strings.Replace(projectYAML("a", ""), "Project a", "Renamed", 1)), 0644)
+	s.reload()
+	if s.reloadError() != nil {
+		t.Errorf("reload error after a fix = %v", s.reloadError())
+	}
+	if page := titles(); !strings.Contains(page, "Renamed;") || strings.Contains(page, "RELOAD FAILED") {
+		t.Errorf("page after a good reload = %s", page)
+	}
+
+	// A broken template keeps the previous templates and content too
+	good := s.content()
+	os.WriteFile(project, []byte(strings.Replace(projectYAML("a", ""), "Project a", "Unseen", 1)), 0644)
+	os.WriteFile(filepath.Join("templates", "titles.html"), []byte(`{{range .}}`), 0644)
+	s.reload()
+	if s.content() != good {
+		t.Error("broken template replaced the previous version")
+	}
+	if err := s.reloadError(); err == nil || !strings.Contains(err.Error(), "titles.html") {
+		t.Errorf("reload error = %v", err)
+	}
+	if page := titles(); !strings.Contains(page, "Renamed;") || !strings.Contains(page, "RELOAD FAILED") {
+		t.Errorf("page after a broken template = %s", page)
+	}
+
+	// Outside dev mode the overlay is never shown
+	s.devMode = false
+	if page := titles(); strings.Contains(page, "RELOAD FAILED") {
+		t.Errorf("overlay shown outside dev mode: %s", page)
+	}
+}
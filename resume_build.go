@@ -0,0 +1,191 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime/debug"
+	"strings"
+	"sync"
+)
+
+// buildGroup runs at most one build per key at a time. Callers arriving
+// while a build is in flight wait for it and share its result instead of
+// starting another.
+type buildGroup struct {
+	mu    sync.Mutex
+	calls map[string]*buildCall
+}
+
+type buildCall struct {
+	done chan struct{}
+	err  error
+}
+
+// Do runs fn unless a call for key is already running, in which case it
+// waits for that call and returns its error. A panic in fn is returned as
+// an error to the caller and every waiter.
+func (g *buildGroup) Do(key string, fn func() error) error {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*buildCall)
+	}
+	if c, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+		<-c.done
+		return c.err
+	}
+	c := &buildCall{done: make(chan struct{})}
+	g.calls[key] = c
+	g.mu.Unlock()
+
+	defer func() {
+		g.mu.Lock()
+		delete(g.calls, key)
+		g.mu.Unlock()
+		close(c.done)
+	}()
+	c.err = g.call(key, fn)
+	return c.err
+}
+
+// call runs fn, turning a panic into an error
+func (g *buildGroup) call(key string, fn func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("Build %s panicked: %v\n%s", key, r, debug.Stack())
+			err = fmt.Errorf("build panicked: %v", r)
+		}
+	}()
+	return fn()
+}
+
+// ensureResumeHTML returns the HTML resume for the current LaTeX source,
+// building it into the cache with pandoc, then htlatex, then the built-in
+// converter when there is none. Concurrent callers share a single build.
+func (s *Server) ensureResumeHTML(texPath string) (resumeArtifact, error) {
+	// The built-in converter puts the name in the page title, and pandoc
+	// links the stylesheet by URL
+	tool := htmlConverter() + "\x00" + s.content().personal.Name + "\x00" + resumeStylesheetURL
+	key, err := resumeCacheKey(texPath, tool, resumeStylesheetPath)
+	if err != nil {
+		return resumeArtifact{}, err
+	}
+
+	err = s.builds.Do(key, func() error {
+		if _, ok := s.cache.Lookup(key, "resume.html"); ok {
+			return nil
+		}
+		dir, err := s.cache.Dir(key)
+		if err != nil {
+			return err
+		}
+		htmlPath := filepath.Join(dir, "resume.html")
+
+		if err := s.convertWithExternalTool(texPath, htmlPath); err != nil {
+			// If both tools fail, create a simple HTML version from the LaTeX content
+			if err := s.createSimpleHTMLFromLaTeX(texPath, htmlPath); err != nil {
+				return err
+			}
+		}
+		if err := s.cache.Prune(resumeCacheKeep); err != nil {
+			log.Printf("Failed to prune resume cache: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return resumeArtifact{}, err
+	}
+
+	path, ok := s.cache.Lookup(key, "resume.html")
+	if !ok {
+		return resumeArtifact{}, fmt.Errorf("resume HTML missing from cache")
+	}
+	return resumeArtifact{Path: path, Key: key}, nil
+}
+
+// htmlConverter names the external tool ensureResumeHTML will try first
+func htmlConverter() string {
+	for _, tool := range []string{"pandoc", "htlatex"} {
+		if _, err := exec.LookPath(tool); err == nil {
+			return tool
+		}
+	}
+	return "builtin"
+}
+
+// convertWithExternalTool converts the resume with pandoc or htlatex into a
+// temporary directory and renames the result into place
+func (s *Server) convertWithExternalTool(texPath, htmlPath string) error {
+	srcDir, err := filepath.Abs(filepath.Dir(texPath))
+	if err != nil {
+		return err
+	}
+	texName := filepath.Base(texPath)
+
+	outDir, err := os.MkdirTemp(filepath.Dir(htmlPath), ".resume-build-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(outDir)
+
+	outDir, err = filepath.Abs(outDir)
+	if err != nil {
+		return err
+	}
+	builtHTML := filepath.Join(outDir, strings.TrimSuffix(texName, filepath.Ext(texName))+".html")
+
+	// Try to convert LaTeX to HTML using pandoc. The page is served from
+	// /resume, so the stylesheet link must be absolute.
+	cmd := exec.Command("pandoc", texName, "-o", builtHTML, "--mathjax", "--standalone", "--css", resumeStylesheetURL)
+	cmd.Dir = srcDir
+	if err := cmd.Run(); err != nil {
+		// If pandoc fails, try htlatex, directing its output to the build directory
+		cmd = exec.Command("htlatex", texName, "xhtml,2", "charset=utf-8", "-d"+outDir+string(filepath.Separator))
+		cmd.Dir = srcDir
+		if err := cmd.Run(); err != nil {
+			return err
+		}
+	}
+
+	if !fileExists(builtHTML) {
+		return fmt.Errorf("no HTML output produced")
+	}
+	return os.Rename(builtHTML, htmlPath)
+}
+
+// writeFileAtomic writes data to a temporary file next to path and renames
+// it into place, so readers see either the old or the new contents
+func writeFileAtomic(path string, data []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-")
+	if err != nil {
+		return err
+	}
+	tmpPath := f.Name()
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Chmod(tmpPath, 0644); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return nil
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
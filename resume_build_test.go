@@ -0,0 +1,84 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBuildGroupShares(t *testing.T) {
+	var g buildGroup
+	release := make(chan struct{})
+	calls := 0
+	started := make(chan struct{})
+	fail := errors.New("broken")
+
+	go g.Do("k", func() error {
+		calls++
+		close(started)
+		<-release
+		return fail
+	})
+	<-started
+
+	var wg sync.WaitGroup
+	errs := make([]error, 3)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = g.Do("k", func() error { calls++; return nil })
+		}(i)
+	}
+	// give the waiters time to join the running call
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != fail {
+			t.Errorf("waiter %d got %v, want the running call's error", i, err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("fn ran %d times, want 1", calls)
+	}
+}
+
+func TestBuildGroupPanic(t *testing.T) {
+	var g buildGroup
+	release := make(chan struct{})
+	started := make(chan struct{})
+	first := make(chan error)
+	go func() {
+		first <- g.Do("k", func() error {
+			close(started)
+			<-release
+			panic("out of range")
+		})
+	}()
+	<-started
+
+	waiter := make(chan error)
+	go func() { waiter <- g.Do("k", func() error { return nil }) }()
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+
+	for name, ch := range map[string]chan error{"caller": first, "waiter": waiter} {
+		select {
+		case err := <-ch:
+			if err == nil || !strings.Contains(err.Error(), "out of range") {
+				t.Errorf("%s got %v, want the panic as an error", name, err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("%s still waiting after the build panicked", name)
+		}
+	}
+
+	// and the key is free for the next build
+	if err := g.Do("k", func() error { return nil }); err != nil {
+		t.Errorf("next build got %v", err)
+	}
+}
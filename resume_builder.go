@@ -0,0 +1,310 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultResumeTarget is the resume served at /resume/pdf
+	defaultResumeTarget = "default"
+
+	resumeBuildHistory  = 50 // Build records kept in memory
+	resumeCheckInterval = 2 * time.Second
+)
+
+// Build record states
+const (
+	buildRunning   = "running"
+	buildSucceeded = "succeeded"
+	buildFailed    = "failed"
+)
+
+// BuildRecord is one attempt to build a resume PDF
+type BuildRecord struct {
+	ID         int       `json:"id"`
+	Target     string    `json:"target"`
+	Status     string    `json:"status"`
+	Engine     string    `json:"engine,omitempty"`
+	ExitCode   int       `json:"exit_code"`
+	Error      string    `json:"error,omitempty"`
+	CacheKey   string    `json:"cache_key"`
+	StartedAt  time.Time `json:"started_at"`
+	DurationMS int64     `json:"duration_ms"`
+	LogBytes   int       `json:"log_bytes"`
+	LogURL     string    `json:"log_url"`
+
+	log []byte
+}
+
+// resumeTarget is a PDF the builder keeps up to date. prepare returns the
+// path of the LaTeX source, writing it first if it is generated.
+type resumeTarget struct {
+	name    string
+	prepare func() (string, error)
+
+	builtKey string // Cache key of the last attempt, successful or not
+	good     resumeArtifact
+}
+
+// ResumeBuilder compiles resume PDFs in the background. It checks every
+// target at startup, whenever Trigger is called and on a short interval,
+// and builds a target when the cache has no PDF for its current inputs. A
+// failed build leaves the previous PDF in place, so requests always get the
+// last good one.
+type ResumeBuilder struct {
+	mu      sync.Mutex
+	cache   *artifactCache
+	targets map[string]*resumeTarget
+	order   []string // Target names in registration order
+	history []*BuildRecord
+	nextID  int
+	trigger chan struct{}
+	compile CompileOptions
+}
+
+// NewResumeBuilder returns a builder with no targets that stores PDFs in
+// cache and compiles within the given limits
+func NewResumeBuilder(cache *artifactCache, compile CompileOptions) *ResumeBuilder {
+	return &ResumeBuilder{
+		cache:   cache,
+		targets: make(map[string]*resumeTarget),
+		trigger: make(chan struct{}, 1),
+		nextID:  1,
+		compile: compile,
+	}
+}
+
+// AddTarget registers a PDF to keep built from the source returned by
+// prepare. Adding an existing target replaces its source but keeps its last
+// good PDF.
+func (b *ResumeBuilder) AddTarget(name string, prepare func() (string, error)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if t, ok := b.targets[name]; ok {
+		t.prepare = prepare
+		return
+	}
+	b.order = append(b.order, name)
+	b.targets[name] = &resumeTarget{name: name, prepare: prepare}
+}
+
+// RemoveTarget stops building target. Its build history is kept.
+func (b *ResumeBuilder) RemoveTarget(name string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	t, ok := b.targets[name]
+	if !ok {
+		return
+	}
+	b.setGood(t, resumeArtifact{})
+	delete(b.targets, name)
+	for i, n := range b.order {
+		if n == name {
+			b.order = append(b.order[:i:i], b.order[i+1:]...)
+			break
+		}
+	}
+}
+
+// Targets returns the registered target names in registration order
+func (b *ResumeBuilder) Targets() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]string(nil), b.order...)
+}
+
+// Run checks the targets until the process exits
+func (b *ResumeBuilder) Run() {
+	ticker := time.NewTicker(resumeCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		b.checkAll()
+		select {
+		case <-b.trigger:
+		case <-ticker.C:
+		}
+	}
+}
+
+// Trigger asks the builder to check its targets now rather than at the next
+// interval. It never blocks.
+func (b *ResumeBuilder) Trigger() {
+	select {
+	case b.trigger <- struct{}{}:
+	default:
+	}
+}
+
+// LastGood returns the most recent successfully built PDF for target. It
+// reports false until a build succeeds.
+func (b *ResumeBuilder) LastGood(name string) (resumeArtifact, bool) {
+	b.mu.Lock()
+	t, ok := b.targets[name]
+	var good resumeArtifact
+	if ok {
+		good = t.good
+	}
+	b.mu.Unlock()
+
+	if good.Path == "" || !fileExists(good.Path) {
+		return resumeArtifact{}, false
+	}
+	return good, true
+}
+
+// Builds returns the build history, newest first
+func (b *ResumeBuilder) Builds() []BuildRecord {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	records := make([]BuildRecord, 0, len(b.history))
+	for i := len(b.history) - 1; i >= 0; i-- {
+		records = append(records, *b.history[i])
+	}
+	return records
+}
+
+// Build returns a single build record and its captured log
+func (b *ResumeBuilder) Build(id int) (BuildRecord, []byte, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	i := sort.Search(len(b.history), func(i int) bool { return b.history[i].ID >= id })
+	if i == len(b.history) || b.history[i].ID != id {
+		return BuildRecord{}, nil, false
+	}
+	return *b.history[i], b.history[i].log, true
+}
+
+func (b *ResumeBuilder) checkAll() {
+	b.mu.Lock()
+	names := append([]string(nil), b.order...)
+	b.mu.Unlock()
+
+	for _, name := range names {
+		b.check(name)
+	}
+}
+
+// check builds target when its cache key differs from the last attempt and
+// the cache has no PDF for it. Failed sources are not retried until they
+// change.
+func (b *ResumeBuilder) check(name string) {
+	b.mu.Lock()
+	t, ok := b.targets[name]
+	var prepare func() (string, error)
+	if ok {
+		prepare = t.prepare // AddTarget may replace it once the lock is released
+	}
+	b.mu.Unlock()
+	if !ok {
+		return // Removed since checkAll listed it
+	}
+
+	source, err := prepare()
+	if err != nil {
+		log.Printf("Resume %s: failed to prepare source: %v", name, err)
+		return
+	}
+	// Hash and compile a snapshot, since the source may be rewritten at any
+	// time and a PDF must never be stored under another source's key
+	snapshot, err := os.MkdirTemp("", "resume-source-")
+	if err != nil {
+		log.Printf("Resume %s: failed to create snapshot directory: %v", name, err)
+		return
+	}
+	defer os.RemoveAll(snapshot)
+	if err := copySourceTree(source, snapshot); err != nil {
+		log.Printf("Resume %s: failed to snapshot source: %v", name, err)
+		return
+	}
+	texPath := filepath.Join(snapshot, filepath.Base(source))
+	key, err := resumeCacheKey(texPath, preferredLaTeXEngine())
+	if err != nil {
+		log.Printf("Resume %s: %v", name, err)
+		return
+	}
+
+	b.mu.Lock()
+	if key == t.builtKey {
+		b.mu.Unlock()
+		return
+	}
+	t.builtKey = key
+	if path, ok := b.cache.Lookup(key, "resume.pdf"); ok {
+		b.setGood(t, resumeArtifact{Path: path, Key: key})
+		b.mu.Unlock()
+		return
+	}
+	record := b.startRecord(name, key)
+	b.mu.Unlock()
+
+	start := time.Now()
+	dir, err := b.cache.Dir(key)
+	var result compileResult
+	if err == nil {
+		result, err = CompileLaTeX(context.Background(), texPath, filepath.Join(dir, "resume.pdf"), b.compile)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	record.DurationMS = time.Since(start).Milliseconds()
+	record.Engine = result.Engine
+	record.ExitCode = result.ExitCode
+	record.log = result.Log
+	record.LogBytes = len(record.log)
+	if err != nil {
+		record.Status = buildFailed
+		record.Error = err.Error()
+		log.Printf("❌ Resume %s build #%d failed after %dms: %v", name, record.ID, record.DurationMS, err)
+		return
+	}
+	record.Status = buildSucceeded
+	b.setGood(t, resumeArtifact{Path: filepath.Join(dir, "resume.pdf"), Key: key})
+	if err := b.cache.Prune(resumeCacheKeep); err != nil {
+		log.Printf("Failed to prune resume cache: %v", err)
+	}
+	log.Printf("✅ Resume %s build #%d succeeded with %s in %dms", name, record.ID, record.Engine, record.DurationMS)
+}
+
+// setGood makes good the PDF t serves, keeping it pinned in the cache so
+// pruning cannot remove it while it is. b.mu must be held.
+func (b *ResumeBuilder) setGood(t *resumeTarget, good resumeArtifact) {
+	if good.Key != "" {
+		b.cache.Pin(good.Key)
+	}
+	if t.good.Key != "" {
+		b.cache.Unpin(t.good.Key)
+	}
+	t.good = good
+}
+
+// startRecord appends a running record to the history. b.mu must be held.
+func (b *ResumeBuilder) startRecord(target, key string) *BuildRecord {
+	record := &BuildRecord{
+		ID:        b.nextID,
+		Target:    target,
+		Status:    buildRunning,
+		ExitCode:  -1,
+		CacheKey:  key,
+		StartedAt: time.Now(),
+		LogURL:    fmt.Sprintf("/admin/resume/builds/%d/log", b.nextID),
+	}
+	b.nextID++
+
+	b.history = append(b.history, record)
+	if len(b.history) > resumeBuildHistory {
+		b.history = b.history[len(b.history)-resumeBuildHistory:]
+	}
+	return record
+}
@@ -0,0 +1,128 @@
+//go:build unix
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeLaTeX puts a stand-in for latexmk, the first engine CompileLaTeX
+// tries, alone on PATH. It writes a PDF ending with the source unless
+// FAKE_LATEX says to fail, hang, print without end or fill the disk.
+func fakeLaTeX(t *testing.T) {
+	t.Helper()
+	dir := t.TempDir()
+	script := `#!/bin/sh
+PATH=/usr/bin:/bin
+for last; do :; done
+echo "fake latexmk $last"
+case "$FAKE_LATEX" in
+fail)   echo '! Undefined control sequence.'; exit 1 ;;
+hang)   sleep 60 & echo $! > "$FAKE_LATEX_PID"; wait ;;
+chatty) yes 'Overfull \hbox' ;;
+bloat)  head -c 4194304 /dev/zero > bloat.bin; sleep 60 ;;
+*)      { echo '%PDF-1.4'; cat "$last"; } > "${last%.tex}.pdf" ;;
+esac
+`
+	if err := os.WriteFile(filepath.Join(dir, "latexmk"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir)
+	t.Setenv("FAKE_LATEX", "")
+}
+
+func TestResumeBuilder(t *testing.T) {
+	fakeLaTeX(t)
+	dir := t.TempDir()
+	cache := newArtifactCache(filepath.Join(dir, "cache"))
+	b := NewResumeBuilder(cache, CompileOptions{Timeout: 10 * time.Second})
+	tex := filepath.Join(dir, "resume.tex")
+	os.WriteFile(tex, []byte("one"), 0644)
+	b.AddTarget(defaultResumeTarget, func() (string, error) { return tex, nil })
+
+	b.checkAll()
+	good, ok := b.LastGood(defaultResumeTarget)
+	if builds := b.Builds(); !ok || len(builds) != 1 || builds[0].Status != buildSucceeded {
+		t.Fatalf("got %+v and builds %+v, want a good PDF", good, builds)
+	}
+	// built from the source it is keyed by
+	if key, _ := resumeCacheKey(tex, "latexmk"); good.Key != key {
+		t.Errorf("PDF stored under %s, want %s", good.Key, key)
+	}
+	if pdf, _ := os.ReadFile(good.Path); !strings.HasSuffix(string(pdf), "one") {
+		t.Errorf("PDF = %q, want it built from the source", pdf)
+	}
+
+	// A broken source is recorded, and the last good PDF is still served
+	t.Setenv("FAKE_LATEX", "fail")
+	os.WriteFile(tex, []byte("two"), 0644)
+	b.checkAll()
+	if again, ok := b.LastGood(defaultResumeTarget); !ok || again != good {
+		t.Errorf("after a failed build got %+v, want %+v", again, good)
+	}
+	failed := b.Builds()[0]
+	if failed.Status != buildFailed || failed.ExitCode != 1 {
+		t.Errorf("failed build recorded as %+v", failed)
+	}
+	if _, log, _ := b.Build(failed.ID); !strings.Contains(string(log), "Undefined control sequence") {
+		t.Errorf("build log = %q", log)
+	}
+	// and not retried until the source changes
+	b.checkAll()
+	if n := len(b.Builds()); n != 2 {
+		t.Errorf("%d builds after checking an unchanged failed source, want 2", n)
+	}
+
+	// Pruning spares the PDF being served, however old
+	for i := 0; i < resumeCacheKeep+5; i++ {
+		cache.Dir(fmt.Sprintf("%064x", i))
+	}
+	old := time.Now().Add(-time.Hour)
+	os.Chtimes(filepath.Dir(good.Path), old, old)
+	if err := cache.Prune(resumeCacheKeep); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := b.LastGood(defaultResumeTarget); !ok {
+		t.Error("pruning removed the PDF being served")
+	}
+
+	b.RemoveTarget(defaultResumeTarget)
+	cache.Prune(resumeCacheKeep)
+	if fileExists(good.Path) {
+		t.Error("PDF of a removed target kept after pruning")
+	}
+}
+
+func TestConvertWithPandocLinksStylesheet(t *testing.T) {
+	dir := t.TempDir()
+	script := `#!/bin/sh
+PATH=/usr/bin:/bin
+while [ $# -gt 0 ]; do
+	case "$1" in
+	-o)    out=$2; shift ;;
+	--css) css=$2; shift ;;
+	esac
+	shift
+done
+echo "<link rel=\"stylesheet\" href=\"$css\">" > "$out"
+`
+	if err := os.WriteFile(filepath.Join(dir, "pandoc"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir)
+
+	tex := filepath.Join(dir, "resume.tex")
+	out := filepath.Join(dir, "resume.html")
+	os.WriteFile(tex, []byte("one"), 0644)
+	if err := (&Server{}).convertWithExternalTool(tex, out); err != nil {
+		t.Fatal(err)
+	}
+	if html, _ := os.ReadFile(out); !strings.Contains(string(html), `href="/static/assets/resume.css"`) {
+		t.Errorf("HTML = %q, want the stylesheet linked from /static/assets", html)
+	}
+}
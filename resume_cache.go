@@ -0,0 +1,172 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// resumeCacheVersion is mixed into every cache key. Bump it when the
+	// generators change in a way that should invalidate built artifacts.
+	resumeCacheVersion = "1"
+
+	resumeCacheKeep = 20 // Cache entries kept, newest first
+)
+
+// resumeArtifact is a built resume file and the cache key it was built from
+type resumeArtifact struct {
+	Path string
+	Key  string
+}
+
+// ETag returns a strong entity tag for the artifact
+func (a resumeArtifact) ETag() string {
+	return `"` + a.Key + `"`
+}
+
+// artifactCache stores built resume files under the hash of their inputs,
+// one directory per key. Files are written atomically, so a file that exists
+// is complete.
+type artifactCache struct {
+	dir string
+
+	mu     sync.Mutex
+	pinned map[string]int // Keys still being served, and by how many, which Prune leaves alone
+}
+
+func newArtifactCache(dir string) *artifactCache {
+	return &artifactCache{dir: dir, pinned: make(map[string]int)}
+}
+
+// Pin keeps the entry for key from being pruned until it has been unpinned
+// as many times as it was pinned
+func (c *artifactCache) Pin(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pinned[key]++
+}
+
+// Unpin undoes one Pin of key
+func (c *artifactCache) Unpin(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.pinned[key] <= 1 {
+		delete(c.pinned, key)
+		return
+	}
+	c.pinned[key]--
+}
+
+// Lookup returns the path of name under key if it has been built, marking
+// the entry as recently used
+func (c *artifactCache) Lookup(key, name string) (string, bool) {
+	path := filepath.Join(c.dir, key, name)
+	if !fileExists(path) {
+		return "", false
+	}
+	now := time.Now()
+	os.Chtimes(filepath.Join(c.dir, key), now, now)
+	return path, true
+}
+
+// Dir returns the directory for key, creating it if needed
+func (c *artifactCache) Dir(key string) (string, error) {
+	dir := filepath.Join(c.dir, key)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create cache directory: %v", err)
+	}
+	now := time.Now()
+	os.Chtimes(dir, now, now) // Mark as recently used for Prune
+	return dir, nil
+}
+
+// Prune removes all but the keep most recently used entries, and any that
+// are pinned
+func (c *artifactCache) Prune(keep int) error {
+	entries, err := os.ReadDir(c.dir)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	type entry struct {
+		name    string
+		modTime time.Time
+	}
+	var dirs []entry
+	for _, e := range entries {
+		if !e.IsDir() || !cacheKeyPattern.MatchString(e.Name()) || c.pinned[e.Name()] > 0 {
+			continue // Leave anything that is not a prunable cache entry alone
+		}
+		if info, err := e.Info(); err == nil {
+			dirs = append(dirs, entry{e.Name(), info.ModTime()})
+		}
+	}
+	if len(dirs) <= keep {
+		return nil
+	}
+
+	sort.Slice(dirs, func(i, j int) bool { return dirs[i].modTime.After(dirs[j].modTime) })
+	for _, d := range dirs[keep:] {
+		if err := os.RemoveAll(filepath.Join(c.dir, d.name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var cacheKeyPattern = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// resumeCacheKey hashes everything a resume artifact is built from: the
+// LaTeX source, the local files it includes, any extra files such as the
+// stylesheet, and the tool that builds it. The source and its includes are
+// hashed by their names relative to the source directory, so a copy of the
+// tree elsewhere has the same key. Missing extra files are hashed
+// as missing, so creating one, even empty, changes the key.
+func resumeCacheKey(texPath, tool string, extra ...string) (string, error) {
+	deps, err := latexDependencies(texPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read LaTeX source: %v", err)
+	}
+
+	h := sha256.New()
+	fmt.Fprintf(h, "v%s\x00%s\x00", resumeCacheVersion, tool)
+
+	dir := filepath.Dir(texPath)
+	for _, name := range append([]string{filepath.Base(texPath)}, deps...) {
+		if err := hashFile(h, filepath.Join(dir, name), name); err != nil {
+			return "", err
+		}
+	}
+	for _, path := range extra {
+		if err := hashFile(h, path, path); err != nil {
+			return "", err
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// hashFile writes name, and the size and contents of path, to w. A missing
+// file has size -1, so it hashes differently from an empty one.
+func hashFile(w io.Writer, path, name string) error {
+	size := -1
+	data, err := os.ReadFile(path)
+	if err == nil {
+		size = len(data)
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read %s: %v", path, err)
+	}
+	fmt.Fprintf(w, "%s\x00%d\x00", filepath.ToSlash(filepath.Clean(name)), size)
+	w.Write(data)
+	return nil
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestResumeCacheKey(t *testing.T) {
+	dir := t.TempDir()
+	tex := filepath.Join(dir, "resume.tex")
+	section := filepath.Join(dir, "section.tex")
+	css := filepath.Join(dir, "resume.css")
+	os.WriteFile(tex, []byte("\\input{section}\n% \\input{unused}\n"), 0644)
+	os.WriteFile(section, []byte("one"), 0644)
+	os.WriteFile(filepath.Join(dir, "unused.tex"), []byte("one"), 0644)
+
+	key := func() string {
+		t.Helper()
+		k, err := resumeCacheKey(tex, "latexmk", css)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !cacheKeyPattern.MatchString(k) {
+			t.Fatalf("key %q is not a cache key", k)
+		}
+		return k
+	}
+
+	base := key()
+	if key() != base {
+		t.Fatal("key is not stable")
+	}
+
+	// Rewriting a file with the same contents keeps the key
+	os.WriteFile(section, []byte("one"), 0644)
+	if key() != base {
+		t.Error("rewriting an included file unchanged changed the key")
+	}
+	// as does changing a file that is only included in a comment
+	os.WriteFile(filepath.Join(dir, "unused.tex"), []byte("two"), 0644)
+	if key() != base {
+		t.Error("changing a commented-out include changed the key")
+	}
+
+	for _, tc := range []struct {
+		name   string
+		change func()
+	}{
+		{"included file", func() { os.WriteFile(section, []byte("two"), 0644) }},
+		{"missing extra file created", func() { os.WriteFile(css, nil, 0644) }},
+		{"extra file", func() { os.WriteFile(css, []byte("body{}"), 0644) }},
+		{"source", func() { os.WriteFile(tex, []byte("\\input{section}\n"), 0644) }},
+	} {
+		tc.change()
+		if k := key(); k == base {
+			t.Errorf("changing the %s kept the key", tc.name)
+		} else {
+			base = k
+		}
+	}
+
+	// A copy of the source tree elsewhere, as compiled, has the same key
+	copyDir := t.TempDir()
+	if err := copySourceTree(tex, copyDir); err != nil {
+		t.Fatal(err)
+	}
+	if k, _ := resumeCacheKey(filepath.Join(copyDir, "resume.tex"), "latexmk", css); k != base {
+		t.Error("copying the source tree changed the key")
+	}
+
+	if k, _ := resumeCacheKey(tex, "pdflatex", css); k == base {
+		t.Error("changing the tool kept the key")
+	}
+	if _, err := resumeCacheKey(filepath.Join(dir, "missing.tex"), "latexmk"); err == nil {
+		t.Error("missing source hashed")
+	}
+}
+
+func TestServeResumeArtifactETag(t *testing.T) {
+	cache := newArtifactCache(t.TempDir())
+	build := func(key, body string) resumeArtifact {
+		dir, err := cache.Dir(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		path := filepath.Join(dir, "resume.pdf")
+		os.WriteFile(path, []byte(body), 0644)
+		return resumeArtifact{Path: path, Key: key}
+	}
+	serve := func(artifact resumeArtifact, etag string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/resume.pdf", nil)
+		if etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		rec := httptest.NewRecorder()
+		serveResumeArtifact(rec, req, artifact)
+		return rec
+	}
+
+	first := build(fmt.Sprintf("%064x", 1), "first")
+	rec := serve(first, "")
+	etag := rec.Header().Get("ETag")
+	if rec.Code != http.StatusOK || etag != `"`+first.Key+`"` || rec.Body.String() != "first" {
+		t.Fatalf("got %d, ETag %s and %q", rec.Code, etag, rec.Body)
+	}
+	if cc := rec.Header().Get("Cache-Control"); cc != "no-cache" {
+		t.Errorf("Cache-Control = %q", cc)
+	}
+
+	if rec := serve(first, etag); rec.Code != http.StatusNotModified {
+		t.Errorf("unchanged artifact got %d, want 304", rec.Code)
+	}
+
+	// A rebuild from new inputs has a new key, so the old tag no longer matches
+	second := build(fmt.Sprintf("%064x", 2), "second")
+	rec = serve(second, etag)
+	if rec.Code != http.StatusOK || rec.Body.String() != "second" || rec.Header().Get("ETag") == etag {
+		t.Errorf("rebuilt artifact got %d, ETag %s and %q", rec.Code, rec.Header().Get("ETag"), rec.Body)
+	}
+}
+
+func TestArtifactCachePrune(t *testing.T) {
+	cache := newArtifactCache(t.TempDir())
+	var keys []string
+	for i := 0; i < 5; i++ {
+		key := fmt.Sprintf("%064x", i)
+		dir, _ := cache.Dir(key)
+		os.WriteFile(filepath.Join(dir, "resume.pdf"), nil, 0644)
+		old := time.Now().Add(time.Duration(i-10) * time.Minute)
+		os.Chtimes(dir, old, old)
+		keys = append(keys, key)
+	}
+	os.MkdirAll(filepath.Join(cache.dir, "sources"), 0755)
+
+	// Looking an entry up marks it as recently used
+	if _, ok := cache.Lookup(keys[0], "resume.pdf"); !ok {
+		t.Fatal("cached file not found")
+	}
+	cache.Pin(keys[1])
+	if err := cache.Prune(2); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, want := range []bool{true, true, false, false, true} {
+		if _, ok := cache.Lookup(keys[i], "resume.pdf"); ok != want {
+			t.Errorf("entry %d kept = %v, want %v", i, ok, want)
+		}
+	}
+	if !fileExists(filepath.Join(cache.dir, "sources")) {
+		t.Error("pruned a directory that is not a cache entry")
+	}
+}
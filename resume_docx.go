@@ -0,0 +1,46 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/daveonthegit/Personal_Portfolio/docx"
+)
+
+// docxContentType is the media type of Word documents
+const docxContentType = "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+
+// RenderResumeDOCX renders the resume as a Word document laid out like the
+// LaTeX version
+func RenderResumeDOCX(data ResumeData) ([]byte, error) {
+	doc := docx.New()
+	doc.Title = data.Personal.Name + " - Resume"
+	doc.Author = data.Personal.Name
+	layoutResume(doc, data)
+	return doc.Bytes()
+}
+
+// resumeDOCXHandler serves the resume as a Word document
+func (s *Server) resumeDOCXHandler(w http.ResponseWriter, r *http.Request) {
+	data := s.content().resumeData()
+	body, err := RenderResumeDOCX(data)
+	if err != nil {
+		log.Printf("Failed to render resume DOCX: %v", err)
+		http.Error(w, "Failed to generate resume", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", docxContentType)
+	w.Header().Set("Content-Disposition", `attachment; filename="`+resumeFileName(data.Personal.Name, "docx")+`"`)
+	w.Write(body)
+}
+
+// resumeFileName returns a download name such as David_Xiao_Resume.docx
+func resumeFileName(name, ext string) string {
+	base := strings.Join(strings.Fields(name), "_")
+	if base == "" {
+		return "Resume." + ext
+	}
+	return base + "_Resume." + ext
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/daveonthegit/Personal_Portfolio/config"
+)
+
+// JSONResume converts the resume to the jsonresume.org schema, listing the
+// featured projects
+func (d ResumeData) JSONResume() config.JSONResume {
+	info := d.Personal
+	info.Summary = d.Summary
+	r := config.ToJSONResume(info)
+
+	for _, p := range d.Projects {
+		project := config.JSONResumeProject{
+			Name:        p.Title,
+			Description: p.Description,
+			Highlights:  p.Highlights,
+			Keywords:    p.Technologies,
+			URL:         p.LiveURL,
+			Type:        p.Type,
+		}
+		if project.URL == "" {
+			project.URL = p.GitHubURL
+		}
+		if !p.Date.IsZero() {
+			project.StartDate = p.Date.Format("2006-01-02")
+		}
+		r.Projects = append(r.Projects, project)
+	}
+	return r
+}
+
+// resumeJSONHandler serves the resume as a JSON Resume document
+func (s *Server) resumeJSONHandler(w http.ResponseWriter, r *http.Request) {
+	resume := s.content().resumeData().JSONResume()
+
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(resume)
+}
+
+// resumeTextHandler serves the resume as wrapped plain text
+func (s *Server) resumeTextHandler(w http.ResponseWriter, r *http.Request) {
+	serveResumeText(w, r, s.content().resumeData(), "text/plain", RenderResumeText)
+}
+
+// resumeMarkdownHandler serves the resume as Markdown
+func (s *Server) resumeMarkdownHandler(w http.ResponseWriter, r *http.Request) {
+	serveResumeText(w, r, s.content().resumeData(), "text/markdown", RenderResumeMarkdown)
+}
+
+func serveResumeText(w http.ResponseWriter, r *http.Request, data ResumeData, contentType string, render func(ResumeData, int) []byte) {
+	width, err := resumeTextWidth(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType+"; charset=utf-8")
+	w.Write(render(data, width))
+}
+
+// resumeTextWidth returns the wrap width from the width query parameter,
+// falling back to RESUME_TEXT_WIDTH. Zero disables wrapping.
+func resumeTextWidth(r *http.Request) (int, error) {
+	width := getEnvInt("RESUME_TEXT_WIDTH", defaultResumeTextWidth)
+	if value := r.URL.Query().Get("width"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return 0, fmt.Errorf("width must be a number")
+		}
+		width = n
+	}
+	if width != 0 && (width < minResumeTextWidth || width > maxResumeTextWidth) {
+		return 0, fmt.Errorf("width must be between %d and %d, or 0 for no wrapping", minResumeTextWidth, maxResumeTextWidth)
+	}
+	return width, nil
+}
+
+// resumeNegotiated picks the /resume representation from the Accept header.
+// Browsers get the HTML page; clients asking for another format get it
+// without following a link.
+func (s *Server) resumeNegotiated(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Vary", "Accept")
+
+	offers := []string{"text/html", "application/pdf", "text/plain", "text/markdown", "application/json", docxContentType}
+	switch negotiateContentType(r.Header.Get("Accept"), offers) {
+	case "application/pdf":
+		s.serveResumePDF(w, r, defaultResumeTarget, "inline")
+	case "text/plain":
+		s.resumeTextHandler(w, r)
+	case "text/markdown":
+		s.resumeMarkdownHandler(w, r)
+	case "application/json":
+		s.resumeJSONHandler(w, r)
+	case docxContentType:
+		s.resumeDOCXHandler(w, r)
+	case "text/html":
+		s.resumeHandler(w, r)
+	default:
+		http.Error(w, "Available formats: "+strings.Join(offers, ", "), http.StatusNotAcceptable)
+	}
+}
+
+// negotiateContentType returns the offer the Accept header prefers, or an
+// empty string if it accepts none of them. Offers are ranked by quality,
+// then by how specifically they were matched, then by their position in the
+// header, so "application/json, */*" picks JSON. A missing header accepts
+// the first offer.
+func negotiateContentType(accept string, offers []string) string {
+	if strings.TrimSpace(accept) == "" {
+		return offers[0]
+	}
+
+	type mediaRange struct {
+		name string
+		q    float64
+	}
+	var ranges []mediaRange
+	for _, part := range strings.Split(accept, ",") {
+		fields := strings.Split(part, ";")
+		name := strings.ToLower(strings.TrimSpace(fields[0]))
+		if name == "" {
+			continue
+		}
+		q := 1.0
+		for _, param := range fields[1:] {
+			key, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if strings.EqualFold(key, "q") {
+				if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+					q = parsed
+				}
+			}
+		}
+		ranges = append(ranges, mediaRange{name, q})
+	}
+
+	// specificity ranks type/subtype above type/* above */*
+	specificity := func(name string) int {
+		switch {
+		case name == "*/*":
+			return 0
+		case strings.HasSuffix(name, "/*"):
+			return 1
+		}
+		return 2
+	}
+
+	best, bestQ, bestSpec, bestPos := "", 0.0, -1, 0
+	for _, offer := range offers {
+		typ, _, _ := strings.Cut(offer, "/")
+
+		// The most specific range matching the offer decides its quality
+		spec, q, pos := -1, 0.0, 0
+		for i, mr := range ranges {
+			if mr.name != offer && mr.name != typ+"/*" && mr.name != "*/*" {
+				continue
+			}
+			if s := specificity(mr.name); s > spec {
+				spec, q, pos = s, mr.q, i
+			}
+		}
+		if spec < 0 || q <= 0 {
+			continue
+		}
+		if q > bestQ || (q == bestQ && (spec > bestSpec || (spec == bestSpec && pos < bestPos))) {
+			best, bestQ, bestSpec, bestPos = offer, q, spec, pos
+		}
+	}
+	return best
+}
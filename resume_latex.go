@@ -0,0 +1,246 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/daveonthegit/Personal_Portfolio/config"
+)
+
+const (
+	resumeTeXTemplatePath = "templates/resume.tex.tmpl"
+	resumeAssetsDir       = "./static/assets"
+	resumeStylesheetPath  = resumeAssetsDir + "/resume.css"
+	resumeStylesheetURL   = "/static/assets/resume.css"
+)
+
+// ResumeData is the structured input shared by the resume generators
+type ResumeData struct {
+	Personal config.PersonalInfo
+	Summary  string
+	Projects []Project // Featured projects, in resume order
+}
+
+// resumeData assembles the resume from the current content. Projects come
+// from PersonalInfo.ResumeProjects; unknown IDs are skipped.
+func (c *siteContent) resumeData() ResumeData {
+	data := ResumeData{
+		Personal: c.personal,
+		Summary:  c.personal.Summary,
+	}
+	if data.Summary == "" {
+		data.Summary = c.personal.Bio
+	}
+
+	for _, id := range c.personal.ResumeProjects {
+		if idx := ProjectIndex(c.projects, id); idx != -1 {
+			data.Projects = append(data.Projects, c.projects[idx])
+		}
+	}
+
+	return data
+}
+
+// parseResumeTeXTemplate loads the LaTeX resume template. It uses << >>
+// delimiters because {{ }} collides with LaTeX grouping.
+func parseResumeTeXTemplate() (*texttemplate.Template, error) {
+	return texttemplate.New(filepath.Base(resumeTeXTemplatePath)).
+		Delims("<<", ">>").
+		Funcs(resumeTeXFuncs).
+		ParseFiles(resumeTeXTemplatePath)
+}
+
+var resumeTeXFuncs = texttemplate.FuncMap{
+	"esc":             latexEscape,
+	"join":            strings.Join,
+	"contactLinks":    latexContactLinks,
+	"degree":          formatDegree,
+	"experienceDates": formatExperienceDates,
+	"educationDates":  formatEducationDates,
+}
+
+// GenerateResumeLaTeX renders the resume data into LaTeX source
+func GenerateResumeLaTeX(tmpl *texttemplate.Template, data ResumeData) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("failed to render resume LaTeX: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// prepareResumeSource writes the LaTeX source the resume endpoints build
+// from into the cache directory, next to the variants' sources. A
+// hand-written resume.tex in the content directory overrides the generated
+// source.
+func (s *Server) prepareResumeSource() (string, error) {
+	c := s.content()
+	texPath := filepath.Join(s.cache.dir, "sources", "resume.tex")
+	return c.writeResumeSource(texPath, filepath.Join(s.contentDir, "resume.tex"), c.resumeData())
+}
+
+// resumeSource returns the LaTeX for data, or the contents of overridePath
+// if it exists
+func (c *siteContent) resumeSource(overridePath string, data ResumeData) ([]byte, error) {
+	if override, err := os.ReadFile(overridePath); err == nil {
+		return override, nil
+	}
+	if c.resumeTeX == nil {
+		return nil, fmt.Errorf("resume LaTeX template not loaded")
+	}
+	return GenerateResumeLaTeX(c.resumeTeX, data)
+}
+
+// writeResumeSource writes the LaTeX for data to texPath, or copies
+// overridePath there if it exists. The file is only rewritten when its
+// contents change so unchanged sources do not trigger rebuilds.
+func (c *siteContent) writeResumeSource(texPath, overridePath string, data ResumeData) (string, error) {
+	source, err := c.resumeSource(overridePath, data)
+	if err != nil {
+		return "", err
+	}
+
+	if existing, err := os.ReadFile(texPath); err == nil && bytes.Equal(existing, source) {
+		return texPath, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(texPath), 0755); err != nil {
+		return "", fmt.Errorf("failed to create resume source directory: %v", err)
+	}
+	if err := writeFileAtomic(texPath, source); err != nil {
+		return "", fmt.Errorf("failed to write resume LaTeX: %v", err)
+	}
+	return texPath, nil
+}
+
+// printResumeTeX writes the LaTeX source the server builds /resume/pdf
+// from to w, for scripts/build-resume.sh
+func printResumeTeX(w io.Writer, contentDir string) error {
+	c, err := loadSiteContent(contentDir)
+	if c == nil {
+		return err
+	}
+	source, err := c.resumeSource(filepath.Join(contentDir, "resume.tex"), c.resumeData())
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(source)
+	return err
+}
+
+// latexEscape escapes the characters LaTeX treats specially so arbitrary text
+// can be placed in the document body
+func latexEscape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch r {
+		case '\\':
+			b.WriteString(`\textbackslash{}`)
+		case '&', '%', '$', '#', '_', '{', '}':
+			b.WriteRune('\\')
+			b.WriteRune(r)
+		case '~':
+			b.WriteString(`\textasciitilde{}`)
+		case '^':
+			b.WriteString(`\textasciicircum{}`)
+		case '<':
+			b.WriteString(`\textless{}`)
+		case '>':
+			b.WriteString(`\textgreater{}`)
+		case '|':
+			b.WriteString(`\textbar{}`)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// latexEscapeURL escapes a URL for use as the first argument of \href, where
+// only %, # and the backslash itself need protecting
+func latexEscapeURL(url string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "#", `\#`).Replace(url)
+}
+
+// latexContactLinks returns the resume heading entries: phone, then email,
+// LinkedIn, GitHub and website as underlined hyperlinks
+func latexContactLinks(p config.PersonalInfo) []string {
+	var links []string
+	if p.Phone != "" {
+		links = append(links, latexEscape(p.Phone))
+	}
+
+	href := func(url, text string) string {
+		return fmt.Sprintf(`\href{%s}{\underline{%s}}`, latexEscapeURL(url), latexEscape(text))
+	}
+	if p.Email != "" {
+		links = append(links, href("mailto:"+p.Email, p.Email))
+	}
+	for _, url := range []string{p.LinkedIn, p.GitHub, p.Website} {
+		if url != "" {
+			links = append(links, href(url, displayURL(url)))
+		}
+	}
+
+	return links
+}
+
+// displayURL strips the scheme and leading www. from a URL for display
+func displayURL(url string) string {
+	url = strings.TrimPrefix(url, "https://")
+	url = strings.TrimPrefix(url, "http://")
+	url = strings.TrimPrefix(url, "www.")
+	return strings.TrimSuffix(url, "/")
+}
+
+// formatDegree joins degree and field, e.g. "Bachelor of Arts in Computer Science"
+func formatDegree(e config.Education) string {
+	if e.Field == "" {
+		return e.Degree
+	}
+	return e.Degree + " in " + e.Field
+}
+
+// formatExperienceDates formats a position's date range for LaTeX
+func formatExperienceDates(e config.Experience) string {
+	start, end := experienceDateRange(e)
+	return joinNonEmpty(" -- ", start, end)
+}
+
+// formatEducationDates formats an education date range for LaTeX
+func formatEducationDates(e config.Education) string {
+	start, end := educationDateRange(e)
+	return joinNonEmpty(" -- ", start, end)
+}
+
+// experienceDateRange returns the start and end of a position, using
+// "Present" for current positions. An unknown start date is empty.
+func experienceDateRange(e config.Experience) (string, string) {
+	end := "Present"
+	if e.EndDate != nil {
+		end = formatResumeDate(*e.EndDate)
+	}
+	return formatResumeDate(e.StartDate), end
+}
+
+// educationDateRange returns the start and end of an education entry,
+// marking future end dates as expected. Unknown dates are empty.
+func educationDateRange(e config.Education) (string, string) {
+	end := formatResumeDate(e.EndDate)
+	if e.EndDate.After(time.Now()) {
+		end = "Expected " + end
+	}
+	return formatResumeDate(e.StartDate), end
+}
+
+// formatResumeDate formats a resume date as "June 2025", or "" when unknown
+func formatResumeDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format("January 2006")
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"html"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/daveonthegit/Personal_Portfolio/latex"
+)
+
+func TestLatexEscape(t *testing.T) {
+	for _, tc := range []struct {
+		in, want string
+	}{
+		{"R&D", `R\&D`},
+		{"100%", `100\%`},
+		{"$5", `\$5`},
+		{"C#", `C\#`},
+		{"snake_case", `snake\_case`},
+		{"{x}", `\{x\}`},
+		{"~user", `\textasciitilde{}user`},
+		{"x^2", `x\textasciicircum{}2`},
+		{`C:\path`, `C:\textbackslash{}path`},
+		{"a<b>c|d", `a\textless{}b\textgreater{}c\textbar{}d`},
+
+		// A backslash next to a brace stays a backslash and a brace, not an
+		// escaped brace or a group
+		{`\{`, `\textbackslash{}\{`},
+		{`}\`, `\}\textbackslash{}`},
+		{`\\`, `\textbackslash{}\textbackslash{}`},
+		{`\textbf{x}`, `\textbackslash{}textbf\{x\}`},
+
+		{"Zoë – naïve", "Zoë – naïve"},
+		{"", ""},
+	} {
+		if got := latexEscape(tc.in); got != tc.want {
+			t.Errorf("latexEscape(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestLatexEscapeRendersAsWritten(t *testing.T) {
+	for _, s := range []string{
+		`& % $ # _ { } ~ ^ \`,
+		`\{}\\{x}`,
+		`50% off {today} at AT&T_HQ #1 ~ \o/`,
+	} {
+		got := strings.TrimSpace(latex.ToHTML(latexEscape(s)))
+		if want := "<p>" + html.EscapeString(s) + "</p>"; got != want {
+			t.Errorf("%q renders as %q, want %q", s, got, want)
+		}
+	}
+}
+
+func TestLatexEscapeURL(t *testing.T) {
+	for _, tc := range []struct {
+		in, want string
+	}{
+		{"https://example.com/a_b~c", "https://example.com/a_b~c"},
+		{"https://example.com/100%25", `https://example.com/100\%25`},
+		{"https://example.com/page#section", `https://example.com/page\#section`},
+		{"https://example.com/?q=%23tag#top", `https://example.com/?q=\%23tag\#top`},
+		{`https://example.com/a\b`, `https://example.com/a\\b`},
+		{"mailto:ada+cv@example.com", "mailto:ada+cv@example.com"},
+	} {
+		if got := latexEscapeURL(tc.in); got != tc.want {
+			t.Errorf("latexEscapeURL(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestPrintResumeTeX(t *testing.T) {
+	var b strings.Builder
+	if err := printResumeTeX(&b, "content"); err != nil {
+		t.Fatal(err)
+	}
+	c, _ := loadSiteContent("content")
+	want, err := GenerateResumeLaTeX(c.resumeTeX, c.resumeData())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.String() != string(want) {
+		t.Error("printed LaTeX differs from what the server builds")
+	}
+}
+
+// The generated resume keeps every bullet of the handwritten one it replaced
+func TestResumeTeXCoversHandwritten(t *testing.T) {
+	handwritten, err := os.ReadFile("latex/testdata/resume.tex")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b strings.Builder
+	if err := printResumeTeX(&b, "content"); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range strings.Split(string(handwritten), "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, `\resumeItem{`) && !strings.Contains(b.String(), line) {
+			t.Errorf("generated resume is missing %s", line)
+		}
+	}
+}
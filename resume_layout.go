@@ -0,0 +1,96 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/daveonthegit/Personal_Portfolio/richtext"
+)
+
+// layoutResume writes the resume to w laid out like the LaTeX version. The
+// Word and built-in PDF resumes both come from it.
+func layoutResume(w richtext.Writer, data ResumeData) {
+	p := data.Personal
+	w.Paragraph(richtext.StyleTitle, richtext.Text(p.Name))
+	if p.Title != "" {
+		w.Paragraph(richtext.StyleSubtitle, richtext.Text(p.Title))
+	}
+
+	var contacts []richtext.Run
+	addContact := func(run richtext.Run) {
+		if len(contacts) > 0 {
+			contacts = append(contacts, richtext.Text(" | "))
+		}
+		contacts = append(contacts, run)
+	}
+	if p.Phone != "" {
+		addContact(richtext.Text(p.Phone))
+	}
+	if p.Email != "" {
+		addContact(richtext.Link(p.Email, "mailto:"+p.Email))
+	}
+	for _, url := range []string{p.LinkedIn, p.GitHub, p.Website} {
+		if url != "" {
+			addContact(richtext.Link(displayURL(url), url))
+		}
+	}
+	if len(contacts) > 0 {
+		w.Paragraph(richtext.StyleSubtitle, contacts...)
+	}
+
+	if data.Summary != "" {
+		w.Heading(1, richtext.Text("Summary"))
+		w.Paragraph(richtext.StyleNormal, richtext.Text(data.Summary))
+	}
+
+	if len(p.Education) > 0 {
+		w.Heading(1, richtext.Text("Education"))
+		for _, e := range p.Education {
+			start, end := educationDateRange(e)
+			w.Heading(2, richtext.Text(e.Institution+"\t"+e.Location))
+			w.Paragraph(richtext.StyleDetail, richtext.Text(formatDegree(e)+"\t"+joinNonEmpty(" – ", start, end)))
+			if e.GPA != "" {
+				w.Bullet(richtext.Text("GPA: " + e.GPA))
+			}
+		}
+	}
+
+	if len(p.Experience) > 0 {
+		w.Heading(1, richtext.Text("Experience"))
+		for _, e := range p.Experience {
+			start, end := experienceDateRange(e)
+			w.Heading(2, richtext.Text(e.Position+"\t"+joinNonEmpty(" – ", start, end)))
+			w.Paragraph(richtext.StyleDetail, richtext.Text(e.Company+"\t"+e.Location))
+			for _, item := range e.Description {
+				w.Bullet(richtext.Text(item))
+			}
+		}
+	}
+
+	if len(data.Projects) > 0 {
+		w.Heading(1, richtext.Text("Projects"))
+		for _, project := range data.Projects {
+			title := richtext.Bold(project.Title)
+			if url := firstNonEmpty(project.LiveURL, project.GitHubURL); url != "" {
+				title = richtext.Run{Text: project.Title, Bold: true, Link: url}
+			}
+			runs := []richtext.Run{title}
+			if len(project.Technologies) > 0 {
+				runs = append(runs, richtext.Text(" | "), richtext.Italic(strings.Join(project.Technologies, ", ")))
+			}
+			if !project.Date.IsZero() {
+				runs = append(runs, richtext.Text("\t"+project.Date.Format("Jan 2006")))
+			}
+			w.Heading(2, runs...)
+			for _, item := range project.Highlights {
+				w.Bullet(richtext.Text(item))
+			}
+		}
+	}
+
+	if len(p.Skills) > 0 {
+		w.Heading(1, richtext.Text("Skills"))
+		for _, s := range p.Skills {
+			w.Paragraph(richtext.StyleNormal, richtext.Bold(s.Category+": "), richtext.Text(strings.Join(s.Items, ", ")))
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/daveonthegit/Personal_Portfolio/config"
+	"github.com/daveonthegit/Personal_Portfolio/richtext"
+)
+
+func TestLayoutResume(t *testing.T) {
+	data := ResumeData{
+		Personal: config.PersonalInfo{
+			Name:   "Ada Lovelace",
+			Email:  "ada@example.com",
+			Skills: []config.Skill{{Category: "Languages", Items: []string{"Go", "C"}}},
+		},
+		Summary:  "Engineer.",
+		Projects: []Project{{Title: "Engine", GitHubURL: "https://github.com/ada/engine", Highlights: []string{"Computed numbers"}}},
+	}
+
+	var body richtext.Body
+	layoutResume(&body, data)
+	var styles []string
+	for _, p := range body.Paragraphs {
+		styles = append(styles, p.Style)
+	}
+	want := []string{
+		richtext.StyleTitle, richtext.StyleSubtitle,
+		richtext.StyleHeading1, richtext.StyleNormal,
+		richtext.StyleHeading1, richtext.StyleHeading2, richtext.StyleBullet,
+		richtext.StyleHeading1, richtext.StyleNormal,
+	}
+	if !reflect.DeepEqual(styles, want) {
+		t.Errorf("styles = %v, want %v", styles, want)
+	}
+	if title := body.Paragraphs[5].Runs[0]; !title.Bold || title.Link != "https://github.com/ada/engine" {
+		t.Errorf("project title = %+v, want a bold link", title)
+	}
+
+	// Both formats are written from the same layout
+	for name, render := range map[string]func(ResumeData) ([]byte, error){"docx": RenderResumeDOCX, "pdf": RenderResumePDF} {
+		if out, err := render(data); err != nil || len(out) == 0 {
+			t.Errorf("%s: got %d bytes, %v", name, len(out), err)
+		}
+	}
+}
+
+// A variant's title shows under the name in every format
+func TestResumeHeaderTitle(t *testing.T) {
+	c, _ := loadSiteContent("content")
+	v, ok := c.variant("security")
+	if !ok {
+		t.Fatal("no security variant")
+	}
+	data := c.variantResumeData(v)
+	if data.Personal.Title != v.Title || v.Title == "" {
+		t.Fatalf("variant title %q applied as %q", v.Title, data.Personal.Title)
+	}
+
+	var body richtext.Body
+	layoutResume(&body, data)
+	if p := body.Paragraphs[1]; p.Style != richtext.StyleSubtitle || len(p.Runs) != 1 || p.Runs[0].Text != v.Title {
+		t.Errorf("paragraph after the name = %+v, want the title", p)
+	}
+
+	tex, err := GenerateResumeLaTeX(c.resumeTeX, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(tex), `\large `+latexEscape(v.Title)+` \\`) {
+		t.Errorf("LaTeX header does not show the title:\n%s", tex[:min(len(tex), 4000)])
+	}
+	for _, out := range [][]byte{RenderResumeText(data, 0), RenderResumeMarkdown(data, 0)} {
+		if !strings.Contains(string(out), v.Title) {
+			t.Errorf("text resume does not show the title:\n%s", out)
+		}
+	}
+
+	// Without a title the header goes straight to the contacts
+	data.Personal.Title = ""
+	body = richtext.Body{}
+	layoutResume(&body, data)
+	if p := body.Paragraphs[1]; len(p.Runs) < 2 {
+		t.Errorf("paragraph after the name = %+v, want the contacts", p)
+	}
+	tex, _ = GenerateResumeLaTeX(c.resumeTeX, data)
+	if strings.Contains(string(tex), `\large  \\`) || strings.Contains(string(tex), `\large `+latexEscape(v.Title)) {
+		t.Error("LaTeX header has an empty title line")
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/daveonthegit/Personal_Portfolio/pdf"
+)
+
+// RenderResumePDF typesets the resume without LaTeX, laid out like the
+// LaTeX version. It is served when no engine has built the PDF.
+func RenderResumePDF(data ResumeData) ([]byte, error) {
+	doc := pdf.New()
+	doc.Title = data.Personal.Name + " - Resume"
+	doc.Author = data.Personal.Name
+	layoutResume(doc, data)
+	return doc.Bytes()
+}
+
+// serveBuiltinResumePDF typesets and serves the resume of target when no
+// LaTeX build of it is available
+func (s *Server) serveBuiltinResumePDF(w http.ResponseWriter, r *http.Request, target string) {
+	c := s.content()
+	data := c.resumeData()
+	if target != defaultResumeTarget {
+		v, ok := c.variant(target)
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		data = c.variantResumeData(v)
+	}
+
+	body, err := RenderResumePDF(data)
+	if err != nil {
+		log.Printf("Failed to render resume PDF: %v", err)
+		http.Error(w, "Failed to generate resume", http.StatusInternalServerError)
+		return
+	}
+
+	sum := sha256.Sum256(body)
+	w.Header().Set("ETag", `"`+hex.EncodeToString(sum[:])+`"`)
+	w.Header().Set("Cache-Control", "no-cache")
+	http.ServeContent(w, r, "resume.pdf", time.Time{}, bytes.NewReader(body))
+}
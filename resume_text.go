@@ -0,0 +1,319 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/daveonthegit/Personal_Portfolio/config"
+)
+
+const (
+	defaultResumeTextWidth = 80
+	minResumeTextWidth     = 20
+	maxResumeTextWidth     = 200
+)
+
+// RenderResumeText renders the resume as plain text for pasting into
+// applicant tracking systems. Paragraphs and bullets wrap at width
+// characters; a width of 0 disables wrapping.
+func RenderResumeText(data ResumeData, width int) []byte {
+	var b strings.Builder
+	p := data.Personal
+
+	b.WriteString(strings.ToUpper(p.Name) + "\n")
+	for _, line := range []string{p.Title, p.Location} {
+		if line != "" {
+			b.WriteString(line + "\n")
+		}
+	}
+	if contacts := textContacts(p); len(contacts) > 0 {
+		b.WriteString(wrapText(strings.Join(contacts, " | "), width, "", ""))
+	}
+
+	section := func(title string) {
+		b.WriteString("\n" + strings.ToUpper(title) + "\n")
+		b.WriteString(strings.Repeat("-", len(title)) + "\n")
+	}
+	bullets := func(items []string) {
+		for _, item := range items {
+			b.WriteString(wrapText(item, width, "  - ", "    "))
+		}
+	}
+
+	if data.Summary != "" {
+		section("Summary")
+		b.WriteString(wrapText(data.Summary, width, "", ""))
+	}
+
+	if len(p.Education) > 0 {
+		section("Education")
+		for i, e := range p.Education {
+			if i > 0 {
+				b.WriteString("\n")
+			}
+			b.WriteString(wrapText(joinNonEmpty(", ", e.Institution, e.Location), width, "", ""))
+			b.WriteString(wrapText(formatDegree(e), width, "", ""))
+			start, end := educationDateRange(e)
+			if dates := joinNonEmpty(" - ", start, end); dates != "" {
+				b.WriteString(dates + "\n")
+			}
+			if e.GPA != "" {
+				b.WriteString("GPA: " + e.GPA + "\n")
+			}
+		}
+	}
+
+	if len(p.Experience) > 0 {
+		section("Experience")
+		for i, e := range p.Experience {
+			if i > 0 {
+				b.WriteString("\n")
+			}
+			heading := e.Position + ", " + e.Company
+			if e.Location != "" {
+				heading += " (" + e.Location + ")"
+			}
+			b.WriteString(wrapText(heading, width, "", ""))
+			start, end := experienceDateRange(e)
+			b.WriteString(joinNonEmpty(" - ", start, end) + "\n")
+			bullets(e.Description)
+		}
+	}
+
+	if len(data.Projects) > 0 {
+		section("Projects")
+		for i, project := range data.Projects {
+			if i > 0 {
+				b.WriteString("\n")
+			}
+			heading := project.Title
+			if len(project.Technologies) > 0 {
+				heading += " | " + strings.Join(project.Technologies, ", ")
+			}
+			b.WriteString(wrapText(heading, width, "", ""))
+			if !project.Date.IsZero() {
+				b.WriteString(project.Date.Format("January 2006") + "\n")
+			}
+			bullets(project.Highlights)
+		}
+	}
+
+	if len(p.Skills) > 0 {
+		section("Skills")
+		for _, s := range p.Skills {
+			b.WriteString(wrapText(s.Category+": "+strings.Join(s.Items, ", "), width, "", "  "))
+		}
+	}
+
+	return []byte(b.String())
+}
+
+// RenderResumeMarkdown renders the resume as Markdown, wrapping paragraphs
+// and list items at width characters; a width of 0 disables wrapping
+func RenderResumeMarkdown(data ResumeData, width int) []byte {
+	var b strings.Builder
+	p := data.Personal
+
+	b.WriteString("# " + markdownEscape(p.Name) + "\n")
+	if line := joinNonEmpty(" · ", markdownEscape(p.Title), markdownEscape(p.Location)); line != "" {
+		b.WriteString("\n" + wrapText(line, width, "", ""))
+	}
+	if contacts := markdownContacts(p); len(contacts) > 0 {
+		b.WriteString("\n" + wrapText(strings.Join(contacts, " · "), width, "", ""))
+	}
+
+	section := func(title string) {
+		b.WriteString("\n## " + title + "\n\n")
+	}
+	bullets := func(items []string) {
+		if len(items) == 0 {
+			return
+		}
+		b.WriteString("\n")
+		for _, item := range items {
+			b.WriteString(wrapText(markdownEscape(item), width, "- ", "  "))
+		}
+	}
+
+	if data.Summary != "" {
+		section("Summary")
+		b.WriteString(wrapText(markdownEscape(data.Summary), width, "", ""))
+	}
+
+	if len(p.Education) > 0 {
+		section("Education")
+		for i, e := range p.Education {
+			if i > 0 {
+				b.WriteString("\n")
+			}
+			b.WriteString("### " + markdownEscape(joinNonEmpty(" — ", e.Institution, e.Location)) + "\n\n")
+			start, end := educationDateRange(e)
+			// Two trailing spaces make a line break
+			b.WriteString(markdownEscape(formatDegree(e)) + "  \n")
+			if dates := joinNonEmpty(" – ", start, end); dates != "" {
+				b.WriteString("*" + dates + "*\n")
+			}
+			if e.GPA != "" {
+				b.WriteString("\nGPA: " + markdownEscape(e.GPA) + "\n")
+			}
+		}
+	}
+
+	if len(p.Experience) > 0 {
+		section("Experience")
+		for i, e := range p.Experience {
+			if i > 0 {
+				b.WriteString("\n")
+			}
+			b.WriteString("### " + markdownEscape(e.Position) + " — " + markdownEscape(e.Company) + "\n\n")
+			start, end := experienceDateRange(e)
+			b.WriteString("*" + joinNonEmpty(" · ", joinNonEmpty(" – ", start, end), markdownEscape(e.Location)) + "*\n")
+			bullets(e.Description)
+		}
+	}
+
+	if len(data.Projects) > 0 {
+		section("Projects")
+		for i, project := range data.Projects {
+			if i > 0 {
+				b.WriteString("\n")
+			}
+			title := markdownEscape(project.Title)
+			if url := firstNonEmpty(project.LiveURL, project.GitHubURL); url != "" {
+				title = "[" + title + "](" + url + ")"
+			}
+			b.WriteString("### " + title + "\n\n")
+			var meta []string
+			if len(project.Technologies) > 0 {
+				meta = append(meta, markdownEscape(strings.Join(project.Technologies, ", ")))
+			}
+			if !project.Date.IsZero() {
+				meta = append(meta, project.Date.Format("January 2006"))
+			}
+			if len(meta) > 0 {
+				b.WriteString(wrapText("*"+strings.Join(meta, " · ")+"*", width, "", ""))
+			}
+			bullets(project.Highlights)
+		}
+	}
+
+	if len(p.Skills) > 0 {
+		section("Skills")
+		for _, s := range p.Skills {
+			item := "**" + markdownEscape(s.Category) + ":** " + markdownEscape(strings.Join(s.Items, ", "))
+			b.WriteString(wrapText(item, width, "- ", "  "))
+		}
+	}
+
+	return []byte(b.String())
+}
+
+// textContacts lists phone, email and profile links for the plain text header
+func textContacts(p config.PersonalInfo) []string {
+	var contacts []string
+	for _, c := range []string{p.Phone, p.Email} {
+		if c != "" {
+			contacts = append(contacts, c)
+		}
+	}
+	for _, url := range []string{p.LinkedIn, p.GitHub, p.Website} {
+		if url != "" {
+			contacts = append(contacts, displayURL(url))
+		}
+	}
+	return contacts
+}
+
+// markdownContacts lists phone, email and profile links as Markdown links
+func markdownContacts(p config.PersonalInfo) []string {
+	var contacts []string
+	if p.Phone != "" {
+		contacts = append(contacts, markdownEscape(p.Phone))
+	}
+	if p.Email != "" {
+		contacts = append(contacts, fmt.Sprintf("[%s](mailto:%s)", markdownEscape(p.Email), p.Email))
+	}
+	for _, url := range []string{p.LinkedIn, p.GitHub, p.Website} {
+		if url != "" {
+			contacts = append(contacts, fmt.Sprintf("[%s](%s)", markdownEscape(displayURL(url)), url))
+		}
+	}
+	return contacts
+}
+
+// markdownEscape backslash-escapes characters Markdown would treat as
+// markup. Words such as "-" or "1." are escaped too, since wrapping may move
+// them to the start of a line where they would begin a list.
+func markdownEscape(s string) string {
+	words := strings.Split(markdownReplacer.Replace(s), " ")
+	for i, word := range words {
+		if listMarkerPattern.MatchString(word) {
+			last := len(word) - 1
+			if strings.Trim(word, "-+=") == "" {
+				last = 0
+			}
+			words[i] = word[:last] + `\` + word[last:]
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+var listMarkerPattern = regexp.MustCompile(`^(?:[-+=]+|[0-9]+[.)])$`)
+
+var markdownReplacer = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", "*", `\*`, "_", `\_`, "[", `\[`, "]", `\]`,
+	"<", `\<`, ">", `\>`, "#", `\#`,
+)
+
+// wrapText word-wraps s to width characters. The first line starts with
+// prefix and continuation lines with indent. Words longer than the width
+// are kept whole. The result ends with a newline.
+func wrapText(s string, width int, prefix, indent string) string {
+	words := strings.Fields(s)
+	if width <= 0 {
+		return prefix + strings.Join(words, " ") + "\n"
+	}
+
+	var b strings.Builder
+	line := prefix
+	lineLen := utf8.RuneCountInString(prefix)
+	empty := true
+	for _, word := range words {
+		wordLen := utf8.RuneCountInString(word)
+		if !empty && lineLen+1+wordLen > width {
+			b.WriteString(line + "\n")
+			line, lineLen, empty = indent, utf8.RuneCountInString(indent), true
+		}
+		if !empty {
+			line += " "
+			lineLen++
+		}
+		line += word
+		lineLen += wordLen
+		empty = false
+	}
+	b.WriteString(line + "\n")
+	return b.String()
+}
+
+// joinNonEmpty joins the non-empty parts with sep
+func joinNonEmpty(sep string, parts ...string) string {
+	var kept []string
+	for _, part := range parts {
+		if part != "" {
+			kept = append(kept, part)
+		}
+	}
+	return strings.Join(kept, sep)
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
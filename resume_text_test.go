@@ -0,0 +1,130 @@
+package main
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/daveonthegit/Personal_Portfolio/config"
+)
+
+func TestWrapText(t *testing.T) {
+	for _, tc := range []struct {
+		s              string
+		width          int
+		prefix, indent string
+		want           string
+	}{
+		{"one two three", 0, "", "", "one two three\n"},
+		{"  one\ttwo \n three ", 0, "- ", "  ", "- one two three\n"},
+		{"one two three", 7, "", "", "one two\nthree\n"},
+		{"one two three four", 10, "  - ", "    ", "  - one\n    two\n    three\n    four\n"},
+		{"one two three four", 12, "  - ", "    ", "  - one two\n    three\n    four\n"},
+		{"a supercalifragilistic word", 10, "", "", "a\nsupercalifragilistic\nword\n"}, // Long words kept whole
+		{"héllo wörld ünïcode", 11, "", "", "héllo wörld\nünïcode\n"},                  // Width in characters, not bytes
+		{"", 10, "- ", "", "- \n"},
+	} {
+		if got := wrapText(tc.s, tc.width, tc.prefix, tc.indent); got != tc.want {
+			t.Errorf("wrapText(%q, %d) = %q, want %q", tc.s, tc.width, got, tc.want)
+		}
+	}
+}
+
+func TestRenderResumeTextWidth(t *testing.T) {
+	data := ResumeData{
+		Personal: config.PersonalInfo{
+			Name:  "Ada Lovelace",
+			Email: "ada@example.com",
+			Experience: []config.Experience{{
+				StartDate:   time.Date(1842, time.October, 1, 0, 0, 0, 0, time.UTC),
+				Position:    "Analyst",
+				Company:     "Analytical Engine Society",
+				Description: []string{strings.Repeat("Wrote the first published algorithm for a machine. ", 4)},
+			}},
+			Skills: []config.Skill{{Category: "Mathematics", Items: []string{"Bernoulli numbers", "Difference engines", "Notes"}}},
+		},
+		Summary: strings.Repeat("Mathematician and writer. ", 10),
+	}
+
+	// Dates and headings are never wrapped, so they must fit for this check
+	for _, width := range []int{40, defaultResumeTextWidth} {
+		for _, line := range strings.Split(string(RenderResumeText(data, width)), "\n") {
+			if n := utf8.RuneCountInString(line); n > width {
+				t.Errorf("width %d: %d character line %q", width, n, line)
+			}
+		}
+	}
+
+	out := string(RenderResumeText(data, 40))
+	if !strings.Contains(out, "\n  - Wrote the first published algorithm\n    for a machine.") {
+		t.Errorf("bullets not wrapped with a hanging indent:\n%s", out)
+	}
+	if !strings.Contains(out, "Mathematics: Bernoulli numbers,\n  Difference engines, Notes\n") {
+		t.Errorf("skills not wrapped with an indent:\n%s", out)
+	}
+
+	if n := strings.Count(string(RenderResumeText(data, 0)), "\n"); n > 20 {
+		t.Errorf("unwrapped text has %d lines", n)
+	}
+
+	md := string(RenderResumeMarkdown(data, 40))
+	if !strings.Contains(md, "\n- Wrote the first published algorithm\n  for a machine.") {
+		t.Errorf("Markdown list items not wrapped with a hanging indent:\n%s", md)
+	}
+}
+
+func TestResumeTextWidth(t *testing.T) {
+	t.Setenv("RESUME_TEXT_WIDTH", "60")
+	for _, tc := range []struct {
+		query string
+		want  int
+		ok    bool
+	}{
+		{"", 60, true},
+		{"?width=0", 0, true},
+		{"?width=20", 20, true},
+		{"?width=200", 200, true},
+		{"?width=19", 0, false},
+		{"?width=201", 0, false},
+		{"?width=wide", 0, false},
+	} {
+		width, err := resumeTextWidth(httptest.NewRequest("GET", "/resume.txt"+tc.query, nil))
+		if width != tc.want || (err == nil) != tc.ok {
+			t.Errorf("%q: got %d, %v", tc.query, width, err)
+		}
+	}
+}
+
+// Dates left out of an imported JSON Resume are left out of every format
+func TestResumeUnknownDates(t *testing.T) {
+	end := time.Date(2024, time.August, 1, 0, 0, 0, 0, time.UTC)
+	data := ResumeData{Personal: config.PersonalInfo{
+		Name:       "Ada Lovelace",
+		Experience: []config.Experience{{Company: "Engine Society", Position: "Analyst", EndDate: &end}},
+		Education:  []config.Education{{Institution: "Home", Degree: "Tutoring"}},
+	}}
+
+	c, _ := loadSiteContent("content")
+	tex, err := GenerateResumeLaTeX(c.resumeTeX, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	outputs := map[string]string{
+		"text":     string(RenderResumeText(data, 0)),
+		"markdown": string(RenderResumeMarkdown(data, 0)),
+		"latex":    string(tex),
+	}
+	for name, out := range outputs {
+		if strings.Contains(out, "0001") || strings.Contains(out, "– August") || strings.Contains(out, "- August") {
+			t.Errorf("%s shows an unknown date:\n%s", name, out)
+		}
+		if !strings.Contains(out, "August 2024") {
+			t.Errorf("%s is missing the known end date:\n%s", name, out)
+		}
+	}
+	if !strings.Contains(outputs["latex"], "{Engine Society}") || !strings.Contains(outputs["latex"], "{August 2024}") {
+		t.Errorf("LaTeX experience heading:\n%s", tex)
+	}
+}
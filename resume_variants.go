@@ -0,0 +1,115 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"path/filepath"
+
+	"github.com/daveonthegit/Personal_Portfolio/config"
+	"github.com/gorilla/mux"
+)
+
+// resumeVariantsFile lists the tailored resumes, relative to the content directory
+const resumeVariantsFile = "resume-variants.yaml"
+
+// variant returns the named resume variant
+func (c *siteContent) variant(name string) (config.ResumeVariant, bool) {
+	for _, v := range c.variants {
+		if v.Name == name {
+			return v, true
+		}
+	}
+	return config.ResumeVariant{}, false
+}
+
+// variantResumeData assembles the resume tailored by v. Projects are those
+// of the variant's types in type order, falling back to the featured
+// projects when it names no types.
+func (c *siteContent) variantResumeData(v config.ResumeVariant) ResumeData {
+	data := c.resumeData()
+	data.Personal = v.Apply(data.Personal)
+	if v.Summary != "" {
+		data.Summary = v.Summary
+	}
+
+	if len(v.ProjectTypes) > 0 {
+		data.Projects = nil
+		for _, projectType := range v.ProjectTypes {
+			for _, p := range c.projects {
+				if p.Type == projectType {
+					data.Projects = append(data.Projects, p)
+				}
+			}
+		}
+	}
+	if v.MaxProjects > 0 && len(data.Projects) > v.MaxProjects {
+		data.Projects = data.Projects[:v.MaxProjects]
+	}
+
+	return data
+}
+
+// prepareVariantSource writes the LaTeX source of a variant into the cache
+// directory. A hand-written resume-<variant>.tex in the content directory
+// overrides the generated source.
+func (s *Server) prepareVariantSource(name string) (string, error) {
+	c := s.content()
+	v, ok := c.variant(name)
+	if !ok {
+		return "", fmt.Errorf("unknown resume variant %q", name)
+	}
+
+	texPath := filepath.Join(s.cache.dir, "sources", "resume-"+name+".tex")
+	overridePath := filepath.Join(s.contentDir, "resume-"+name+".tex")
+	return c.writeResumeSource(texPath, overridePath, c.variantResumeData(v))
+}
+
+// syncResumeTargets registers a PDF build for every resume variant and drops
+// builds for variants that no longer exist
+func (s *Server) syncResumeTargets() {
+	c := s.content()
+	for _, name := range s.resumes.Targets() {
+		if _, ok := c.variant(name); !ok && name != defaultResumeTarget {
+			s.resumes.RemoveTarget(name)
+		}
+	}
+	for _, v := range c.variants {
+		name := v.Name
+		s.resumes.AddTarget(name, func() (string, error) {
+			return s.prepareVariantSource(name)
+		})
+	}
+	s.resumes.Trigger()
+}
+
+// resumeVariantPDFHandler serves the PDF of a resume variant
+func (s *Server) resumeVariantPDFHandler(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["variant"]
+	if _, ok := s.content().variant(name); !ok {
+		http.NotFound(w, r)
+		return
+	}
+	s.serveResumePDF(w, r, name, "inline")
+}
+
+// resumeVariantHTMLHandler serves the HTML rendering of a resume variant
+func (s *Server) resumeVariantHTMLHandler(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["variant"]
+	if _, ok := s.content().variant(name); !ok {
+		http.NotFound(w, r)
+		return
+	}
+	texPath, err := s.prepareVariantSource(name)
+	s.serveResumeHTML(w, r, texPath, err)
+}
+
+// resumeVariantTextHandler serves a resume variant as plain text
+func (s *Server) resumeVariantTextHandler(w http.ResponseWriter, r *http.Request) {
+	c := s.content()
+	v, ok := c.variant(mux.Vars(r)["variant"])
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	serveResumeText(w, r, c.variantResumeData(v), "text/plain", RenderResumeText)
+}
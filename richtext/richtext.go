@@ -0,0 +1,76 @@
+// Package richtext is the document model shared by the docx and pdf
+// writers: paragraphs in a small set of named styles, made of runs of bold,
+// italic or linked text. A layout written against Writer comes out the same
+// in either format.
+package richtext
+
+// Paragraph styles every writer supports
+const (
+	StyleNormal   = "Normal"
+	StyleTitle    = "Title"
+	StyleSubtitle = "Subtitle"
+	StyleHeading1 = "Heading1" // Section heading
+	StyleHeading2 = "Heading2" // Entry heading with a right aligned tab stop
+	StyleDetail   = "Detail"   // Italic line under an entry heading, same tab stop
+	StyleBullet   = "ListBullet"
+)
+
+// Run is a span of text with uniform formatting. Tabs in Text advance to the
+// right aligned tab stop and newlines become line breaks. A run with a Link
+// is written as a hyperlink.
+type Run struct {
+	Text   string
+	Bold   bool
+	Italic bool
+	Link   string
+}
+
+// Text returns a plain run
+func Text(s string) Run { return Run{Text: s} }
+
+// Bold returns a bold run
+func Bold(s string) Run { return Run{Text: s, Bold: true} }
+
+// Italic returns an italic run
+func Italic(s string) Run { return Run{Text: s, Italic: true} }
+
+// Link returns a hyperlink run
+func Link(s, url string) Run { return Run{Text: s, Link: url} }
+
+// Paragraph is a paragraph of runs in one of the styles
+type Paragraph struct {
+	Style string
+	Runs  []Run
+}
+
+// Writer is a document built paragraph by paragraph
+type Writer interface {
+	Paragraph(style string, runs ...Run)
+	Heading(level int, runs ...Run)
+	Bullet(runs ...Run)
+}
+
+// Body collects paragraphs for a writer to lay out. Documents embed it to
+// implement Writer.
+type Body struct {
+	Paragraphs []Paragraph
+}
+
+// Paragraph appends a paragraph in the given style
+func (b *Body) Paragraph(style string, runs ...Run) {
+	b.Paragraphs = append(b.Paragraphs, Paragraph{Style: style, Runs: runs})
+}
+
+// Heading appends a section heading (level 1) or entry heading (level 2)
+func (b *Body) Heading(level int, runs ...Run) {
+	style := StyleHeading1
+	if level > 1 {
+		style = StyleHeading2
+	}
+	b.Paragraph(style, runs...)
+}
+
+// Bullet appends a bulleted list item
+func (b *Body) Bullet(runs ...Run) {
+	b.Paragraph(StyleBullet, runs...)
+}
@@ -1,12 +1,13 @@
 @echo off
 REM Build script for LaTeX resume on Windows
-REM This script compiles the resume.tex file to PDF
+REM This script generates the resume LaTeX the server builds from and
+REM compiles it to PDF
 
 setlocal enabledelayedexpansion
 
 set "RESUME_DIR=static\assets"
-set "RESUME_TEX=%RESUME_DIR%\resume.tex"
 set "RESUME_PDF=%RESUME_DIR%\resume.pdf"
+set "BUILD_DIR=.cache\resume\script"
 
 REM Check if pdflatex is available
 where pdflatex >nul 2>&1
@@ -18,25 +19,28 @@ if %errorlevel% neq 0 (
 
 echo Building resume from LaTeX...
 
-REM Create output directory if it doesn't exist
+REM Create output directories if they don't exist
 if not exist "%RESUME_DIR%" mkdir "%RESUME_DIR%"
+if not exist "%BUILD_DIR%" mkdir "%BUILD_DIR%"
+if exist "%BUILD_DIR%\resume.pdf" del "%BUILD_DIR%\resume.pdf"
+
+REM Generate the LaTeX with the server's own generator
+go run . resume-tex > "%BUILD_DIR%\resume.tex"
+if %errorlevel% neq 0 (
+    echo ❌ Failed to generate resume LaTeX
+    exit /b 1
+)
 
 REM Compile LaTeX to PDF
-cd "%RESUME_DIR%"
+pushd "%BUILD_DIR%"
 pdflatex -interaction=nonstopmode resume.tex >nul 2>&1
+popd
 
-REM Clean up auxiliary files
-if exist "*.aux" del "*.aux"
-if exist "*.log" del "*.log"
-if exist "*.out" del "*.out"
-if exist "*.fdb_latexmk" del "*.fdb_latexmk"
-if exist "*.fls" del "*.fls"
-if exist "*.synctex.gz" del "*.synctex.gz"
-
-if exist "resume.pdf" (
+if exist "%BUILD_DIR%\resume.pdf" (
+    copy /y "%BUILD_DIR%\resume.pdf" "%RESUME_PDF%" >nul
     echo ✅ Resume successfully built: %RESUME_PDF%
 ) else (
-    echo ❌ Failed to build resume PDF
+    echo ❌ Failed to build resume PDF, see %BUILD_DIR%\resume.log
     exit /b 1
 )
 
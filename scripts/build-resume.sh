@@ -1,13 +1,15 @@
 #!/bin/bash
 
 # Build script for LaTeX resume
-# This script compiles the resume.tex file to PDF and optionally to HTML
+# This script generates the resume LaTeX the server builds from, from
+# content/personal.yaml and the project files (or content/resume.tex if
+# present), and compiles it to PDF and optionally to HTML
 
 set -e
 
 RESUME_DIR="static/assets"
-RESUME_TEX="$RESUME_DIR/resume.tex"
 RESUME_PDF="$RESUME_DIR/resume.pdf"
+BUILD_DIR=".cache/resume/script"
 
 # Check if pdflatex is available
 if ! command -v pdflatex &> /dev/null; then
@@ -20,27 +22,28 @@ fi
 
 echo "Building resume from LaTeX..."
 
-# Create output directory if it doesn't exist
-mkdir -p "$RESUME_DIR"
+# Create output directories if they don't exist
+mkdir -p "$RESUME_DIR" "$BUILD_DIR"
+rm -f "$BUILD_DIR/resume.pdf"
 
-# Compile LaTeX to PDF
-cd "$RESUME_DIR"
-pdflatex -interaction=nonstopmode resume.tex > /dev/null 2>&1
+# Generate the LaTeX with the server's own generator
+go run . resume-tex > "$BUILD_DIR/resume.tex"
 
-# Clean up auxiliary files
-rm -f *.aux *.log *.out *.fdb_latexmk *.fls *.synctex.gz
+# Compile LaTeX to PDF
+(cd "$BUILD_DIR" && pdflatex -interaction=nonstopmode resume.tex > /dev/null 2>&1) || true
 
-if [ -f "resume.pdf" ]; then
+if [ -f "$BUILD_DIR/resume.pdf" ]; then
+    cp "$BUILD_DIR/resume.pdf" "$RESUME_PDF"
     echo "✅ Resume successfully built: $RESUME_PDF"
 else
-    echo "❌ Failed to build resume PDF"
+    echo "❌ Failed to build resume PDF, see $BUILD_DIR/resume.log"
     exit 1
 fi
 
 # Optional: Convert to HTML using pandoc if available
 if command -v pandoc &> /dev/null; then
     echo "Converting to HTML..."
-    pandoc resume.tex -o resume.html --standalone --css=resume.css
+    pandoc "$BUILD_DIR/resume.tex" -o "$RESUME_DIR/resume.html" --standalone --css=/static/assets/resume.css
     echo "✅ HTML version created: $RESUME_DIR/resume.html"
 fi
 
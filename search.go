@@ -0,0 +1,306 @@
+package main
+
+import (
+	"html"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Field weights used when scoring search matches
+const (
+	searchWeightTitle       = 3.0
+	searchWeightTechnology  = 2.0
+	searchWeightDescription = 1.0
+
+	// searchPrefixPenalty scales matches where a query term is only a prefix
+	// of the indexed term
+	searchPrefixPenalty = 0.5
+
+	snippetLength = 180
+)
+
+// SearchIndex is an in-memory inverted index over project titles,
+// descriptions and technologies. It is immutable once built; a new index is
+// built whenever the project set is reloaded.
+type SearchIndex struct {
+	projects []Project
+	postings map[string][]searchPosting
+	terms    []string // Sorted keys of postings, for prefix lookups
+}
+
+type searchPosting struct {
+	doc    int
+	weight float64
+}
+
+// SearchResult is a single ranked match
+type SearchResult struct {
+	ID      string  `json:"id"`
+	Title   string  `json:"title"`
+	URL     string  `json:"url"`
+	Type    string  `json:"type"`
+	Status  string  `json:"status"`
+	Score   float64 `json:"score"`
+	Snippet string  `json:"snippet"` // HTML with matches wrapped in <mark>
+}
+
+// NewSearchIndex indexes the given projects
+func NewSearchIndex(projects []Project) *SearchIndex {
+	idx := &SearchIndex{
+		projects: projects,
+		postings: make(map[string][]searchPosting),
+	}
+
+	for doc, project := range projects {
+		weights := make(map[string]float64)
+		add := func(text string, weight float64) {
+			for _, tok := range tokenize(text) {
+				weights[tok.term] += weight
+			}
+		}
+
+		add(project.Title, searchWeightTitle)
+		add(project.Description, searchWeightDescription)
+		for _, tech := range project.Technologies {
+			add(tech, searchWeightTechnology)
+		}
+
+		for term, weight := range weights {
+			idx.postings[term] = append(idx.postings[term], searchPosting{doc: doc, weight: weight})
+		}
+	}
+
+	for term := range idx.postings {
+		idx.terms = append(idx.terms, term)
+	}
+	sort.Strings(idx.terms)
+
+	return idx
+}
+
+// Search returns up to limit projects matching query, best first. Every
+// query term is matched exactly or as a prefix of an indexed term; projects
+// matching more of the query terms rank higher.
+func (idx *SearchIndex) Search(query string, limit int) []SearchResult {
+	queryTerms := uniqueTerms(tokenize(query))
+	if len(queryTerms) == 0 {
+		return nil
+	}
+
+	scores := make(map[int]float64)
+	matchedTerms := make(map[int]int)
+	docCount := float64(len(idx.projects))
+
+	for _, qt := range queryTerms {
+		matchedDocs := make(map[int]bool)
+		for _, term := range idx.expand(qt) {
+			postings := idx.postings[term]
+			idf := math.Log(1 + docCount/float64(len(postings)))
+			factor := 1.0
+			if term != qt {
+				factor = searchPrefixPenalty
+			}
+			for _, p := range postings {
+				scores[p.doc] += p.weight * idf * factor
+				matchedDocs[p.doc] = true
+			}
+		}
+		for doc := range matchedDocs {
+			matchedTerms[doc]++
+		}
+	}
+
+	results := make([]SearchResult, 0, len(scores))
+	for doc, score := range scores {
+		project := idx.projects[doc]
+		// Coordination factor: favour projects matching every query term
+		score *= float64(matchedTerms[doc]) / float64(len(queryTerms))
+		results = append(results, SearchResult{
+			ID:      project.ID,
+			Title:   project.Title,
+			URL:     "/projects/" + project.ID,
+			Type:    project.Type,
+			Status:  project.Status,
+			Score:   math.Round(score*1000) / 1000,
+			Snippet: highlightSnippet(project.Description, queryTerms),
+		})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].ID < results[j].ID
+	})
+
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
+
+// expand returns the indexed terms equal to or starting with term
+func (idx *SearchIndex) expand(term string) []string {
+	start := sort.SearchStrings(idx.terms, term)
+	var matches []string
+	for i := start; i < len(idx.terms) && strings.HasPrefix(idx.terms[i], term); i++ {
+		matches = append(matches, idx.terms[i])
+	}
+	return matches
+}
+
+// searchToken is a normalized term and its byte span in the source text
+type searchToken struct {
+	term       string
+	start, end int
+}
+
+// tokenize splits text into case-folded, stemmed terms. Letters, digits and
+// the '+' and '#' of names like C++ and C# form words.
+func tokenize(text string) []searchToken {
+	var tokens []searchToken
+	start := -1
+
+	flush := func(end int) {
+		if start == -1 {
+			return
+		}
+		word := strings.ToLower(text[start:end])
+		if term := stem(word); term != "" {
+			tokens = append(tokens, searchToken{term: term, start: start, end: end})
+		}
+		start = -1
+	}
+
+	for i, r := range text {
+		isWord := unicode.IsLetter(r) || unicode.IsDigit(r) || ((r == '+' || r == '#') && start != -1)
+		if isWord {
+			if start == -1 {
+				start = i
+			}
+			continue
+		}
+		flush(i)
+	}
+	flush(len(text))
+
+	return tokens
+}
+
+// stem strips common English suffixes, then a trailing silent e, so "game",
+// "games", "gaming" and "gamed" all index as "gam". Queries go through the
+// same function, so either form finds the other. It is deliberately simple,
+// not a full Porter stemmer.
+func stem(word string) string {
+	n := utf8.RuneCountInString(word)
+	switch {
+	case n > 4 && strings.HasSuffix(word, "ies"):
+		return word[:len(word)-3] + "y"
+	case n > 5 && strings.HasSuffix(word, "ing"):
+		word = word[:len(word)-3]
+	case n > 4 && strings.HasSuffix(word, "ed"):
+		word = word[:len(word)-2]
+	case n > 4 && strings.HasSuffix(word, "ly"):
+		return word[:len(word)-2]
+	case n > 4 && (strings.HasSuffix(word, "sses") || strings.HasSuffix(word, "xes") || strings.HasSuffix(word, "ches") || strings.HasSuffix(word, "shes")):
+		return word[:len(word)-2]
+	case n > 3 && strings.HasSuffix(word, "s") && !strings.HasSuffix(word, "ss") && !strings.HasSuffix(word, "us"):
+		word = word[:len(word)-1]
+	}
+	if utf8.RuneCountInString(word) > 3 && strings.HasSuffix(word, "e") && !strings.HasSuffix(word, "ee") {
+		word = word[:len(word)-1]
+	}
+	return word
+}
+
+func uniqueTerms(tokens []searchToken) []string {
+	seen := make(map[string]bool)
+	var terms []string
+	for _, tok := range tokens {
+		if !seen[tok.term] {
+			seen[tok.term] = true
+			terms = append(terms, tok.term)
+		}
+	}
+	return terms
+}
+
+// highlightSnippet returns an HTML-escaped excerpt of text centred on the
+// first match, with every matching word wrapped in <mark>
+func highlightSnippet(text string, queryTerms []string) string {
+	tokens := tokenize(text)
+
+	var marks []searchToken
+	for _, tok := range tokens {
+		for _, qt := range queryTerms {
+			if strings.HasPrefix(tok.term, qt) {
+				marks = append(marks, tok)
+				break
+			}
+		}
+	}
+
+	// Window of roughly snippetLength bytes starting a little before the first match
+	start, end := 0, len(text)
+	if len(marks) > 0 {
+		start = max(0, marks[0].start-snippetLength/4)
+	}
+	if end-start > snippetLength {
+		end = start + snippetLength
+	}
+	start, end = snapToRunes(text, start, end)
+	start, end = snapToWords(text, start, end)
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("…")
+	}
+	pos := start
+	for _, m := range marks {
+		if m.start < start || m.end > end {
+			continue
+		}
+		b.WriteString(html.EscapeString(text[pos:m.start]))
+		b.WriteString("<mark>")
+		b.WriteString(html.EscapeString(text[m.start:m.end]))
+		b.WriteString("</mark>")
+		pos = m.end
+	}
+	b.WriteString(html.EscapeString(text[pos:end]))
+	if end < len(text) {
+		b.WriteString("…")
+	}
+
+	return b.String()
+}
+
+// snapToWords moves start forward and end backward to the nearest spaces so
+// the snippet does not begin or end mid-word
+func snapToWords(text string, start, end int) (int, int) {
+	if start > 0 {
+		if i := strings.IndexByte(text[start:end], ' '); i != -1 {
+			start += i + 1
+		}
+	}
+	if end < len(text) {
+		if i := strings.LastIndexByte(text[start:end], ' '); i != -1 {
+			end = start + i
+		}
+	}
+	return start, end
+}
+
+// snapToRunes moves start and end back to the nearest rune boundaries, so a
+// window without spaces to snap to still never splits a character
+func snapToRunes(text string, start, end int) (int, int) {
+	for start > 0 && !utf8.RuneStart(text[start]) {
+		start--
+	}
+	for end < len(text) && end > start && !utf8.RuneStart(text[end]) {
+		end--
+	}
+	return start, end
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestStem(t *testing.T) {
+	for _, group := range [][]string{
+		{"game", "games", "gaming", "gamed"},
+		{"code", "codes", "coding", "coded"},
+		{"library", "libraries"},
+		{"process", "processes"},
+		{"tree", "trees"},
+	} {
+		want := stem(group[0])
+		for _, word := range group[1:] {
+			if got := stem(word); got != want {
+				t.Errorf("stem(%q) = %q, stem(%q) = %q; want the same", word, got, group[0], want)
+			}
+		}
+	}
+
+	for _, word := range []string{"go", "css", "status", "use"} {
+		if got := stem(word); got != word {
+			t.Errorf("stem(%q) = %q, want it unchanged", word, got)
+		}
+	}
+}
+
+func TestSearchRanking(t *testing.T) {
+	idx := NewSearchIndex([]Project{
+		{ID: "engine", Title: "Game Engine", Description: "A small renderer.", Technologies: []string{"C++"}},
+		{ID: "bot", Title: "Chat Bot", Description: "Plays games with friends over chat.", Technologies: []string{"Go"}},
+		{ID: "site", Title: "Portfolio", Description: "A personal website.", Technologies: []string{"Go", "HTML"}},
+	})
+
+	ids := func(results []SearchResult) string {
+		var s []string
+		for _, r := range results {
+			s = append(s, r.ID)
+		}
+		return strings.Join(s, ",")
+	}
+
+	for _, tc := range []struct {
+		query, want string
+	}{
+		{"gaming", "engine,bot"}, // Title outweighs description
+		{"game", "engine,bot"},
+		{"GAMES", "engine,bot"},
+		{"go", "bot,site"},      // Equal scores are ordered by ID
+		{"go chat", "bot,site"}, // Matching every term beats matching one
+		{"c++", "engine"},
+		{"port", "site"}, // Prefix
+		{"nothing", ""},
+		{"  ", ""},
+	} {
+		if got := ids(idx.Search(tc.query, 0)); got != tc.want {
+			t.Errorf("Search(%q) = %q, want %q", tc.query, got, tc.want)
+		}
+	}
+
+	if got := idx.Search("go", 1); len(got) != 1 {
+		t.Errorf("limit 1 returned %d results", len(got))
+	}
+
+	exact := idx.Search("portfolio", 0)[0].Score
+	prefix := idx.Search("port", 0)[0].Score
+	if prefix >= exact {
+		t.Errorf("prefix match scored %v, exact %v", prefix, exact)
+	}
+}
+
+func TestHighlightSnippet(t *testing.T) {
+	got := highlightSnippet("Built a <game> engine for gaming & more", []string{stem("game")})
+	want := "Built a &lt;<mark>game</mark>&gt; engine for <mark>gaming</mark> &amp; more"
+	if got != want {
+		t.Errorf("got %q\nwant %q", got, want)
+	}
+
+	// A long match is windowed, with ellipses on the cut sides
+	long := strings.Repeat("filler words ", 30) + "the renderer " + strings.Repeat("more words ", 30)
+	got = highlightSnippet(long, []string{"render"})
+	if !strings.HasPrefix(got, "…") || !strings.HasSuffix(got, "…") || !strings.Contains(got, "<mark>renderer</mark>") {
+		t.Errorf("long snippet = %q", got)
+	}
+	if len(got) > snippetLength+len("……<mark></mark>") {
+		t.Errorf("snippet is %d bytes", len(got))
+	}
+
+	// Text without spaces to snap to is cut between runes
+	for _, text := range []string{
+		strings.Repeat("日本語", 40) + " game " + strings.Repeat("日本語", 40),
+		strings.Repeat("é", 99) + " game " + strings.Repeat("é", 99),
+		strings.Repeat("xé", 60) + " game " + strings.Repeat("éx", 60),
+	} {
+		got := highlightSnippet(text, []string{stem("game")})
+		if !utf8.ValidString(got) {
+			t.Errorf("snippet of %q is not valid UTF-8: %q", text, got)
+		}
+		if !strings.Contains(got, "<mark>game</mark>") {
+			t.Errorf("snippet of %q lost the match: %q", text, got)
+		}
+	}
+}
@@ -1,9 +1,24 @@
-import type { ContactFormData } from '../types';
+import type { ApiResponse, ContactFormData, PowChallenge } from '../types';
+
+// Hashes computed per round while solving a proof-of-work challenge
+const POW_BATCH = 256;
+
+interface PowSolution {
+  challenge: string;
+  solution: string;
+  expiresAt: number;
+}
 
 export class ContactFormHandler {
   private static form: HTMLFormElement | null = null;
   private static submitButton: HTMLButtonElement | null = null;
   private static messageContainer: HTMLDivElement | null = null;
+  // Sent with every attempt at the same message, so a retry after a network
+  // error is not delivered twice
+  private static idempotencyKey: string | null = null;
+  // Proof-of-work solution for the next submission, solved in the background
+  // while the visitor types
+  private static pow: Promise<PowSolution> | null = null;
 
   static init() {
     this.form = document.getElementById('contact-form') as HTMLFormElement;
@@ -12,6 +27,12 @@ export class ContactFormHandler {
 
     if (this.form) {
       this.form.addEventListener('submit', this.handleSubmit.bind(this));
+      // An edited message is a new message
+      this.form.addEventListener('input', (event) => {
+        this.idempotencyKey = null;
+        this.clearFieldError(event.target as HTMLElement);
+      });
+      this.prepareChallenge();
     }
   }
 
@@ -20,12 +41,17 @@ export class ContactFormHandler {
     
     if (!this.form || !this.submitButton || !this.messageContainer) return;
 
+    this.clearFieldErrors();
     const formData = new FormData(this.form);
     const data: ContactFormData = {
       name: formData.get('name') as string,
       email: formData.get('email') as string,
       subject: formData.get('subject') as string,
       message: formData.get('message') as string,
+      website: (formData.get('website') as string) || '',
+      form_token: (formData.get('form_token') as string) || '',
+      challenge: '',
+      solution: '',
     };
 
     // Basic validation
@@ -43,32 +69,139 @@ export class ContactFormHandler {
     this.submitButton.disabled = true;
     this.submitButton.textContent = 'Sending...';
 
+    if (!this.idempotencyKey) {
+      this.idempotencyKey = crypto.randomUUID();
+    }
+
     try {
+      const pow = await this.takeChallenge();
+      data.challenge = pow.challenge;
+      data.solution = pow.solution;
+
       const response = await fetch('/contact', {
         method: 'POST',
         headers: {
           'Content-Type': 'application/json',
+          'Idempotency-Key': this.idempotencyKey,
         },
         body: JSON.stringify(data),
       });
 
-      const result = await response.json();
+      const result: ApiResponse = await response.json();
 
       if (response.ok && result.status === 'success') {
         this.showMessage(result.message, 'success');
         this.form.reset();
+        this.idempotencyKey = null;
       } else {
+        if (result.errors) {
+          this.showFieldErrors(result.errors);
+        }
         this.showMessage(result.message || 'Something went wrong. Please try again.', 'error');
       }
     } catch (error) {
       console.error('Contact form error:', error);
       this.showMessage('Network error. Please check your connection and try again.', 'error');
     } finally {
+      // Each solution is accepted once
+      this.prepareChallenge();
       this.submitButton.disabled = false;
       this.submitButton.textContent = 'TRANSMIT MESSAGE';
     }
   }
 
+  // Marks the inputs the server rejected, with its message under each
+  private static showFieldErrors(errors: Record<string, string>) {
+    if (!this.form) return;
+
+    let first: HTMLElement | null = null;
+    for (const [name, message] of Object.entries(errors)) {
+      const input = this.form.elements.namedItem(name);
+      if (!(input instanceof HTMLInputElement || input instanceof HTMLTextAreaElement) || input.type === 'hidden') {
+        continue;
+      }
+      const error = document.createElement('p');
+      error.id = `${input.id}-error`;
+      error.className = 'form-field-error';
+      error.textContent = message;
+      input.insertAdjacentElement('afterend', error);
+      input.classList.add('form-input-error');
+      input.setAttribute('aria-invalid', 'true');
+      input.setAttribute('aria-describedby', error.id);
+      first = first ?? input;
+    }
+    first?.focus();
+  }
+
+  private static clearFieldError(input: HTMLElement) {
+    if (!input.classList.contains('form-input-error')) return;
+
+    document.getElementById(`${input.id}-error`)?.remove();
+    input.classList.remove('form-input-error');
+    input.removeAttribute('aria-invalid');
+    input.removeAttribute('aria-describedby');
+  }
+
+  private static clearFieldErrors() {
+    this.form?.querySelectorAll<HTMLElement>('.form-input-error').forEach((input) => this.clearFieldError(input));
+  }
+
+  // Starts solving a fresh challenge in the background
+  private static prepareChallenge() {
+    const pow = this.solveChallenge();
+    this.pow = pow;
+    // A failure is retried when the form is submitted
+    pow.catch(() => {
+      if (this.pow === pow) {
+        this.pow = null;
+      }
+    });
+  }
+
+  // Returns a solution that will still be valid when the form arrives
+  private static async takeChallenge(): Promise<PowSolution> {
+    let pow = this.pow ? await this.pow.catch(() => null) : null;
+    if (!pow || pow.expiresAt - Date.now() < 60000) {
+      this.pow = this.solveChallenge();
+      pow = await this.pow;
+    }
+    return pow;
+  }
+
+  // Fetches a challenge and finds a suffix whose SHA-256 hash, appended to
+  // it, starts with the requested number of zero bits
+  private static async solveChallenge(): Promise<PowSolution> {
+    const response = await fetch('/api/contact/challenge', { cache: 'no-store' });
+    if (!response.ok) {
+      throw new Error(`challenge request failed: ${response.status}`);
+    }
+    const { challenge, difficulty, expires_at }: PowChallenge = await response.json();
+
+    const encoder = new TextEncoder();
+    for (let start = 0; ; start += POW_BATCH) {
+      const hashes = await Promise.all(
+        Array.from({ length: POW_BATCH }, (_, i) =>
+          crypto.subtle.digest('SHA-256', encoder.encode(`${challenge}:${(start + i).toString(16)}`)),
+        ),
+      );
+      const found = hashes.findIndex((hash) => this.leadingZeroBits(new Uint8Array(hash)) >= difficulty);
+      if (found !== -1) {
+        return { challenge, solution: (start + found).toString(16), expiresAt: Date.parse(expires_at) };
+      }
+    }
+  }
+
+  private static leadingZeroBits(hash: Uint8Array): number {
+    let bits = 0;
+    for (const byte of hash) {
+      if (byte !== 0) {
+        return bits + Math.clz32(byte) - 24;
+      }
+      bits += 8;
+    }
+    return bits;
+  }
+
   private static showMessage(message: string, type: 'success' | 'error') {
     if (!this.messageContainer) return;
 
@@ -6,22 +6,52 @@ export interface Project {
   description: string;
   image: string;
   technologies: string[];
+  type: string;
   github_url: string;
   live_url: string;
+  demo_type: string;
+  demo_url: string;
+  hosted_path: string;
+  status: string;
   date: string;
 }
 
+// Envelope returned by /api/projects
+export interface ProjectPage {
+  status: 'success';
+  total: number;
+  available: number;
+  count: number;
+  offset: number;
+  limit: number;
+  next_cursor?: string;
+  projects: Project[];
+}
+
 export interface ContactFormData {
   name: string;
   email: string;
   subject: string;
   message: string;
+  website: string; // Honeypot, left empty by people
+  form_token: string; // Signed by the server when the page was rendered
+  challenge: string; // Proof-of-work challenge from /api/contact/challenge
+  solution: string;
+}
+
+// Returned by /api/contact/challenge
+export interface PowChallenge {
+  status: 'success';
+  challenge: string;
+  difficulty: number; // Leading zero bits the solution's hash needs
+  expires_at: string;
 }
 
 export interface ApiResponse<T = any> {
   status: 'success' | 'error';
   message: string;
   data?: T;
+  errors?: Record<string, string>; // Per-field messages for rejected forms
 }
 
 export interface ThemeState {